@@ -5,23 +5,57 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/google/uuid"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/github_client"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/inference_client"
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/ycloud_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/ywiki_client"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/component"
 	dreamwikihttpapi "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/dreamwiki_http_api"
 	dreamwikitaskactionresultstopicreader "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/dreamwiki_task_action_results_topic_reader"
 	dreamwikitaskactionstopicreader "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/dreamwiki_task_actions_topic_reader"
+	llmoperationpoller "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/llm_operation_poller"
+	pagerevisionsreindexer "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/page_revisions_reindexer"
 	staletaskfailer "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/stale_task_failer"
+	vectorindexbuilder "github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/vector_index_builder"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/db"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
 )
 
+func connectToYDB(appConfig *config.Config, log logger.Logger) *ydb.Driver {
+	log.Debug("connecting to ydb")
+	ydbDriver, err := db.ConnectToYDB(appConfig, log)
+	if err != nil {
+		log.Fatalf("failed to connect to ydb: %v", err)
+	}
+	return ydbDriver
+}
+
+func memoryStorageUsers(appConfig *config.Config) []models.User {
+	users := make([]models.User, 0, len(appConfig.MemoryStorageUsers))
+	for login, passwordHash := range appConfig.MemoryStorageUsers {
+		users = append(users, models.User{
+			ID:           uuid.New(),
+			Login:        login,
+			PasswordHash: passwordHash,
+		})
+	}
+	return users
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == migrateCommand {
+		migrateMain(os.Args[2:])
+		return
+	}
+
 	appConfig, err := config.LoadConfig()
 	if err != nil {
 		fmt.Printf("failed to load config: %v\n", err)
@@ -40,14 +74,23 @@ func main() {
 	logger.Info("configuration loaded successfully")
 	config.LogConfig(appConfig, logger)
 
-	logger.Debug("connecting to ydb")
-	ydbDriver, err := db.ConnectToYDB(appConfig, logger)
-	if err != nil {
-		logger.Fatalf("failed to connect to ydb: %v", err)
+	vectorIndex := vectorindex.NewIndex(appConfig.VectorIndexProbes, appConfig.VectorIndexMinParagraphs)
+
+	var storage repository.Storage
+	switch appConfig.StorageBackend {
+	case config.StorageBackendYDB:
+		ydbDriver := connectToYDB(appConfig, logger)
+		defer func() {
+			_ = ydbDriver.Close(context.Background())
+		}()
+
+		dbAdapter := db_adapter.NewDBAdapter(appConfig, logger)
+		defer dbAdapter.Close()
+		storage = repository.NewYDBStorage(dbAdapter, logger, vectorIndex)
+	case config.StorageBackendMemory:
+		logger.Warn("using memory storage, all data will be lost on shutdown")
+		storage = repository.NewMemoryStorage(logger, memoryStorageUsers(appConfig), vectorIndex)
 	}
-	defer func() {
-		_ = ydbDriver.Close(context.Background())
-	}()
 
 	inferenceClient, err := inference_client.NewInferenceClient(appConfig)
 	if err != nil {
@@ -59,38 +102,43 @@ func main() {
 		logger.Fatalf("failed to initialize ywiki client: %v", err)
 	}
 
-	yCloudClient, err := ycloud_client.NewYCloudClient(appConfig)
+	llmClient, err := llm_client.NewLLMClient(appConfig)
 	if err != nil {
-		logger.Fatalf("failed to initialize ycloud client: %v", err)
+		logger.Fatalf("failed to initialize LLM client: %v", err)
 	}
 
 	gitHubClient, err := github_client.NewGitHubClient(appConfig)
 	if err != nil {
 		logger.Fatalf("failed to initialize github client: %v", err)
 	}
-	dbAdapter := db_adapter.NewDBAdapter(appConfig, logger)
-	defer dbAdapter.Close()
 
 	deps := deps.Deps{
-		YDBDriver:       dbAdapter,
+		Storage:         storage,
 		Config:          appConfig,
 		Logger:          logger,
 		InferenceClient: inferenceClient,
 		YWikiClient:     yWikiClient,
 		GitHubClient:    gitHubClient,
-		YCloudClient:    yCloudClient,
+		LLMClient:       llmClient,
+		VectorIndex:     vectorIndex,
 	}
 
 	taskActionsTopicReader := dreamwikitaskactionstopicreader.NewDreamWikiTaskActionsTopicReader(&deps)
 	taskActionResultsTopicReader := dreamwikitaskactionresultstopicreader.NewDreamWikiTaskActionResultsTopicReader(&deps)
 	httpAPI := dreamwikihttpapi.NewDreamWikiHTTPAPI(&deps)
 	staleTaskFailer := staletaskfailer.NewStaleTaskFailer(&deps)
+	pageRevisionsReindexer := pagerevisionsreindexer.NewPageRevisionsReindexer(&deps)
+	vectorIndexBuilder := vectorindexbuilder.NewVectorIndexBuilder(&deps)
+	llmOperationPoller := llmoperationpoller.NewLLMOperationPoller(&deps)
 
 	err = component.RunComponents(
 		taskActionsTopicReader,
 		taskActionResultsTopicReader,
 		httpAPI,
 		staleTaskFailer,
+		pageRevisionsReindexer,
+		vectorIndexBuilder,
+		llmOperationPoller,
 	)
 	if err != nil {
 		logger.Error("one or more components shutted down with error: %v", err)
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/migrations"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+)
+
+const migrateCommand = "migrate"
+
+// migrateMain runs migrate subcommand. It needs YDB settings only, other
+// service configuration is not loaded.
+func migrateMain(args []string) {
+	migrateConfig, err := config.LoadMigrateConfig()
+	if err != nil {
+		fmt.Printf("failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	log, err := logger.New(migrateConfig.LogMode)
+	if err != nil {
+		fmt.Printf("failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		_ = log.Sync()
+	}()
+
+	ydbDriver := connectToYDB(migrateConfig, log)
+	defer func() {
+		_ = ydbDriver.Close(context.Background())
+	}()
+
+	err = runMigrate(context.Background(), ydbDriver, log, args)
+	if err != nil {
+		log.Fatalf("failed to migrate schema: %v", err)
+	}
+}
+
+// runMigrate implements "migrate [-dry-run] [-baseline VERSION]" subcommand,
+// which brings YDB schema to the latest version.
+func runMigrate(ctx context.Context, driver *ydb.Driver, log logger.Logger, args []string) error {
+	flags := flag.NewFlagSet(migrateCommand, flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "list pending migrations without applying them")
+	baseline := flags.Int64("baseline", 0, "record migrations up to this version as applied without executing them")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	allMigrations, err := migrations.LoadMigrations()
+	if err != nil {
+		return err
+	}
+	store := migrations.NewYDBStore(driver)
+
+	if *baseline > 0 {
+		if *dryRun {
+			return fmt.Errorf("-baseline can not be combined with -dry-run")
+		}
+		recorded, err := migrations.Baseline(ctx, store, allMigrations, *baseline)
+		for _, migration := range recorded {
+			log.Info("recorded migration as applied ", formatMigration(migration))
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if *dryRun {
+		pending, err := migrations.Pending(ctx, store, allMigrations)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		for _, migration := range pending {
+			fmt.Println("pending", formatMigration(migration))
+		}
+		return nil
+	}
+
+	applied, err := migrations.Migrate(ctx, store, allMigrations)
+	for _, migration := range applied {
+		log.Info("applied migration ", formatMigration(migration))
+	}
+	if err != nil {
+		return err
+	}
+	if len(applied) == 0 {
+		log.Info("schema is up to date")
+	}
+	return nil
+}
+
+func formatMigration(migration migrations.Migration) string {
+	return fmt.Sprintf("%04d_%s", migration.Version, migration.Description)
+}
@@ -0,0 +1,40 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+func (d *AppDelivery) AskQuestion(ctx context.Context, request api.AskQuestionRequestObject) (api.AskQuestionResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	taskID, err := usecase.AskQuestion(*request.Body)
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.AskQuestion500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.AskQuestion200JSONResponse{TaskId: *taskID}, nil
+}
+
+func (d *AppDelivery) GetAskResult(ctx context.Context, request api.GetAskResultRequestObject) (api.GetAskResultResponseObject, error) {
+	waitSeconds := 0
+	if request.Body.WaitSeconds != nil {
+		waitSeconds = *request.Body.WaitSeconds
+	}
+
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	result, err := usecase.GetAskResult(request.Body.TaskId, waitSeconds)
+	if errors.Is(err, models.ErrConflict) {
+		return api.GetAskResult409JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.GetAskResult500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.GetAskResult200JSONResponse(*result), nil
+}
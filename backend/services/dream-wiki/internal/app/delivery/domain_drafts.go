@@ -2,7 +2,9 @@ package delivery
 
 import (
 	"context"
+	"errors"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 )
@@ -42,13 +44,30 @@ func (d *AppDelivery) GetDraft(ctx context.Context, request api.GetDraftRequestO
 
 func (d *AppDelivery) ApplyDraft(ctx context.Context, request api.ApplyDraftRequestObject) (api.ApplyDraftResponseObject, error) {
 	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
-	err := usecase.ApplyDraft(request.Body.DraftId)
+	taskID, err := usecase.ApplyDraft(request.Body.DraftId)
+	if errors.Is(err, models.ErrConflict) {
+		return api.ApplyDraft409JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.ApplyDraft500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.ApplyDraft200JSONResponse{TaskId: *taskID}, nil
+}
+
+func (d *AppDelivery) RebaseDraft(ctx context.Context, request api.RebaseDraftRequestObject) (api.RebaseDraftResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	result, err := usecase.RebaseDraft(request.Body.DraftId)
+	if errors.Is(err, models.ErrConflict) {
+		return api.RebaseDraft409JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
 	if err != nil {
 		d.log.Error(err.Error())
-		return api.ApplyDraft500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+		return api.RebaseDraft500JSONResponse{Message: internalErrorMessage}, nil
 	}
 
-	return api.ApplyDraft200JSONResponse{}, nil
+	return api.RebaseDraft200JSONResponse(*result), nil
 }
 
 func (d *AppDelivery) ListDrafts(ctx context.Context, request api.ListDraftsRequestObject) (api.ListDraftsResponseObject, error) {
@@ -75,3 +94,28 @@ func (d *AppDelivery) UpdateDraft(ctx context.Context, request api.UpdateDraftRe
 
 	return api.UpdateDraft200JSONResponse{}, nil
 }
+
+func (d *AppDelivery) DiffDraft(ctx context.Context, request api.DiffDraftRequestObject) (api.DiffDraftResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	result, err := usecase.DiffDraft(request.Body.DraftId)
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.DiffDraft500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.DiffDraft200JSONResponse(*result), nil
+}
+
+func (d *AppDelivery) RejectDraftHunks(ctx context.Context, request api.RejectDraftHunksRequestObject) (api.RejectDraftHunksResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	result, err := usecase.RejectDraftHunks(request.Body.DraftId, request.Body.DraftVersion, request.Body.RejectedHunkIndexes)
+	if errors.Is(err, models.ErrConflict) {
+		return api.RejectDraftHunks409JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.RejectDraftHunks500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.RejectDraftHunks200JSONResponse(*result), nil
+}
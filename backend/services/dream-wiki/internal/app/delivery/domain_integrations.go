@@ -42,10 +42,14 @@ func (d *AppDelivery) YwikiAddPage(ctx context.Context, request api.YwikiAddPage
 func (d *AppDelivery) YwikiFetchAll(ctx context.Context, request api.YwikiFetchAllRequestObject) (api.YwikiFetchAllResponseObject, error) {
 	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
 
-	_, err := usecase.YwikiFetchAllAsync()
+	var rootPageURL *string
+	if request.Body != nil {
+		rootPageURL = request.Body.RootPageUrl
+	}
+	taskID, err := usecase.YwikiFetchAllAsync(rootPageURL)
 	if err != nil {
 		d.log.Error(err.Error())
 		return api.YwikiFetchAll500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
 	}
-	return api.YwikiFetchAll200Response{}, nil
+	return api.YwikiFetchAll200JSONResponse{TaskId: *taskID}, nil
 }
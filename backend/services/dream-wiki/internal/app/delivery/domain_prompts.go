@@ -0,0 +1,52 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+func (d *AppDelivery) ListPromptTemplates(ctx context.Context, request api.ListPromptTemplatesRequestObject) (api.ListPromptTemplatesResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	templates, err := usecase.ListPromptTemplates()
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.ListPromptTemplates500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.ListPromptTemplates200JSONResponse{PromptTemplates: templates}, nil
+}
+
+func (d *AppDelivery) ListPromptTemplateVersions(ctx context.Context, request api.ListPromptTemplateVersionsRequestObject) (api.ListPromptTemplateVersionsResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	templates, err := usecase.ListPromptTemplateVersions(request.Body.Name)
+	if errors.Is(err, models.ErrNoPromptTemplate) {
+		return api.ListPromptTemplateVersions404JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.ListPromptTemplateVersions500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.ListPromptTemplateVersions200JSONResponse{PromptTemplates: templates}, nil
+}
+
+func (d *AppDelivery) OverridePromptTemplate(ctx context.Context, request api.OverridePromptTemplateRequestObject) (api.OverridePromptTemplateResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	template, err := usecase.OverridePromptTemplate(*request.Body)
+	if errors.Is(err, models.ErrBadRequest) {
+		return api.OverridePromptTemplate400JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if errors.Is(err, models.ErrNoPromptTemplate) {
+		return api.OverridePromptTemplate404JSONResponse{Message: err.Error()}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.OverridePromptTemplate500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.OverridePromptTemplate200JSONResponse{PromptTemplate: *template}, nil
+}
@@ -0,0 +1,73 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+const defaultDiffContextLines = 3
+
+func (d *AppDelivery) ListPageRevisions(ctx context.Context, request api.ListPageRevisionsRequestObject) (api.ListPageRevisionsResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	revisions, nextInfo, err := usecase.ListPageRevisions(request.Body.PageId, request.Body.Cursor)
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.ListPageRevisions500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.ListPageRevisions200JSONResponse{
+		Revisions: revisions,
+		NextInfo:  *nextInfo,
+	}, nil
+}
+
+func (d *AppDelivery) GetPageRevision(ctx context.Context, request api.GetPageRevisionRequestObject) (api.GetPageRevisionResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	revision, err := usecase.GetRevision(request.Body.RevisionId)
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.GetPageRevision500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.GetPageRevision200JSONResponse{Revision: *revision}, nil
+}
+
+func (d *AppDelivery) DiffPageRevisions(ctx context.Context, request api.DiffPageRevisionsRequestObject) (api.DiffPageRevisionsResponseObject, error) {
+	contextLines := defaultDiffContextLines
+	if request.Body.ContextLines != nil {
+		contextLines = *request.Body.ContextLines
+	}
+
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	result, err := usecase.DiffRevisions(request.Body.OldRevisionId, request.Body.NewRevisionId, request.Body.Mode, contextLines)
+	if errors.Is(err, models.ErrBadRequest) {
+		return api.DiffPageRevisions400JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if errors.Is(err, models.ErrNotFound) {
+		return api.DiffPageRevisions404JSONResponse{Message: err.Error()}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.DiffPageRevisions500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.DiffPageRevisions200JSONResponse(*result), nil
+}
+
+func (d *AppDelivery) RevertPageRevision(ctx context.Context, request api.RevertPageRevisionRequestObject) (api.RevertPageRevisionResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	revision, err := usecase.RevertPageToRevision(request.Body.RevisionId)
+	if errors.Is(err, models.ErrConflict) {
+		return api.RevertPageRevision409JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.RevertPageRevision500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.RevertPageRevision200JSONResponse{Revision: *revision}, nil
+}
@@ -0,0 +1,46 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+func (d *AppDelivery) ListSynonymGroups(ctx context.Context, request api.ListSynonymGroupsRequestObject) (api.ListSynonymGroupsResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	groups, err := usecase.ListSynonymGroups()
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.ListSynonymGroups500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.ListSynonymGroups200JSONResponse{SynonymGroups: groups}, nil
+}
+
+func (d *AppDelivery) UpsertSynonymGroup(ctx context.Context, request api.UpsertSynonymGroupRequestObject) (api.UpsertSynonymGroupResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	synonymGroupID, err := usecase.UpsertSynonymGroup(request.Body.SynonymGroupId, request.Body.Phrases)
+	if errors.Is(err, models.ErrBadRequest) || errors.Is(err, models.ErrNotFound) {
+		return api.UpsertSynonymGroup400JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.UpsertSynonymGroup500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.UpsertSynonymGroup200JSONResponse{SynonymGroupId: *synonymGroupID}, nil
+}
+
+func (d *AppDelivery) DeleteSynonymGroup(ctx context.Context, request api.DeleteSynonymGroupRequestObject) (api.DeleteSynonymGroupResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	err := usecase.DeleteSynonymGroup(request.Body.SynonymGroupId)
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.DeleteSynonymGroup500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+	}
+
+	return api.DeleteSynonymGroup200JSONResponse{}, nil
+}
@@ -2,7 +2,9 @@ package delivery
 
 import (
 	"context"
+	"errors"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 )
@@ -10,14 +12,37 @@ import (
 func (d *AppDelivery) CancelTask(ctx context.Context, request api.CancelTaskRequestObject) (api.CancelTaskResponseObject, error) {
 	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
 	err := usecase.CancelTask(request.Body.TaskId)
+	if errors.Is(err, models.ErrNotFound) {
+		return api.CancelTask404JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if errors.Is(err, models.ErrConflict) {
+		return api.CancelTask409JSONResponse{Message: err.Error()}, nil
+	}
 	if err != nil {
 		d.log.Error(err.Error())
-		return api.CancelTask500JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: internalErrorMessage}}, nil
+		return api.CancelTask500JSONResponse{Message: internalErrorMessage}, nil
 	}
 
 	return api.CancelTask200JSONResponse{}, nil
 }
 
+func (d *AppDelivery) RetryTask(ctx context.Context, request api.RetryTaskRequestObject) (api.RetryTaskResponseObject, error) {
+	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
+	err := usecase.RetryTask(request.Body.TaskId)
+	if errors.Is(err, models.ErrNotFound) {
+		return api.RetryTask404JSONResponse{ErrorResponseJSONResponse: api.ErrorResponseJSONResponse{Message: err.Error()}}, nil
+	}
+	if errors.Is(err, models.ErrConflict) {
+		return api.RetryTask409JSONResponse{Message: err.Error()}, nil
+	}
+	if err != nil {
+		d.log.Error(err.Error())
+		return api.RetryTask500JSONResponse{Message: internalErrorMessage}, nil
+	}
+
+	return api.RetryTask200JSONResponse{}, nil
+}
+
 func (d *AppDelivery) GetTaskDetails(ctx context.Context, request api.GetTaskDetailsRequestObject) (api.GetTaskDetailsResponseObject, error) {
 	usecase := usecase.NewAppUsecaseImpl(ctx, d.deps)
 	result, err := usecase.GetTaskDetails(request.Body.TaskId)
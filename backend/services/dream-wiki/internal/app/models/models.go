@@ -15,8 +15,25 @@ type (
 )
 
 var (
-	ErrWrongCredentials error = fmt.Errorf("wrong credentials")
-	ErrNoAccess         error = fmt.Errorf("no access")
-	ErrNotFound         error = fmt.Errorf("not found")
-	ErrNoRows           error = fmt.Errorf("%w: no rows", ErrNotFound)
+	ErrWrongCredentials   error = fmt.Errorf("wrong credentials")
+	ErrNoAccess           error = fmt.Errorf("no access")
+	ErrNotFound           error = fmt.Errorf("not found")
+	ErrNoRows             error = fmt.Errorf("%w: no rows", ErrNotFound)
+	ErrConflict           error = fmt.Errorf("conflict")
+	ErrBadRequest         error = fmt.Errorf("bad request")
+	ErrTaskNotCancellable error = fmt.Errorf("%w: task is already finished", ErrConflict)
+	ErrTaskNotRetryable   error = fmt.Errorf("%w: task is not failed", ErrConflict)
+	ErrNothingToRetry     error = fmt.Errorf("%w: task has no failed actions", ErrConflict)
+	ErrDraftNeedsRebase   error = fmt.Errorf("%w: draft is based on outdated page revision", ErrConflict)
+	ErrDraftHasConflicts  error = fmt.Errorf("%w: draft has unresolved conflicts", ErrConflict)
+	ErrDraftMerged        error = fmt.Errorf("%w: draft is already merged", ErrConflict)
+	ErrDraftPublishing    error = fmt.Errorf("%w: draft is being published", ErrConflict)
+	ErrRevisionIsCurrent  error = fmt.Errorf("%w: revision is already current", ErrConflict)
+	ErrDraftChanged       error = fmt.Errorf("%w: draft was changed", ErrConflict)
+	ErrDraftHunkNotFound  error = fmt.Errorf("%w: no such draft hunk", ErrConflict)
+	ErrTaskNotAskQuestion error = fmt.Errorf("%w: task is not a question", ErrConflict)
+	ErrBadSynonymGroup    error = fmt.Errorf("%w: synonym group needs at least two different phrases", ErrBadRequest)
+	ErrRevisionsMismatch  error = fmt.Errorf("%w: revisions belong to different pages", ErrBadRequest)
+	ErrBadPromptTemplate  error = fmt.Errorf("%w: bad prompt template", ErrBadRequest)
+	ErrNoPromptTemplate   error = fmt.Errorf("%w: no such prompt template", ErrNotFound)
 )
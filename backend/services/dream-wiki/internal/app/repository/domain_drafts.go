@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"strings"
 	"time"
 
@@ -47,7 +48,7 @@ func encodeDraftsNextInfo(timeFrom time.Time, idFrom api.DraftID, numRows int) *
 	}
 }
 
-func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, error) {
+func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, *internals.DraftAdditionalInfo, error) {
 	yql := `
 	SELECT
 		d.draft_id,
@@ -56,8 +57,10 @@ func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, error
 		d.status,
 		d.created_at,
 		d.updated_at,
+		d.source_task_id,
+		d.source_paragraph_indexes,
 		d.page_revision_id,
-		r.revision_id,
+		p.current_revision_id,
 		r.content,
 		p.page_id,
 		p.title
@@ -69,7 +72,7 @@ func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, error
 
 	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$draftID", types.UuidValue(draftID)))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer result.Close()
 
@@ -80,8 +83,10 @@ func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, error
 	var status string
 	var createdAt time.Time
 	var updatedAt time.Time
-	var pageRevisionID int64
+	var sourceTaskID *int64
+	var sourceParagraphIndexesBytes *[]byte
 	var baseRevisionID int64
+	var currentRevisionID int64
 	var pageID api.PageID
 	var pageTitle string
 
@@ -92,21 +97,30 @@ func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, error
 		&status,
 		&createdAt,
 		&updatedAt,
-		&pageRevisionID,
+		&sourceTaskID,
+		&sourceParagraphIndexesBytes,
 		&baseRevisionID,
+		&currentRevisionID,
 		&originalContent,
 		&pageID,
 		&pageTitle,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if status == string(api.Active) && pageRevisionID != baseRevisionID {
+	if status == string(api.Active) && baseRevisionID != currentRevisionID {
 		status = string(api.NeedsRebase)
 	}
 
-	return &api.Draft{
+	var sourceParagraphIndexes *[]int
+	if sourceParagraphIndexesBytes != nil {
+		if err = json.Unmarshal(*sourceParagraphIndexesBytes, &sourceParagraphIndexes); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	draft := &api.Draft{
 		Content:   content,
 		CreatedAt: createdAt,
 		DraftDigest: api.DraftDigest{
@@ -118,9 +132,18 @@ func (r *appRepositoryImpl) GetDraftByID(draftID api.DraftID) (*api.Draft, error
 			},
 			Status: api.DraftStatus(status),
 		},
-		OriginalPageContent: &originalContent,
-		UpdatedAt:           updatedAt,
-	}, nil
+		OriginalPageContent:    &originalContent,
+		SourceParagraphIndexes: sourceParagraphIndexes,
+		UpdatedAt:              updatedAt,
+	}
+
+	draftAdditionalInfo := &internals.DraftAdditionalInfo{
+		BaseRevisionId:        baseRevisionID,
+		PageCurrentRevisionId: currentRevisionID,
+		SourceTaskId:          sourceTaskID,
+	}
+
+	return draft, draftAdditionalInfo, nil
 }
 
 func (r *appRepositoryImpl) ListDrafts(cursor *api.Cursor, limit int64) ([]api.DraftDigest, *api.NextInfo, error) {
@@ -133,7 +156,8 @@ func (r *appRepositoryImpl) ListDrafts(cursor *api.Cursor, limit int64) ([]api.D
 		p.page_id,
 		p.title
 	FROM Draft d
-	JOIN Page p ON d.page_revision_id = p.current_revision_id
+	JOIN PageRevision r ON r.revision_id = d.page_revision_id
+	JOIN Page p ON r.page_id = p.page_id
 	-- WHERE (d.created_at, d.draft_id) < ($timeFrom, $idFrom)
 	ORDER BY d.created_at DESC, d.draft_id DESC
 	LIMIT $limit;
@@ -190,6 +214,30 @@ func (r *appRepositoryImpl) ListDrafts(cursor *api.Cursor, limit int64) ([]api.D
 	return drafts, encodeDraftsNextInfo(newTimeFrom, newIDFrom, result.RowCount()), nil
 }
 
+// MarkPageDraftsNeedRebase marks active drafts of the page which are based on
+// outdated revision.
+func (r *appRepositoryImpl) MarkPageDraftsNeedRebase(pageID api.PageID) error {
+	yql := `
+	UPDATE Draft ON
+	SELECT
+		d.draft_id AS draft_id,
+		'needs_rebase' AS status,
+		CurrentUtcTimestamp() AS updated_at
+	FROM Draft d
+	JOIN PageRevision r ON r.revision_id = d.page_revision_id
+	JOIN Page p ON r.page_id = p.page_id
+	WHERE p.page_id = $pageID AND d.status = 'active' AND d.page_revision_id != p.current_revision_id;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$pageID", types.UuidValue(pageID)))
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	return nil
+}
+
 func (r *appRepositoryImpl) RemoveDraft(draftID api.DraftID) error {
 	yql := `
 	DELETE FROM Draft WHERE draft_id = $draftID;
@@ -288,7 +336,9 @@ func (r *appRepositoryImpl) SetDraftTitle(draftID api.DraftID, newTitle string)
 	return nil
 }
 
-func (r *appRepositoryImpl) CreateDraft(pageID api.PageID, draftTitle string, draftContent string) (*api.DraftID, error) {
+// CreateDraft creates draft based on the current page revision. sourceTaskID is set
+// for drafts proposed by tasks, sourceParagraphIndexes may be set for them too.
+func (r *appRepositoryImpl) CreateDraft(pageID api.PageID, draftTitle string, draftContent string, sourceTaskID *api.TaskID, sourceParagraphIndexes []int) (*api.DraftID, error) {
 	pageYql := `
 	SELECT current_revision_id
 	FROM Page
@@ -313,6 +363,8 @@ func (r *appRepositoryImpl) CreateDraft(pageID api.PageID, draftTitle string, dr
 		status,
 		draft_title,
 		content,
+		source_task_id,
+		source_paragraph_indexes,
 		created_at,
 		updated_at
 	)
@@ -322,16 +374,36 @@ func (r *appRepositoryImpl) CreateDraft(pageID api.PageID, draftTitle string, dr
 		'active',
 		$draftTitle,
 		$content,
+		$sourceTaskID,
+		$sourceParagraphIndexes,
 		CurrentUtcDatetime(),
 		CurrentUtcDatetime()
 	)
 	RETURNING draft_id;
 	`
 
+	var ydbSourceTaskID types.Value
+	if sourceTaskID != nil {
+		ydbSourceTaskID = types.OptionalValue(types.Int64Value(*sourceTaskID))
+	} else {
+		ydbSourceTaskID = types.NullValue(types.TypeInt64)
+	}
+
+	ydbSourceParagraphIndexes := types.NullValue(types.TypeJSON)
+	if sourceParagraphIndexes != nil {
+		indexesBytes, err := json.Marshal(sourceParagraphIndexes)
+		if err != nil {
+			return nil, err
+		}
+		ydbSourceParagraphIndexes = types.OptionalValue(types.JSONValueFromBytes(indexesBytes))
+	}
+
 	parameters := []table.ParameterOption{
 		table.ValueParam("$pageRevisionID", types.Int64Value(currentRevisionID)),
 		table.ValueParam("$draftTitle", types.TextValue(draftTitle)),
 		table.ValueParam("$content", types.TextValue(draftContent)),
+		table.ValueParam("$sourceTaskID", ydbSourceTaskID),
+		table.ValueParam("$sourceParagraphIndexes", ydbSourceParagraphIndexes),
 	}
 
 	result, err := r.tx.InTX().Execute(yql, parameters...)
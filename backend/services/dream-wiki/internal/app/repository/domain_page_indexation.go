@@ -3,6 +3,7 @@ package repository
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
@@ -14,6 +15,8 @@ func (r *appRepositoryImpl) RemovePageIndexation(pageID api.PageID) error {
 	yql := `
 		DELETE FROM Paragraph WHERE page_id=$pageID;
 		DELETE FROM Term WHERE page_id=$pageID;
+		DELETE FROM PageTermStats WHERE page_id=$pageID;
+		DELETE FROM TermPageStats WHERE page_id=$pageID;
 	`
 
 	result, err := r.tx.InTX().Execute(yql,
@@ -24,21 +27,128 @@ func (r *appRepositoryImpl) RemovePageIndexation(pageID api.PageID) error {
 	}
 	defer result.Close()
 
+	r.embeddingIndexUpdate.removePage(pageID)
 	return nil
 }
 
+func (r *appRepositoryImpl) GetPageIndexedParagraphs(pageID api.PageID) ([]internals.IndexedParagraphDigest, error) {
+	yql := `
+		SELECT paragraph_index, content_hash, embedding
+		FROM Paragraph
+		WHERE page_id = $pageID
+		ORDER BY paragraph_index;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$pageID", types.UuidValue(pageID)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	paragraphs := make([]internals.IndexedParagraphDigest, 0)
+	for result.NextRow() {
+		var paragraphIndex int64
+		var contentHash string
+		var binaryEmbedding []byte
+		err = result.FetchRow(&paragraphIndex, &contentHash, &binaryEmbedding)
+		if err != nil {
+			return nil, err
+		}
+
+		embedding, err := embeddingFromYDBBinary(binaryEmbedding)
+		if err != nil {
+			return nil, fmt.Errorf("paragraph %d of page %s: %w", paragraphIndex, pageID, err)
+		}
+
+		paragraphs = append(paragraphs, internals.IndexedParagraphDigest{
+			ParagraphIndex: int(paragraphIndex),
+			ContentHash:    contentHash,
+			Embedding:      embedding,
+		})
+	}
+
+	return paragraphs, nil
+}
+
+func (r *appRepositoryImpl) GetParagraphEmbeddings() ([]internals.ParagraphEmbedding, error) {
+	yql := `
+		SELECT page_id, paragraph_index, embedding
+		FROM Paragraph;
+	`
+
+	result, err := r.tx.InTX().Execute(yql)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	embeddings := make([]internals.ParagraphEmbedding, 0)
+	for result.NextRow() {
+		var pageID api.PageID
+		var paragraphIndex int64
+		var binaryEmbedding []byte
+		err = result.FetchRow(&pageID, &paragraphIndex, &binaryEmbedding)
+		if err != nil {
+			return nil, err
+		}
+
+		embedding, err := embeddingFromYDBBinary(binaryEmbedding)
+		if err != nil {
+			return nil, fmt.Errorf("paragraph %d of page %s: %w", paragraphIndex, pageID, err)
+		}
+
+		embeddings = append(embeddings, internals.ParagraphEmbedding{
+			PageId:         pageID,
+			ParagraphIndex: int(paragraphIndex),
+			Embedding:      embedding,
+		})
+	}
+
+	return embeddings, nil
+}
+
+func (r *appRepositoryImpl) GetPageTerms(pageID api.PageID) ([]internals.Term, error) {
+	yql := `
+		SELECT term, paragraph_index, times_in
+		FROM Term
+		WHERE page_id = $pageID;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$pageID", types.UuidValue(pageID)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	terms := make([]internals.Term, 0)
+	for result.NextRow() {
+		term := internals.Term{PageId: pageID}
+		err = result.FetchRow(&term.Term, &term.ParagraphIndex, &term.TimesIn)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
 func (r *appRepositoryImpl) AddIndexedParagraph(paragraph internals.ParagraphWithEmbedding) error {
 	yql := `
-		INSERT INTO Paragraph (page_id, line_number, content, embedding, anchor_link_slug, paragraph_index, headers, is_header)
+		INSERT INTO Paragraph (page_id, line_number, end_line_number, content, embedding, anchor_link_slug, paragraph_index, headers, is_header, block_type, content_hash, terms_count)
 		VALUES (
 			$pageID,
 			$lineNumber,
+			$endLineNumber,
 			$content,
 			Untag(Knn::ToBinaryStringFloat($embedding), "FloatVector"),
 			$anchorLinkSlug,
 			$paragraphIndex,
 			$headers,
-			$isHeader
+			$isHeader,
+			$blockType,
+			$contentHash,
+			$termsCount
 		);
 	`
 
@@ -54,18 +164,23 @@ func (r *appRepositoryImpl) AddIndexedParagraph(paragraph internals.ParagraphWit
 	result, err := r.tx.InTX().Execute(yql,
 		table.ValueParam("$pageID", types.UuidValue(paragraph.PageId)),
 		table.ValueParam("$lineNumber", types.Int64Value(int64(paragraph.LineNumber))),
+		table.ValueParam("$endLineNumber", types.Int64Value(int64(paragraph.EndLineNumber))),
 		table.ValueParam("$content", types.TextValue(paragraph.Content)),
 		table.ValueParam("$embedding", embeddingToYDBList(paragraph.Embedding)),
 		table.ValueParam("$anchorLinkSlug", anchorLinkSlug),
 		table.ValueParam("$paragraphIndex", types.Int64Value(int64(paragraph.ParagraphIndex))),
 		table.ValueParam("$headers", types.UTF8Value(strings.Join(paragraph.Headers, "\n"))),
 		table.ValueParam("$isHeader", types.BoolValue(paragraph.IsHeader)),
+		table.ValueParam("$blockType", types.TextValue(string(paragraph.BlockType))),
+		table.ValueParam("$contentHash", types.TextValue(paragraph.ContentHash)),
+		table.ValueParam("$termsCount", types.Int64Value(paragraph.TermsCount)),
 	)
 	if err != nil {
 		return err
 	}
 	defer result.Close()
 
+	r.embeddingIndexUpdate.addParagraph(paragraph)
 	return nil
 }
 
@@ -115,3 +230,122 @@ func (r *appRepositoryImpl) AddTerms(terms []internals.Term) error {
 
 	return nil
 }
+
+// SetPageTermStats writes statistics of the page indexation written after
+// RemovePageIndexation, which removes previous ones.
+func (r *appRepositoryImpl) SetPageTermStats(stats internals.PageTermStats) error {
+	yql := `
+		UPSERT INTO PageTermStats (page_id, paragraphs_count, terms_count)
+		VALUES ($pageID, $paragraphsCount, $termsCount);
+	`
+	params := []table.ParameterOption{
+		table.ValueParam("$pageID", types.UuidValue(stats.PageId)),
+		table.ValueParam("$paragraphsCount", types.Int64Value(stats.ParagraphsCount)),
+		table.ValueParam("$termsCount", types.Int64Value(stats.TermsCount)),
+	}
+
+	if len(stats.TermParagraphsCounts) > 0 {
+		yql += `
+		UPSERT INTO TermPageStats (term, page_id, paragraphs_count)
+		SELECT term, $pageID AS page_id, paragraphs_count FROM AS_TABLE($termStats);
+		`
+		termStats := make([]types.Value, 0, len(stats.TermParagraphsCounts))
+		for term, paragraphsCount := range stats.TermParagraphsCounts {
+			termStats = append(termStats, types.StructValue(
+				types.StructFieldValue("term", types.TextValue(term)),
+				types.StructFieldValue("paragraphs_count", types.Int64Value(paragraphsCount)),
+			))
+		}
+		params = append(params, table.ValueParam("$termStats", types.ListValue(termStats...)))
+	}
+
+	result, err := r.tx.InTX().Execute(yql, params...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	return nil
+}
+
+// AddPendingReindexationPage remembers write of the page until it is
+// reindexated. Time of the first pending write is kept.
+func (r *appRepositoryImpl) AddPendingReindexationPage(pageID api.PageID, writtenAt time.Time) error {
+	yql := `
+		$firstWrittenAt = (SELECT first_written_at FROM PendingPageReindexation WHERE page_id = $pageID);
+
+		UPSERT INTO PendingPageReindexation (page_id, first_written_at, last_written_at)
+		VALUES ($pageID, COALESCE($firstWrittenAt, $writtenAt), $writtenAt);
+	`
+
+	result, err := r.tx.InTX().Execute(yql,
+		table.ValueParam("$pageID", types.UuidValue(pageID)),
+		table.ValueParam("$writtenAt", types.TimestampValueFromTime(writtenAt)),
+	)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	r.log.Debug("Added pending reindexation of page ", pageID)
+
+	return nil
+}
+
+// GetDuePendingReindexationPages returns pending pages not written since
+// lastWrittenBefore or pending since firstWrittenBefore, in order of their
+// first pending write.
+func (r *appRepositoryImpl) GetDuePendingReindexationPages(lastWrittenBefore time.Time, firstWrittenBefore time.Time) ([]api.PageID, error) {
+	yql := `
+		SELECT page_id
+		FROM PendingPageReindexation
+		WHERE last_written_at <= $lastWrittenBefore OR first_written_at <= $firstWrittenBefore
+		ORDER BY first_written_at, page_id;
+	`
+
+	result, err := r.tx.InTX().Execute(yql,
+		table.ValueParam("$lastWrittenBefore", types.TimestampValueFromTime(lastWrittenBefore)),
+		table.ValueParam("$firstWrittenBefore", types.TimestampValueFromTime(firstWrittenBefore)),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	pageIDs := make([]api.PageID, 0, result.RowCount())
+	for result.NextRow() {
+		var pageID api.PageID
+		if err := result.FetchRow(&pageID); err != nil {
+			return nil, err
+		}
+		pageIDs = append(pageIDs, pageID)
+	}
+
+	return pageIDs, nil
+}
+
+func (r *appRepositoryImpl) RemovePendingReindexationPages(pageIDs []api.PageID) error {
+	if len(pageIDs) == 0 {
+		return nil
+	}
+
+	yql := `
+		DELETE FROM PendingPageReindexation
+		WHERE page_id IN $pageIDs;
+	`
+
+	pageIDValues := make([]types.Value, 0, len(pageIDs))
+	for _, pageID := range pageIDs {
+		pageIDValues = append(pageIDValues, types.UuidValue(pageID))
+	}
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$pageIDs", types.ListValue(pageIDValues...)))
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	r.log.Debug("Removed pending reindexation of ", len(pageIDs), " pages")
+
+	return nil
+}
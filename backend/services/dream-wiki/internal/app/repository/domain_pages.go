@@ -1,10 +1,17 @@
 package repository
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/ywiki_slug"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+	"github.com/ydb-platform/ydb-go-sdk/v3/topic/topicoptions"
+	"github.com/ydb-platform/ydb-go-sdk/v3/topic/topicwriter"
 )
 
 func (r *appRepositoryImpl) DeleteAllPages() error {
@@ -19,6 +26,7 @@ func (r *appRepositoryImpl) DeleteAllPages() error {
 	}
 	defer result.Close()
 
+	r.embeddingIndexUpdate.removeAllPages()
 	r.log.Info("All pages and paragraphs deleted successfully")
 	return nil
 }
@@ -88,7 +96,7 @@ func (r *appRepositoryImpl) CreatePage(yWikiSlug string, title string, content s
 
 	r.log.Debug("Inserted page with id ", pageID)
 
-	_, err = r.AppendPageRevision(pageID, content)
+	_, err = r.AppendPageRevision(pageID, content, api.YwikiSync, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -130,7 +138,44 @@ func (r *appRepositoryImpl) GetAllPageDigests() ([]api.PageDigest, error) {
 	return pages, nil
 }
 
-func (r *appRepositoryImpl) AppendPageRevision(pageID api.PageID, newContent string) (*internals.RevisionID, error) {
+func (r *appRepositoryImpl) GetPageTreeNodes() ([]internals.PageTreeNode, error) {
+	yql := `SELECT page_id, title, ywiki_slug, parent_page_id FROM Page`
+
+	result, err := r.tx.InTX().Execute(yql)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	nodes := make([]internals.PageTreeNode, 0, result.RowCount())
+	for result.NextRow() {
+		var node internals.PageTreeNode
+		err := result.FetchRow(&node.PageId, &node.Title, &node.YwikiSlug, &node.ParentPageId)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// enqueuePageRevisionAppended notifies PageRevisionsReindexer that the page has
+// to be reindexated. Message is written only if the transaction is committed.
+func (r *appRepositoryImpl) enqueuePageRevisionAppended(pageID api.PageID) error {
+	topicClient := r.tx.TopicClient()
+	writer, err := topicClient.StartTransactionalWriter(r.tx.GetTX(), pageRevisionAppendedTopicName, topicoptions.WithWriterWaitServerAck(true))
+	if err != nil {
+		return err
+	}
+	err = writer.Write(r.ctx, topicwriter.Message{Data: strings.NewReader(pageID.String())})
+	if err != nil {
+		return err
+	}
+	r.log.Info("Enqueued revision appended message for page ", pageID)
+	return nil
+}
+
+func (r *appRepositoryImpl) AppendPageRevision(pageID api.PageID, newContent string, source api.RevisionSource, author *string) (*internals.RevisionID, error) {
 	yql1 := `
 	SELECT current_revision_id
 	FROM Page
@@ -156,18 +201,30 @@ func (r *appRepositoryImpl) AppendPageRevision(pageID api.PageID, newContent str
 	}
 
 	yql2 := `
-	INSERT INTO PageRevision(page_id, previous_revision_id, content)
+	INSERT INTO PageRevision(page_id, previous_revision_id, content, created_at, source, author)
 	VALUES (
 		$pageID,
 		$previousRevisionID,
-		$content
+		$content,
+		CurrentUtcDatetime(),
+		$source,
+		$author
 	)
 	RETURNING revision_id;`
 
+	var ydbAuthor types.Value
+	if author != nil {
+		ydbAuthor = types.OptionalValue(types.TextValue(*author))
+	} else {
+		ydbAuthor = types.NullValue(types.TypeText)
+	}
+
 	parameters := []table.ParameterOption{
 		table.ValueParam("$pageID", types.UuidValue(pageID)),
 		table.ValueParam("$previousRevisionID", ydbRevisionID),
 		table.ValueParam("$content", types.TextValue(newContent)),
+		table.ValueParam("$source", types.TextValue(string(source))),
+		table.ValueParam("$author", ydbAuthor),
 	}
 
 	result2, err := r.tx.InTX().Execute(yql2, parameters...)
@@ -198,6 +255,16 @@ func (r *appRepositoryImpl) AppendPageRevision(pageID api.PageID, newContent str
 	}
 	defer result3.Close()
 
+	err = r.MarkPageDraftsNeedRebase(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.enqueuePageRevisionAppended(pageID)
+	if err != nil {
+		return nil, err
+	}
+
 	r.log.Debug("Appended page ", pageID, " revision with id ", revisionID)
 
 	return &revisionID, nil
@@ -265,3 +332,100 @@ func (r *appRepositoryImpl) SetPageTitle(pageID api.PageID, newTitle string) err
 
 	return nil
 }
+
+func (r *appRepositoryImpl) SetPageParentID(pageID api.PageID, parentPageID *api.PageID) error {
+	yql := `
+	UPDATE Page
+	SET parent_page_id = $parentPageID
+	WHERE page_id = $pageID;
+	`
+
+	var ydbParentPageID types.Value
+	if parentPageID != nil {
+		ydbParentPageID = types.OptionalValue(types.UuidValue(*parentPageID))
+	} else {
+		ydbParentPageID = types.NullValue(types.TypeUUID)
+	}
+
+	parameters := []table.ParameterOption{
+		table.ValueParam("$pageID", types.UuidValue(pageID)),
+		table.ValueParam("$parentPageID", ydbParentPageID),
+	}
+
+	result, err := r.tx.InTX().Execute(yql, parameters...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	return nil
+}
+
+// UpsertPage creates page with given slug or brings existing one to the given title
+// and content. New revision is appended only if content has changed.
+func (r *appRepositoryImpl) UpsertPage(yWikiSlug string, title string, content string) (*api.PageID, error) {
+	page, err := r.GetPageBySlug(yWikiSlug)
+	if errors.Is(err, models.ErrNoRows) {
+		return r.CreatePage(yWikiSlug, title, content)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Content != content {
+		_, err := r.AppendPageRevision(page.PageId, content, api.YwikiSync, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if page.Title != title {
+		err := r.SetPageTitle(page.PageId, title)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &page.PageId, nil
+}
+
+// GetClosestAncestorPageID returns ID of the stored page whose slug is the longest
+// proper prefix of the given slug. Nil is returned if there is no such page.
+func (r *appRepositoryImpl) GetClosestAncestorPageID(yWikiSlug string) (*api.PageID, error) {
+	ancestorSlugs := ywiki_slug.AncestorSlugs(yWikiSlug)
+	if len(ancestorSlugs) == 0 {
+		return nil, nil
+	}
+
+	yql := `
+	SELECT page_id, ywiki_slug
+	FROM Page
+	WHERE ywiki_slug IN $ancestorSlugs;
+	`
+
+	slugValues := make([]types.Value, len(ancestorSlugs))
+	for i, slug := range ancestorSlugs {
+		slugValues[i] = types.TextValue(slug)
+	}
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$ancestorSlugs", types.ListValue(slugValues...)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var closestPageID *api.PageID
+	closestSlugLength := 0
+	for result.NextRow() {
+		var pageID api.PageID
+		var slug string
+		if err := result.FetchRow(&pageID, &slug); err != nil {
+			return nil, err
+		}
+		if len(slug) > closestSlugLength {
+			closestPageID = &pageID
+			closestSlugLength = len(slug)
+		}
+	}
+
+	return closestPageID, nil
+}
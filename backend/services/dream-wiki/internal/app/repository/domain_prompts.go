@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+)
+
+// fetchPromptTemplateOverride scans columns in order of PromptTemplateOverride
+// table with fetch, which is FetchRow or FetchExactlyOne of the result.
+func fetchPromptTemplateOverride(fetch func(values ...any) error) (*internals.PromptTemplateOverride, error) {
+	var override internals.PromptTemplateOverride
+	var version int64
+	var maxTokens int64
+	var createdAt time.Time
+	err := fetch(&override.Name, &version, &override.SystemPrompt, &override.UserPrompt, &override.Temperature, &maxTokens, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+	override.Version = int(version)
+	override.MaxTokens = int(maxTokens)
+	override.CreatedAt = createdAt
+	return &override, nil
+}
+
+// GetLatestPromptTemplateOverride returns models.ErrNoRows if the template was
+// never overridden.
+func (r *appRepositoryImpl) GetLatestPromptTemplateOverride(name string) (*internals.PromptTemplateOverride, error) {
+	yql := `
+	SELECT name, version, system_prompt, user_prompt, temperature, max_tokens, created_at
+	FROM PromptTemplateOverride
+	WHERE name = $name
+	ORDER BY version DESC
+	LIMIT 1;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$name", types.TextValue(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return fetchPromptTemplateOverride(result.FetchExactlyOne)
+}
+
+// ListPromptTemplateOverrides returns overrides of the template from the
+// newest version.
+func (r *appRepositoryImpl) ListPromptTemplateOverrides(name string) ([]internals.PromptTemplateOverride, error) {
+	yql := `
+	SELECT name, version, system_prompt, user_prompt, temperature, max_tokens, created_at
+	FROM PromptTemplateOverride
+	WHERE name = $name
+	ORDER BY version DESC;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$name", types.TextValue(name)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	overrides := make([]internals.PromptTemplateOverride, 0)
+	for result.NextRow() {
+		override, err := fetchPromptTemplateOverride(result.FetchRow)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, *override)
+	}
+
+	return overrides, nil
+}
+
+// CreatePromptTemplateOverride fails if the version of the template already
+// exists. CreatedAt of override is ignored.
+func (r *appRepositoryImpl) CreatePromptTemplateOverride(override internals.PromptTemplateOverride) (*internals.PromptTemplateOverride, error) {
+	yql := `
+	INSERT INTO PromptTemplateOverride (name, version, system_prompt, user_prompt, temperature, max_tokens, created_at)
+	VALUES ($name, $version, $systemPrompt, $userPrompt, $temperature, $maxTokens, CurrentUtcTimestamp())
+	RETURNING name, version, system_prompt, user_prompt, temperature, max_tokens, created_at;
+	`
+
+	result, err := r.tx.InTX().Execute(yql,
+		table.ValueParam("$name", types.TextValue(override.Name)),
+		table.ValueParam("$version", types.Int64Value(int64(override.Version))),
+		table.ValueParam("$systemPrompt", types.TextValue(override.SystemPrompt)),
+		table.ValueParam("$userPrompt", types.TextValue(override.UserPrompt)),
+		table.ValueParam("$temperature", types.DoubleValue(override.Temperature)),
+		table.ValueParam("$maxTokens", types.Int64Value(int64(override.MaxTokens))),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return fetchPromptTemplateOverride(result.FetchExactlyOne)
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+)
+
+// Revision IDs are serial, so listing from new to old revisions is paginated by
+// the last returned revision ID.
+func decodeRevisionsCursor(cursor *api.Cursor) (idBefore int64) {
+	if cursor == nil {
+		return math.MaxInt64
+	}
+
+	idBefore, err := strconv.ParseInt(*cursor, 10, 64)
+	if err != nil {
+		return math.MaxInt64
+	}
+
+	return idBefore
+}
+
+func encodeRevisionsNextInfo(idBefore int64, hasMore bool) *api.NextInfo {
+	return &api.NextInfo{
+		Cursor:  strconv.FormatInt(idBefore, 10),
+		HasMore: hasMore,
+	}
+}
+
+func makeRevisionDigest(revisionID int64, pageID api.PageID, previousRevisionID *int64, createdAt time.Time, source string, author *string) api.RevisionDigest {
+	return api.RevisionDigest{
+		RevisionId:         revisionID,
+		PageId:             pageID,
+		PreviousRevisionId: previousRevisionID,
+		CreatedAt:          createdAt,
+		Source:             api.RevisionSource(source),
+		Author:             author,
+	}
+}
+
+func (r *appRepositoryImpl) ListPageRevisions(pageID api.PageID, cursor *api.Cursor, limit int64) ([]api.RevisionDigest, *api.NextInfo, error) {
+	yql := `
+	SELECT
+		revision_id,
+		page_id,
+		previous_revision_id,
+		created_at,
+		source,
+		author
+	FROM PageRevision
+	WHERE page_id = $pageID AND revision_id < $idBefore
+	ORDER BY revision_id DESC
+	LIMIT $limit;
+	`
+
+	idBefore := decodeRevisionsCursor(cursor)
+
+	// One extra row tells whether there are more revisions.
+	parameters := []table.ParameterOption{
+		table.ValueParam("$pageID", types.UuidValue(pageID)),
+		table.ValueParam("$idBefore", types.Int64Value(idBefore)),
+		table.ValueParam("$limit", types.Uint64Value(uint64(limit+1))),
+	}
+
+	result, err := r.tx.InTX().Execute(yql, parameters...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer result.Close()
+
+	revisions := make([]api.RevisionDigest, 0, limit)
+	for result.NextRow() && len(revisions) < int(limit) {
+		var revisionID int64
+		var retrievedPageID api.PageID
+		var previousRevisionID *int64
+		var createdAt time.Time
+		var source string
+		var author *string
+
+		err := result.FetchRow(&revisionID, &retrievedPageID, &previousRevisionID, &createdAt, &source, &author)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		revisions = append(revisions, makeRevisionDigest(revisionID, retrievedPageID, previousRevisionID, createdAt, source, author))
+		idBefore = revisionID
+	}
+
+	return revisions, encodeRevisionsNextInfo(idBefore, result.RowCount() > int(limit)), nil
+}
+
+func (r *appRepositoryImpl) GetRevisionByID(revisionID api.RevisionID) (*api.Revision, error) {
+	yql := `
+	SELECT
+		revision_id,
+		page_id,
+		previous_revision_id,
+		created_at,
+		source,
+		author,
+		content
+	FROM PageRevision
+	WHERE revision_id = $revisionID;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$revisionID", types.Int64Value(revisionID)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var retrievedRevisionID int64
+	var pageID api.PageID
+	var previousRevisionID *int64
+	var createdAt time.Time
+	var source string
+	var author *string
+	var content string
+
+	err = result.FetchExactlyOne(&retrievedRevisionID, &pageID, &previousRevisionID, &createdAt, &source, &author, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.Revision{
+		RevisionDigest: makeRevisionDigest(retrievedRevisionID, pageID, previousRevisionID, createdAt, source, author),
+		Content:        content,
+	}, nil
+}
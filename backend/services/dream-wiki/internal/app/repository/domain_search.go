@@ -6,13 +6,86 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
 )
 
-func (r *appRepositoryImpl) SearchByEmbedding(query string, queryEmbedding internals.Embedding, limit int) ([]internals.SearchResultItem, error) {
+// searchFiltersCondition is a YQL condition over Paragraph aliased as par,
+// its parameters are built by searchFiltersParams.
+const searchFiltersCondition = `
+	($filterByPages = false OR par.page_id IN $pageIDs)
+	AND ($includeHeaders OR NOT par.is_header)
+	AND ($headerPath = "" OR par.headers = $headerPath OR StartsWith(par.headers, $headerPath || "\n"))`
+
+func searchFiltersParams(filters internals.SearchFilters) []table.ParameterOption {
+	filterByPages := filters.PageIds != nil
+	pageIDs := types.ZeroValue(types.List(types.TypeUUID))
+	if filterByPages && len(*filters.PageIds) > 0 {
+		pageIDValues := make([]types.Value, 0, len(*filters.PageIds))
+		for _, pageID := range *filters.PageIds {
+			pageIDValues = append(pageIDValues, types.UuidValue(pageID))
+		}
+		pageIDs = types.ListValue(pageIDValues...)
+	}
+
+	headerPath := ""
+	if filters.HeaderPath != nil {
+		headerPath = strings.Join(*filters.HeaderPath, "\n")
+	}
+
+	return []table.ParameterOption{
+		table.ValueParam("$filterByPages", types.BoolValue(filterByPages)),
+		table.ValueParam("$pageIDs", pageIDs),
+		table.ValueParam("$includeHeaders", types.BoolValue(filters.IncludeHeaders)),
+		table.ValueParam("$headerPath", types.BytesValueFromString(headerPath)),
+	}
+}
+
+func (r *appRepositoryImpl) SearchByEmbedding(query string, queryEmbedding internals.Embedding, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error) {
+	return searchWithVectorIndex(r.embeddingIndex, queryEmbedding, filters, limit,
+		func(candidates []vectorindex.Key) ([]internals.SearchResultItem, error) {
+			return r.searchByEmbeddingAmong(candidates, queryEmbedding, filters, limit)
+		},
+		func() ([]internals.SearchResultItem, error) {
+			return r.executeEmbeddingSearch(embeddingSearchAllParagraphs, queryEmbedding, filters, limit)
+		},
+	)
+}
+
+const (
+	// Sources of paragraphs for executeEmbeddingSearch, par and page aliases
+	// are required.
+	embeddingSearchAllParagraphs = `Paragraph par
+	JOIN Page page ON page.page_id = par.page_id`
+	embeddingSearchCandidates = `AS_TABLE($candidates) AS candidate
+	JOIN Paragraph par ON par.page_id = candidate.page_id AND par.paragraph_index = candidate.paragraph_index
+	JOIN Page page ON page.page_id = par.page_id`
+)
+
+// searchByEmbeddingAmong looks up candidates by primary key and ranks them by
+// exact distance, so that vector index does not scan Paragraph table.
+func (r *appRepositoryImpl) searchByEmbeddingAmong(candidates []vectorindex.Key, queryEmbedding internals.Embedding, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error) {
+	if len(candidates) == 0 {
+		return []internals.SearchResultItem{}, nil
+	}
+
+	candidateValues := make([]types.Value, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateValues = append(candidateValues, types.StructValue(
+			types.StructFieldValue("page_id", types.UuidValue(candidate.PageID)),
+			types.StructFieldValue("paragraph_index", types.Int64Value(int64(candidate.ParagraphIndex))),
+		))
+	}
+
+	return r.executeEmbeddingSearch(embeddingSearchCandidates, queryEmbedding, filters, limit,
+		table.ValueParam("$candidates", types.ListValue(candidateValues...)))
+}
+
+func (r *appRepositoryImpl) executeEmbeddingSearch(source string, queryEmbedding internals.Embedding, filters internals.SearchFilters, limit int, sourceParams ...table.ParameterOption) ([]internals.SearchResultItem, error) {
 	yql := `
 	$targetEmbedding = Knn::ToBinaryStringFloat($queryEmbedding);
 
@@ -26,18 +99,20 @@ func (r *appRepositoryImpl) SearchByEmbedding(query string, queryEmbedding inter
 		par.anchor_link_slug,
 		par.line_number,
 		Unwrap(Knn::CosineDistance(Unwrap(par.embedding), $targetEmbedding)) As CosineDistance
-	FROM Paragraph par
-	JOIN Page page USING(page_id)
-	WHERE NOT is_header
-	ORDER BY Knn::CosineDistance(embedding, $targetEmbedding)
+	FROM ` + source + `
+	WHERE ` + searchFiltersCondition + `
+	ORDER BY Knn::CosineDistance(par.embedding, $targetEmbedding)
 	LIMIT $limit;
 `
 
 	yqlEmbedding := embeddingToYDBList(queryEmbedding)
 
-	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$queryEmbedding", yqlEmbedding),
+	params := append(searchFiltersParams(filters),
+		table.ValueParam("$queryEmbedding", yqlEmbedding),
 		table.ValueParam("$limit", types.Uint64Value(uint64(limit))),
 	)
+	params = append(params, sourceParams...)
+	result, err := r.tx.InTX().Execute(yql, params...)
 	if err != nil {
 		return nil, err
 	}
@@ -78,6 +153,7 @@ func (r *appRepositoryImpl) SearchByEmbedding(query string, queryEmbedding inter
 			Headers:          strings.Split(headers, "\n"),
 			ParagraphIndex:   int(paragraphIndex),
 			LineIndex:        int(lineNumber),
+			Score:            float64(distance),
 		})
 	}
 
@@ -85,7 +161,7 @@ func (r *appRepositoryImpl) SearchByEmbedding(query string, queryEmbedding inter
 }
 
 func (r *appRepositoryImpl) SearchByEmbeddingWithContext(query string, queryEmbedding internals.Embedding, contextSize int) ([]internals.ParagraphWithContext, error) {
-	initialResults, err := r.SearchByEmbedding(query, queryEmbedding, 3)
+	initialResults, err := r.SearchByEmbedding(query, queryEmbedding, internals.SearchFilters{}, 3)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +179,7 @@ func (r *appRepositoryImpl) SearchByEmbeddingWithContext(query string, queryEmbe
 			SELECT
 				page_id,
 				line_number,
+				end_line_number,
 				content,
 				paragraph_index
 			FROM Paragraph
@@ -125,10 +202,11 @@ func (r *appRepositoryImpl) SearchByEmbeddingWithContext(query string, queryEmbe
 		for result.NextRow() {
 			var pageID api.PageID
 			var lineNumber int64
+			var endLineNumber int64
 			var content string
 			var paragraphIndex int64
 
-			err = result.FetchRow(&pageID, &lineNumber, &content, &paragraphIndex)
+			err = result.FetchRow(&pageID, &lineNumber, &endLineNumber, &content, &paragraphIndex)
 			if err != nil {
 				return nil, err
 			}
@@ -137,7 +215,7 @@ func (r *appRepositoryImpl) SearchByEmbeddingWithContext(query string, queryEmbe
 				PageId:          pageID,
 				ParagraphIndex:  int(paragraphIndex),
 				StartLineNumber: int(lineNumber),
-				EndLineNumber:   int(lineNumber),
+				EndLineNumber:   int(endLineNumber),
 				Content:         content,
 			}
 
@@ -155,54 +233,227 @@ func (r *appRepositoryImpl) SearchByEmbeddingWithContext(query string, queryEmbe
 	return allParagraphs, nil
 }
 
-func (r *appRepositoryImpl) SearchByTerms(terms []string, limit int) ([]internals.SearchResultItem, error) {
-	if len(terms) == 0 {
-		return []internals.SearchResultItem{}, nil
-	}
+// GetParagraphWithContext joins paragraph with up to contextSize neighbours on
+// each side. Header paragraphs are kept, so that the context reads like the page.
+func (r *appRepositoryImpl) GetParagraphWithContext(pageID api.PageID, paragraphIndex int, contextSize int) (*internals.ParagraphWithContext, error) {
+	yql := `
+		SELECT
+			page_id,
+			line_number,
+			end_line_number,
+			content,
+			paragraph_index
+		FROM Paragraph
+		WHERE page_id = $page_id AND paragraph_index >= $start_index AND paragraph_index <= $end_index
+		ORDER BY paragraph_index
+	`
 
-	totalDocsQuery := `SELECT Count(DISTINCT page_id) AS total FROM Paragraph`
-	totalDocsResult, err := r.tx.InTX().Execute(totalDocsQuery)
+	result, err := r.tx.InTX().Execute(yql,
+		table.ValueParam("$page_id", types.UuidValue(pageID)),
+		table.ValueParam("$start_index", types.Int32Value(int32(paragraphIndex-contextSize))),
+		table.ValueParam("$end_index", types.Int32Value(int32(paragraphIndex+contextSize))))
 	if err != nil {
 		return nil, err
 	}
-	defer totalDocsResult.Close()
+	defer result.Close()
+
+	var paragraph *internals.ParagraphWithContext
+	for result.NextRow() {
+		var retrievedPageID api.PageID
+		var lineNumber int64
+		var endLineNumber int64
+		var content string
+		var retrievedParagraphIndex int64
+
+		err = result.FetchRow(&retrievedPageID, &lineNumber, &endLineNumber, &content, &retrievedParagraphIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if paragraph == nil {
+			paragraph = &internals.ParagraphWithContext{
+				PageId:          retrievedPageID,
+				ParagraphIndex:  int(retrievedParagraphIndex),
+				StartLineNumber: int(lineNumber),
+				EndLineNumber:   int(endLineNumber),
+				Content:         content,
+			}
+			continue
+		}
+		paragraph.Content += "\n\n" + content
+		paragraph.EndLineNumber = int(endLineNumber)
+	}
+
+	if paragraph == nil {
+		return nil, models.ErrNoRows
+	}
+	return paragraph, nil
+}
+
+const (
+	// BM25 parameters: bm25K1 limits contribution of repeated term, bm25B is the
+	// strength of paragraph length normalization.
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type (
+	// termMatchedParagraph is a paragraph containing some of the searched terms.
+	termMatchedParagraph struct {
+		data            internals.SearchResultItem
+		termFrequencies map[string]int64
+		// length in terms.
+		length int64
+	}
+
+	// termCorpusStats are statistics of indexed paragraphs, see
+	// AppRepository.SetPageTermStats.
+	termCorpusStats struct {
+		paragraphsCount int64
+		termsCount      int64
+		// termDocFreq is the number of paragraphs containing the term.
+		termDocFreq map[string]int64
+	}
+)
+
+// bm25IDF is non-negative BM25 inverse document frequency.
+func bm25IDF(docFreq int64, docsCount int64) float64 {
+	return math.Log(1 + (float64(docsCount-docFreq)+0.5)/(float64(docFreq)+0.5))
+}
+
+// rankTermMatchedParagraphs scores paragraphs by BM25, documents are
+// paragraphs.
+func rankTermMatchedParagraphs(paragraphs map[string]*termMatchedParagraph, stats termCorpusStats, limit int) []internals.SearchResultItem {
+	averageLength := 0.0
+	if stats.paragraphsCount > 0 {
+		averageLength = float64(stats.termsCount) / float64(stats.paragraphsCount)
+	}
 
-	var totalDocs uint64
-	err = totalDocsResult.FetchExactlyOne(&totalDocs)
+	searchResult := make([]internals.SearchResultItem, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		lengthRatio := 1.0
+		if averageLength > 0 {
+			lengthRatio = float64(paragraph.length) / averageLength
+		}
+
+		score := 0.0
+		for term, freq := range paragraph.termFrequencies {
+			tf := float64(freq)
+			score += bm25IDF(stats.termDocFreq[term], stats.paragraphsCount) * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*lengthRatio))
+		}
+
+		matchedTerms := make([]string, 0, len(paragraph.termFrequencies))
+		for term := range paragraph.termFrequencies {
+			matchedTerms = append(matchedTerms, term)
+		}
+		sort.Strings(matchedTerms)
+
+		paragraph.data.Score = score
+		paragraph.data.MatchedTerms = &matchedTerms
+		searchResult = append(searchResult, paragraph.data)
+	}
+
+	// Ties are broken by paragraph position, so that paginated results are stable.
+	sort.Slice(searchResult, func(i, j int) bool {
+		if searchResult[i].Score != searchResult[j].Score {
+			return searchResult[i].Score > searchResult[j].Score
+		}
+		if searchResult[i].PageId != searchResult[j].PageId {
+			return searchResult[i].PageId.String() < searchResult[j].PageId.String()
+		}
+		return searchResult[i].ParagraphIndex < searchResult[j].ParagraphIndex
+	})
+
+	return searchResult[:min(len(searchResult), limit)]
+}
+
+func (r *appRepositoryImpl) getTermCorpusStats(yqlTerms types.Value) (*termCorpusStats, error) {
+	corpusQuery := `
+		SELECT
+			Coalesce(Sum(paragraphs_count), 0) AS paragraphs_count,
+			Coalesce(Sum(terms_count), 0) AS terms_count
+		FROM PageTermStats;
+	`
+	corpusResult, err := r.tx.InTX().Execute(corpusQuery)
 	if err != nil {
 		return nil, err
 	}
+	defer corpusResult.Close()
 
-	termList := make([]types.Value, len(terms))
-	for i, term := range terms {
-		termList[i] = types.TextValue(term)
+	stats := &termCorpusStats{termDocFreq: make(map[string]int64)}
+	err = corpusResult.FetchExactlyOne(&stats.paragraphsCount, &stats.termsCount)
+	if err != nil {
+		return nil, err
 	}
-	yqlTerms := types.ListValue(termList...)
 
 	docFreqQuery := `
 		SELECT
 			term,
-			Count(DISTINCT page_id) AS doc_freq
-		FROM Term
+			Coalesce(Sum(paragraphs_count), 0) AS doc_freq
+		FROM TermPageStats
 		WHERE term IN $terms
-		GROUP BY term
+		GROUP BY term;
 	`
-
 	docFreqResult, err := r.tx.InTX().Execute(docFreqQuery, table.ValueParam("$terms", yqlTerms))
 	if err != nil {
 		return nil, err
 	}
 	defer docFreqResult.Close()
 
-	termDocFreq := make(map[string]int64)
 	for docFreqResult.NextRow() {
 		var term string
-		var docFreq uint64
+		var docFreq int64
 		err = docFreqResult.FetchRow(&term, &docFreq)
 		if err != nil {
 			return nil, err
 		}
-		termDocFreq[term] = int64(docFreq)
+		stats.termDocFreq[term] = docFreq
+	}
+
+	return stats, nil
+}
+
+// GetTermVocabulary returns distinct indexed terms starting with prefix.
+func (r *appRepositoryImpl) GetTermVocabulary(prefix string) ([]string, error) {
+	yql := `
+		SELECT DISTINCT term
+		FROM TermPageStats
+		WHERE StartsWith(term, $prefix);
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$prefix", types.TextValue(prefix)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	vocabulary := make([]string, 0)
+	for result.NextRow() {
+		var term string
+		err = result.FetchRow(&term)
+		if err != nil {
+			return nil, err
+		}
+		vocabulary = append(vocabulary, term)
+	}
+
+	return vocabulary, nil
+}
+
+func (r *appRepositoryImpl) SearchByTerms(terms []string, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error) {
+	if len(terms) == 0 {
+		return []internals.SearchResultItem{}, nil
+	}
+
+	termList := make([]types.Value, len(terms))
+	for i, term := range terms {
+		termList[i] = types.TextValue(term)
+	}
+	yqlTerms := types.ListValue(termList...)
+
+	stats, err := r.getTermCorpusStats(yqlTerms)
+	if err != nil {
+		return nil, err
 	}
 
 	paragraphsQuery := `
@@ -211,28 +462,27 @@ func (r *appRepositoryImpl) SearchByTerms(terms []string, limit int) ([]internal
 			t.paragraph_index,
 			t.term,
 			t.times_in,
-			p.content,
-			p.headers,
+			par.content,
+			par.headers,
+			par.anchor_link_slug,
+			par.line_number,
+			par.terms_count,
 			page.ywiki_slug,
 			page.title
 		FROM Term t
-		JOIN Paragraph p ON t.page_id = p.page_id AND t.paragraph_index = p.paragraph_index
+		JOIN Paragraph par ON t.page_id = par.page_id AND t.paragraph_index = par.paragraph_index
 		JOIN Page page ON t.page_id = page.page_id
-		WHERE t.term IN $terms
+		WHERE t.term IN $terms AND ` + searchFiltersCondition + `
 	`
 
-	paragraphsResult, err := r.tx.InTX().Execute(paragraphsQuery, table.ValueParam("$terms", yqlTerms))
+	paragraphsParams := append(searchFiltersParams(filters), table.ValueParam("$terms", yqlTerms))
+	paragraphsResult, err := r.tx.InTX().Execute(paragraphsQuery, paragraphsParams...)
 	if err != nil {
 		return nil, err
 	}
 	defer paragraphsResult.Close()
 
-	type paragraphData struct {
-		data            internals.SearchResultItem
-		termFrequencies map[string]int64
-	}
-
-	paragraphs := make(map[string]*paragraphData)
+	paragraphs := make(map[string]*termMatchedParagraph)
 	for paragraphsResult.NextRow() {
 		var pageID api.PageID
 		var paragraphIndex int64
@@ -240,75 +490,42 @@ func (r *appRepositoryImpl) SearchByTerms(terms []string, limit int) ([]internal
 		var timesIn int64
 		var content string
 		var headers string
+		var anchorLinkSlug string
+		var lineNumber int64
+		var termsCount int64
 		var pageSlug string
 		var title string
 
-		err = paragraphsResult.FetchRow(&pageID, &paragraphIndex, &term, &timesIn, &content, &headers, &pageSlug, &title)
+		err = paragraphsResult.FetchRow(&pageID, &paragraphIndex, &term, &timesIn, &content, &headers, &anchorLinkSlug, &lineNumber, &termsCount, &pageSlug, &title)
 		if err != nil {
 			return nil, err
 		}
 
 		key := fmt.Sprintf("%s_%d", pageID.String(), paragraphIndex)
 		if _, exists := paragraphs[key]; !exists {
-			paragraphs[key] = &paragraphData{
+			paragraphs[key] = &termMatchedParagraph{
 				data: internals.SearchResultItem{
 					PageId:           pageID,
 					ParagraphIndex:   int(paragraphIndex),
 					ParagraphContent: content,
 					Headers:          strings.Split(headers, "\n"),
+					AnchorSlug:       &anchorLinkSlug,
+					LineIndex:        int(lineNumber),
 					PageSlug:         pageSlug,
 					PageTitle:        title,
 				},
 				termFrequencies: make(map[string]int64),
+				length:          termsCount,
 			}
 		}
 		paragraphs[key].termFrequencies[term] = timesIn
 	}
 
-	type scoredParagraph struct {
-		data  internals.SearchResultItem
-		score float64
-	}
-
-	var scoredParagraphs []scoredParagraph
-	for _, paragraph := range paragraphs {
-		score := 0.0
-		totalTermsInParagraph := 0
-		for _, freq := range paragraph.termFrequencies {
-			totalTermsInParagraph += int(freq)
-		}
-
-		for term, freq := range paragraph.termFrequencies {
-			if docFreq, exists := termDocFreq[term]; exists {
-				tf := float64(freq) / float64(totalTermsInParagraph)
-				idf := math.Log(float64(totalDocs) / float64(docFreq))
-				score += tf * idf
-			}
-		}
-
-		scoredParagraphs = append(scoredParagraphs, scoredParagraph{
-			data:  paragraph.data,
-			score: score,
-		})
-	}
-
-	sort.Slice(scoredParagraphs, func(i, j int) bool {
-		return scoredParagraphs[i].score > scoredParagraphs[j].score
-	})
-
-	resultLimit := min(len(scoredParagraphs), limit)
-
-	searchResult := make([]internals.SearchResultItem, 0, resultLimit)
-	for i := range resultLimit {
-		paragraph := scoredParagraphs[i]
-		searchResult = append(searchResult, paragraph.data)
-	}
-
-	return searchResult, nil
+	return rankTermMatchedParagraphs(paragraphs, *stats, limit), nil
 }
 
 func (r *appRepositoryImpl) SearchByTermsWithContext(terms []string, contextSize int) ([]internals.ParagraphWithContext, error) {
-	initialResults, err := r.SearchByTerms(terms, 3)
+	initialResults, err := r.SearchByTerms(terms, internals.SearchFilters{}, 3)
 	if err != nil {
 		return nil, err
 	}
@@ -326,6 +543,7 @@ func (r *appRepositoryImpl) SearchByTermsWithContext(terms []string, contextSize
 		SELECT
 			page_id,
 			line_number,
+			end_line_number,
 			content,
 			paragraph_index
 		FROM Paragraph
@@ -345,10 +563,11 @@ func (r *appRepositoryImpl) SearchByTermsWithContext(terms []string, contextSize
 		for result.NextRow() {
 			var pageID api.PageID
 			var lineNumber int64
+			var endLineNumber int64
 			var content string
 			var paragraphIndex int64
 
-			err = result.FetchRow(&pageID, &lineNumber, &content, &paragraphIndex)
+			err = result.FetchRow(&pageID, &lineNumber, &endLineNumber, &content, &paragraphIndex)
 			if err != nil {
 				return nil, err
 			}
@@ -357,7 +576,7 @@ func (r *appRepositoryImpl) SearchByTermsWithContext(terms []string, contextSize
 				PageId:          pageID,
 				ParagraphIndex:  int(paragraphIndex),
 				StartLineNumber: int(lineNumber),
-				EndLineNumber:   int(lineNumber),
+				EndLineNumber:   int(endLineNumber),
 				Content:         content,
 			}
 
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"fmt"
+	"math"
 	"sort"
 	"testing"
 
@@ -166,3 +168,101 @@ func TestMergeOverlappingParagraphs(t *testing.T) {
 		})
 	}
 }
+
+func makeTermMatchedParagraphs(paragraphs ...*termMatchedParagraph) map[string]*termMatchedParagraph {
+	result := make(map[string]*termMatchedParagraph, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		result[fmt.Sprintf("%s_%d", paragraph.data.PageId, paragraph.data.ParagraphIndex)] = paragraph
+	}
+	return result
+}
+
+func termMatched(paragraphIndex int, length int64, termFrequencies map[string]int64) *termMatchedParagraph {
+	return &termMatchedParagraph{
+		data:            internals.SearchResultItem{ParagraphIndex: paragraphIndex},
+		termFrequencies: termFrequencies,
+		length:          length,
+	}
+}
+
+func rankedParagraphIndexes(result []internals.SearchResultItem) []int {
+	indexes := make([]int, 0, len(result))
+	for _, item := range result {
+		indexes = append(indexes, item.ParagraphIndex)
+	}
+	return indexes
+}
+
+func TestRankTermMatchedParagraphsBM25(t *testing.T) {
+	stats := termCorpusStats{
+		paragraphsCount: 100,
+		termsCount:      1000,
+		termDocFreq:     map[string]int64{"deploy": 50, "helm": 2, "make": 10},
+	}
+
+	tests := []struct {
+		name       string
+		paragraphs []*termMatchedParagraph
+		expected   []int
+	}{
+		{
+			name: "Rare term outweighs common one",
+			paragraphs: []*termMatchedParagraph{
+				termMatched(0, 10, map[string]int64{"deploy": 1}),
+				termMatched(1, 10, map[string]int64{"helm": 1}),
+			},
+			expected: []int{1, 0},
+		},
+		{
+			name: "Shorter paragraph wins with the same term frequency",
+			paragraphs: []*termMatchedParagraph{
+				termMatched(0, 40, map[string]int64{"make": 1}),
+				termMatched(1, 5, map[string]int64{"make": 1}),
+				termMatched(2, 10, map[string]int64{"make": 1}),
+			},
+			expected: []int{1, 2, 0},
+		},
+		{
+			name: "Repeated term saturates",
+			paragraphs: []*termMatchedParagraph{
+				termMatched(0, 10, map[string]int64{"make": 8}),
+				termMatched(1, 10, map[string]int64{"make": 1, "helm": 1}),
+				termMatched(2, 10, map[string]int64{"make": 1}),
+			},
+			expected: []int{1, 0, 2},
+		},
+		{
+			name: "Ties are ordered by position",
+			paragraphs: []*termMatchedParagraph{
+				termMatched(3, 10, map[string]int64{"make": 1}),
+				termMatched(1, 10, map[string]int64{"make": 1}),
+			},
+			expected: []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := rankTermMatchedParagraphs(makeTermMatchedParagraphs(tt.paragraphs...), stats, 10)
+			assert.Equal(t, tt.expected, rankedParagraphIndexes(result))
+		})
+	}
+}
+
+func TestRankTermMatchedParagraphsScore(t *testing.T) {
+	stats := termCorpusStats{
+		paragraphsCount: 10,
+		termsCount:      100,
+		termDocFreq:     map[string]int64{"helm": 1},
+	}
+
+	result := rankTermMatchedParagraphs(makeTermMatchedParagraphs(
+		termMatched(0, 10, map[string]int64{"helm": 1}),
+	), stats, 10)
+
+	// With average length and single occurrence the score equals idf:
+	// ln(1 + (10 - 1 + 0.5) / (1 + 0.5)).
+	assert.Len(t, result, 1)
+	assert.InDelta(t, math.Log(22.0/3.0), result[0].Score, 1e-9)
+	assert.Equal(t, []string{"helm"}, *result[0].MatchedTerms)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table"
+	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
+)
+
+func (r *appRepositoryImpl) ListSynonymGroups() ([]api.SynonymGroup, error) {
+	yql := `
+	SELECT synonym_group_id, phrases
+	FROM SynonymGroup
+	ORDER BY created_at, synonym_group_id;
+	`
+
+	result, err := r.tx.InTX().Execute(yql)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	groups := make([]api.SynonymGroup, 0)
+	for result.NextRow() {
+		var group api.SynonymGroup
+		var phrasesBytes []byte
+		err = result.FetchRow(&group.SynonymGroupId, &phrasesBytes)
+		if err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal(phrasesBytes, &group.Phrases); err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	return groups, nil
+}
+
+func (r *appRepositoryImpl) CreateSynonymGroup(phrases []string) (*api.SynonymGroupID, error) {
+	yql := `
+	INSERT INTO SynonymGroup (synonym_group_id, phrases, created_at, updated_at)
+	VALUES (RandomUuid(4), $phrases, CurrentUtcTimestamp(), CurrentUtcTimestamp())
+	RETURNING synonym_group_id;
+	`
+
+	phrasesBytes, err := json.Marshal(phrases)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$phrases", types.JSONValueFromBytes(phrasesBytes)))
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	var synonymGroupID api.SynonymGroupID
+	err = result.FetchExactlyOne(&synonymGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &synonymGroupID, nil
+}
+
+// SetSynonymGroupPhrases returns models.ErrNoRows if there is no such group.
+func (r *appRepositoryImpl) SetSynonymGroupPhrases(synonymGroupID api.SynonymGroupID, phrases []string) error {
+	yql := `
+	UPDATE SynonymGroup
+	SET phrases = $phrases, updated_at = CurrentUtcTimestamp()
+	WHERE synonym_group_id = $synonymGroupID
+	RETURNING synonym_group_id;
+	`
+
+	phrasesBytes, err := json.Marshal(phrases)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.tx.InTX().Execute(yql,
+		table.ValueParam("$synonymGroupID", types.UuidValue(synonymGroupID)),
+		table.ValueParam("$phrases", types.JSONValueFromBytes(phrasesBytes)),
+	)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	var updatedID api.SynonymGroupID
+	return result.FetchExactlyOne(&updatedID)
+}
+
+func (r *appRepositoryImpl) RemoveSynonymGroup(synonymGroupID api.SynonymGroupID) error {
+	yql := `
+	DELETE FROM SynonymGroup WHERE synonym_group_id = $synonymGroupID;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$synonymGroupID", types.UuidValue(synonymGroupID)))
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table"
@@ -85,7 +86,7 @@ func (r *appRepositoryImpl) CreateTaskActionResult(actionID internals.TaskAction
 
 func (r *appRepositoryImpl) EnqueueTaskAction(actionID internals.TaskActionID) error {
 	topicClient := r.tx.TopicClient()
-	writer, err := topicClient.StartTransactionalWriter(r.tx.GetTX(), "TaskActionToExecute", topicoptions.WithWriterWaitServerAck(true))
+	writer, err := topicClient.StartTransactionalWriter(r.tx.GetTX(), taskActionsTopicName, topicoptions.WithWriterWaitServerAck(true))
 	if err != nil {
 		return err
 	}
@@ -100,7 +101,7 @@ func (r *appRepositoryImpl) EnqueueTaskAction(actionID internals.TaskActionID) e
 
 func (r *appRepositoryImpl) EnqueueTaskActionResult(actionID internals.TaskActionID) error {
 	topicClient := r.tx.TopicClient()
-	writer, err := topicClient.StartTransactionalWriter(r.tx.GetTX(), "TaskActionResultReady", topicoptions.WithWriterWaitServerAck(true))
+	writer, err := topicClient.StartTransactionalWriter(r.tx.GetTX(), taskActionResultsTopicName, topicoptions.WithWriterWaitServerAck(true))
 	if err != nil {
 		return err
 	}
@@ -284,3 +285,149 @@ func (r *appRepositoryImpl) GetTaskActionsByTaskID(taskID api.TaskID) ([]api.Tas
 
 	return taskActions, nil
 }
+
+func (r *appRepositoryImpl) GetTaskActionIDsByStatus(taskID api.TaskID, statuses []internals.TaskActionStatus) ([]internals.TaskActionID, error) {
+	yql := `
+	SELECT task_action_id
+	FROM TaskAction
+	WHERE task_id = $taskID AND status IN $statuses
+	ORDER BY task_action_id;
+	`
+
+	statusValues := make([]types.Value, len(statuses))
+	for i, status := range statuses {
+		statusValues[i] = types.TextValue(string(status))
+	}
+
+	parameters := []table.ParameterOption{
+		table.ValueParam("$taskID", types.Int64Value(taskID)),
+		table.ValueParam("$statuses", types.ListValue(statusValues...)),
+	}
+
+	result, err := r.tx.InTX().Execute(yql, parameters...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	actionIDs := make([]internals.TaskActionID, 0, result.RowCount())
+	for result.NextRow() {
+		var actionID internals.TaskActionID
+		if err := result.FetchRow(&actionID); err != nil {
+			return nil, err
+		}
+		actionIDs = append(actionIDs, actionID)
+	}
+
+	return actionIDs, nil
+}
+
+func (r *appRepositoryImpl) AbandonPendingTaskActions(taskID api.TaskID) error {
+	yql := `
+	UPDATE TaskAction
+	SET
+		status = 'abandoned',
+		updated_at = CurrentUtcDatetime()
+	WHERE task_id = $taskID AND status IN ('new', 'executing', 'submitted');
+	`
+
+	result, err := r.tx.InTX().Execute(yql, table.ValueParam("$taskID", types.Int64Value(taskID)))
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	r.log.Debug("Abandoned pending task actions for task id ", taskID)
+
+	return nil
+}
+
+// SetPendingLLMOperation remembers asynchronous LLM request of the action.
+// Operation of the previous attempt is replaced on retry.
+func (r *appRepositoryImpl) SetPendingLLMOperation(actionID internals.TaskActionID, operationID string) error {
+	yql := `
+	UPSERT INTO PendingLLMOperation(task_action_id, operation_id, submitted_at)
+	VALUES ($actionID, $operationID, CurrentUtcDatetime());
+	`
+
+	parameters := []table.ParameterOption{
+		table.ValueParam("$actionID", types.Int64Value(actionID)),
+		table.ValueParam("$operationID", types.TextValue(operationID)),
+	}
+
+	result, err := r.tx.InTX().Execute(yql, parameters...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	r.log.Debug("Set pending LLM operation for action id ", actionID)
+
+	return nil
+}
+
+func (r *appRepositoryImpl) GetPendingLLMOperations() ([]internals.PendingLLMOperation, error) {
+	yql := `
+	SELECT task_action_id, operation_id, submitted_at
+	FROM PendingLLMOperation
+	ORDER BY task_action_id;
+	`
+
+	result, err := r.tx.InTX().Execute(yql)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	operations := make([]internals.PendingLLMOperation, 0, result.RowCount())
+	for result.NextRow() {
+		var operation internals.PendingLLMOperation
+		if err := result.FetchRow(&operation.TaskActionId, &operation.OperationId, &operation.SubmittedAt); err != nil {
+			return nil, err
+		}
+		operations = append(operations, operation)
+	}
+
+	return operations, nil
+}
+
+// RemovePendingLLMOperation removes the operation if it is still the pending
+// one of the action. models.ErrNoRows is returned if it was already removed or
+// replaced by the operation of a retry.
+func (r *appRepositoryImpl) RemovePendingLLMOperation(actionID internals.TaskActionID, operationID string) error {
+	parameters := []table.ParameterOption{
+		table.ValueParam("$actionID", types.Int64Value(actionID)),
+		table.ValueParam("$operationID", types.TextValue(operationID)),
+	}
+
+	yql := `
+	SELECT task_action_id
+	FROM PendingLLMOperation
+	WHERE task_action_id = $actionID AND operation_id = $operationID;
+	`
+
+	result, err := r.tx.InTX().Execute(yql, parameters...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+
+	if result.RowCount() == 0 {
+		return models.ErrNoRows
+	}
+
+	yql = `
+	DELETE FROM PendingLLMOperation
+	WHERE task_action_id = $actionID AND operation_id = $operationID;
+	`
+
+	deleteResult, err := r.tx.InTX().Execute(yql, parameters...)
+	if err != nil {
+		return err
+	}
+	defer deleteResult.Close()
+
+	r.log.Debug("Removed pending LLM operation for action id ", actionID)
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// vectorIndexCandidatesFactor is how many more candidates than requested are
+// taken from the index, because header filters are applied to candidates
+// afterwards.
+const vectorIndexCandidatesFactor = 4
+
+type (
+	searchAmongFunc func(candidates []vectorindex.Key) ([]internals.SearchResultItem, error)
+	searchAllFunc   func() ([]internals.SearchResultItem, error)
+
+	// embeddingIndexUpdate collects paragraph changes of a transaction, which
+	// are applied to the vector index when the transaction is committed.
+	// Paragraphs are added only after RemovePageIndexation of their page, so
+	// collected entries of a page are all its paragraphs.
+	embeddingIndexUpdate struct {
+		reset bool
+		pages map[api.PageID][]vectorindex.Entry
+	}
+)
+
+func (u *embeddingIndexUpdate) removeAllPages() {
+	u.reset = true
+	u.pages = nil
+}
+
+func (u *embeddingIndexUpdate) removePage(pageID api.PageID) {
+	if u.pages == nil {
+		u.pages = make(map[api.PageID][]vectorindex.Entry)
+	}
+	u.pages[pageID] = []vectorindex.Entry{}
+}
+
+func (u *embeddingIndexUpdate) addParagraph(paragraph internals.ParagraphWithEmbedding) {
+	if u.pages == nil {
+		u.pages = make(map[api.PageID][]vectorindex.Entry)
+	}
+	u.pages[paragraph.PageId] = append(u.pages[paragraph.PageId], vectorindex.Entry{
+		Key:       vectorindex.Key{PageID: paragraph.PageId, ParagraphIndex: paragraph.ParagraphIndex},
+		Embedding: paragraph.Embedding,
+	})
+}
+
+// apply is called after commit and empties the update. index may be nil.
+func (u *embeddingIndexUpdate) apply(index *vectorindex.Index) {
+	if index != nil {
+		if u.reset {
+			index.Reset()
+		}
+		for pageID, entries := range u.pages {
+			index.ReplacePage(pageID, entries)
+		}
+	}
+	*u = embeddingIndexUpdate{}
+}
+
+// searchWithVectorIndex ranks candidates found by the index with searchAmong.
+// searchAll scans all paragraphs when the index is absent or not ready, or when
+// candidates give fewer than limit results.
+func searchWithVectorIndex(
+	index *vectorindex.Index,
+	queryEmbedding internals.Embedding,
+	filters internals.SearchFilters,
+	limit int,
+	searchAmong searchAmongFunc,
+	searchAll searchAllFunc,
+) ([]internals.SearchResultItem, error) {
+	if index == nil {
+		return searchAll()
+	}
+
+	start := time.Now()
+	var filter func(vectorindex.Key) bool
+	if filters.PageIds != nil {
+		pageIDs := make(map[internals.PageID]struct{}, len(*filters.PageIds))
+		for _, pageID := range *filters.PageIds {
+			pageIDs[pageID] = struct{}{}
+		}
+		filter = func(key vectorindex.Key) bool {
+			_, ok := pageIDs[key.PageID]
+			return ok
+		}
+	}
+
+	neighbours, ready := index.Search(queryEmbedding, limit*vectorIndexCandidatesFactor, filter)
+	if ready {
+		candidates := make([]vectorindex.Key, 0, len(neighbours))
+		for _, neighbour := range neighbours {
+			candidates = append(candidates, neighbour.Key)
+		}
+		searchResult, err := searchAmong(candidates)
+		if err != nil {
+			return nil, err
+		}
+		if len(searchResult) >= limit {
+			index.RecordSearch(true, time.Since(start))
+			return searchResult, nil
+		}
+		index.RecordFallback()
+	}
+
+	start = time.Now()
+	searchResult, err := searchAll()
+	if err != nil {
+		return nil, err
+	}
+	index.RecordSearch(false, time.Since(start))
+	return searchResult, nil
+}
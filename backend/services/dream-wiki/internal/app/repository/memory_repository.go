@@ -0,0 +1,1048 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/ywiki_slug"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// memoryRepository implements AppRepository over memoryStorage. Methods follow
+// their YQL counterparts in domain_*.go, including their quirks, so that
+// switching storage does not change application behavior.
+type memoryRepository struct {
+	ctx                  context.Context
+	log                  logger.Logger
+	tx                   *memoryTransaction
+	embeddingIndex       *vectorindex.Index
+	embeddingIndexUpdate embeddingIndexUpdate
+}
+
+var (
+	_ AppRepository = &memoryRepository{}
+)
+
+const (
+	revisionSequence           = "PageRevision.revision_id"
+	integrationLogSequence     = "IntegrationLogField.field_id"
+	taskSequence               = "Task.task_id"
+	taskActionSequence         = "TaskAction.task_action_id"
+	expectedExactlyOneRowError = "expected exactly one row"
+)
+
+func (r *memoryRepository) Commit() error {
+	err := r.tx.commit()
+	if err != nil {
+		return err
+	}
+	r.embeddingIndexUpdate.apply(r.embeddingIndex)
+	return nil
+}
+
+func (r *memoryRepository) Rollback() {
+	r.tx.rollback()
+	r.embeddingIndexUpdate = embeddingIndexUpdate{}
+}
+
+// exactlyOne mirrors ResultSet.FetchExactlyOne.
+func exactlyOne[T any](rows []T) (T, error) {
+	var row T
+	if len(rows) == 0 {
+		return row, models.ErrNoRows
+	}
+	if len(rows) > 1 {
+		return row, errors.New(expectedExactlyOneRowError)
+	}
+	return rows[0], nil
+}
+
+// currentRevision joins page with its current revision.
+func (r *memoryRepository) currentRevision(page memoryPage) (memoryRevision, bool) {
+	if page.currentRevisionID == nil {
+		return memoryRevision{}, false
+	}
+	return r.tx.revisions.get(*page.currentRevisionID)
+}
+
+// domain_drafts.go
+
+func (r *memoryRepository) CreateDraft(pageID api.PageID, draftTitle string, draftContent string, sourceTaskID *api.TaskID, sourceParagraphIndexes []int) (*api.DraftID, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	page, ok := r.tx.pages.get(pageID)
+	if !ok || page.currentRevisionID == nil {
+		return nil, models.ErrNoRows
+	}
+
+	now := memoryNow()
+	draftID := uuid.New()
+	err := r.tx.drafts.insert(draftID, memoryDraft{
+		draftID:                draftID,
+		pageRevisionID:         *page.currentRevisionID,
+		status:                 string(api.Active),
+		title:                  draftTitle,
+		content:                draftContent,
+		sourceTaskID:           sourceTaskID,
+		sourceParagraphIndexes: slices.Clone(sourceParagraphIndexes),
+		createdAt:              now,
+		updatedAt:              now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &draftID, nil
+}
+
+// draftPage joins draft with its base revision and page.
+func (r *memoryRepository) draftPage(draft memoryDraft) (memoryRevision, memoryPage, bool) {
+	revision, ok := r.tx.revisions.get(draft.pageRevisionID)
+	if !ok {
+		return memoryRevision{}, memoryPage{}, false
+	}
+	page, ok := r.tx.pages.get(revision.pageID)
+	if !ok {
+		return memoryRevision{}, memoryPage{}, false
+	}
+	return revision, page, true
+}
+
+func (r *memoryRepository) GetDraftByID(draftID api.DraftID) (*api.Draft, *internals.DraftAdditionalInfo, error) {
+	draft, ok := r.tx.drafts.get(draftID)
+	if !ok {
+		return nil, nil, models.ErrNoRows
+	}
+	revision, page, ok := r.draftPage(draft)
+	if !ok || page.currentRevisionID == nil {
+		return nil, nil, models.ErrNoRows
+	}
+
+	status := draft.status
+	if status == string(api.Active) && draft.pageRevisionID != *page.currentRevisionID {
+		status = string(api.NeedsRebase)
+	}
+
+	originalContent := revision.content
+	var sourceParagraphIndexes *[]int
+	if draft.sourceParagraphIndexes != nil {
+		indexes := slices.Clone(draft.sourceParagraphIndexes)
+		sourceParagraphIndexes = &indexes
+	}
+	draftResult := &api.Draft{
+		Content:   draft.content,
+		CreatedAt: draft.createdAt,
+		DraftDigest: api.DraftDigest{
+			DraftId:    draft.draftID,
+			DraftTitle: draft.title,
+			PageDigest: api.PageDigest{
+				PageId: page.pageID,
+				Title:  page.title,
+			},
+			Status: api.DraftStatus(status),
+		},
+		OriginalPageContent:    &originalContent,
+		SourceParagraphIndexes: sourceParagraphIndexes,
+		UpdatedAt:              draft.updatedAt,
+	}
+	draftAdditionalInfo := &internals.DraftAdditionalInfo{
+		BaseRevisionId:        draft.pageRevisionID,
+		PageCurrentRevisionId: *page.currentRevisionID,
+		SourceTaskId:          draft.sourceTaskID,
+	}
+
+	return draftResult, draftAdditionalInfo, nil
+}
+
+// ListDrafts ignores cursor as the YQL query does.
+func (r *memoryRepository) ListDrafts(cursor *api.Cursor, limit int64) ([]api.DraftDigest, *api.NextInfo, error) {
+	type draftWithPage struct {
+		draft memoryDraft
+		page  memoryPage
+	}
+
+	rows := make([]draftWithPage, 0)
+	for _, draft := range r.tx.drafts.all() {
+		_, page, ok := r.draftPage(draft)
+		if ok {
+			rows = append(rows, draftWithPage{draft: draft, page: page})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].draft.createdAt.Equal(rows[j].draft.createdAt) {
+			return rows[i].draft.createdAt.After(rows[j].draft.createdAt)
+		}
+		return rows[i].draft.draftID.String() > rows[j].draft.draftID.String()
+	})
+	rows = rows[:min(len(rows), int(limit))]
+
+	drafts := make([]api.DraftDigest, 0, len(rows))
+	newIDFrom := api.DraftID{}
+	newTimeFrom := time.Time{}
+	for _, row := range rows {
+		drafts = append(drafts, api.DraftDigest{
+			DraftId:    row.draft.draftID,
+			DraftTitle: row.draft.title,
+			PageDigest: api.PageDigest{
+				PageId: row.page.pageID,
+				Title:  row.page.title,
+			},
+			Status: api.DraftStatus(row.draft.status),
+		})
+		newIDFrom = row.draft.draftID
+		newTimeFrom = row.draft.createdAt
+	}
+
+	return drafts, encodeDraftsNextInfo(newTimeFrom, newIDFrom, len(rows)), nil
+}
+
+func (r *memoryRepository) RemoveDraft(draftID api.DraftID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	r.tx.drafts.delete(draftID)
+	return nil
+}
+
+// updateDraft applies update to the draft if it exists, like UPDATE does.
+func (r *memoryRepository) updateDraft(draftID api.DraftID, update func(draft *memoryDraft)) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	draft, ok := r.tx.drafts.get(draftID)
+	if !ok {
+		return nil
+	}
+	update(&draft)
+	draft.updatedAt = memoryNow()
+	r.tx.drafts.put(draftID, draft)
+	return nil
+}
+
+func (r *memoryRepository) SetDraftStatus(draftID api.DraftID, newStatus api.DraftStatus) error {
+	return r.updateDraft(draftID, func(draft *memoryDraft) {
+		draft.status = string(newStatus)
+	})
+}
+
+func (r *memoryRepository) SetDraftContent(draftID api.DraftID, newContent string) error {
+	return r.updateDraft(draftID, func(draft *memoryDraft) {
+		draft.content = newContent
+	})
+}
+
+func (r *memoryRepository) SetDraftTitle(draftID api.DraftID, newTitle string) error {
+	return r.updateDraft(draftID, func(draft *memoryDraft) {
+		draft.title = newTitle
+	})
+}
+
+func (r *memoryRepository) SetDraftBaseRevision(draftID api.DraftID, newRevisionID internals.RevisionID) error {
+	return r.updateDraft(draftID, func(draft *memoryDraft) {
+		draft.pageRevisionID = newRevisionID
+	})
+}
+
+func (r *memoryRepository) MarkPageDraftsNeedRebase(pageID api.PageID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	outdatedDraftIDs := make([]api.DraftID, 0)
+	for draftID, draft := range r.tx.drafts.all() {
+		_, page, ok := r.draftPage(draft)
+		if !ok || page.pageID != pageID || draft.status != string(api.Active) {
+			continue
+		}
+		if page.currentRevisionID != nil && draft.pageRevisionID != *page.currentRevisionID {
+			outdatedDraftIDs = append(outdatedDraftIDs, draftID)
+		}
+	}
+
+	for _, draftID := range outdatedDraftIDs {
+		err := r.updateDraft(draftID, func(draft *memoryDraft) {
+			draft.status = string(api.NeedsRebase)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// domain_integration_logs.go
+
+// WriteIntegrationLogField writes outside of the repository transaction, so
+// that logs survive its rollback.
+func (r *memoryRepository) WriteIntegrationLogField(integrationID api.IntegrationID, logText string) error {
+	s := r.tx.storage
+	tx := s.begin(false)
+
+	fieldID := s.nextSequenceValue(integrationLogSequence)
+	tx.integrationLogs.put(fieldID, memoryIntegrationLogField{
+		fieldID:       fieldID,
+		integrationID: string(integrationID),
+		logText:       logText,
+		createdAt:     memoryNow(),
+	})
+	return tx.commit()
+}
+
+func (r *memoryRepository) GetIntegrationLogFields(integrationID api.IntegrationID, cursor *api.Cursor, limit uint64) ([]api.IntegrationLogField, *api.NextInfo, error) {
+	timeFrom, idFrom := decodeIntegrationLogsCursor(cursor)
+
+	rows := make([]memoryIntegrationLogField, 0)
+	for _, field := range r.tx.integrationLogs.all() {
+		if field.integrationID != string(integrationID) {
+			continue
+		}
+		if field.createdAt.Before(timeFrom) || (field.createdAt.Equal(timeFrom) && field.fieldID > idFrom) {
+			rows = append(rows, field)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].createdAt.Equal(rows[j].createdAt) {
+			return rows[i].createdAt.After(rows[j].createdAt)
+		}
+		return rows[i].fieldID < rows[j].fieldID
+	})
+	rows = rows[:min(uint64(len(rows)), limit)]
+
+	fields := make([]api.IntegrationLogField, 0, len(rows))
+	newIDFrom := int64(0)
+	newTimeFrom := time.Now().Add(10000 * time.Hour)
+	for _, row := range rows {
+		fields = append(fields, api.IntegrationLogField{
+			Content:   row.logText,
+			CreatedAt: row.createdAt,
+		})
+		newIDFrom = row.fieldID
+		if newTimeFrom.After(row.createdAt) {
+			newTimeFrom = row.createdAt
+		}
+	}
+
+	return fields, encodeIntegrationLogsNextInfo(newTimeFrom, newIDFrom, len(fields)), nil
+}
+
+// domain_pages.go
+
+func (r *memoryRepository) GetPageBySlug(yWikiSlug string) (*api.Page, error) {
+	rows := make([]*api.Page, 0)
+	for _, page := range r.tx.pages.all() {
+		if page.ywikiSlug != yWikiSlug {
+			continue
+		}
+		revision, ok := r.currentRevision(page)
+		if !ok {
+			continue
+		}
+		rows = append(rows, &api.Page{
+			PageId:    page.pageID,
+			Title:     page.title,
+			Content:   revision.content,
+			YwikiSlug: page.ywikiSlug,
+		})
+	}
+	return exactlyOne(rows)
+}
+
+func (r *memoryRepository) CreatePage(yWikiSlug string, title string, content string) (*api.PageID, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	pageID := uuid.New()
+	err := r.tx.pages.insert(pageID, memoryPage{
+		pageID:    pageID,
+		title:     title,
+		ywikiSlug: yWikiSlug,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log.Debug("Inserted page with id ", pageID)
+
+	_, err = r.AppendPageRevision(pageID, content, api.YwikiSync, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pageID, nil
+}
+
+func (r *memoryRepository) AppendPageRevision(pageID api.PageID, newContent string, source api.RevisionSource, author *string) (*internals.RevisionID, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	page, ok := r.tx.pages.get(pageID)
+	if !ok {
+		return nil, models.ErrNoRows
+	}
+
+	revisionID := r.tx.storage.nextSequenceValue(revisionSequence)
+	err := r.tx.revisions.insert(revisionID, memoryRevision{
+		revisionID:         revisionID,
+		pageID:             pageID,
+		previousRevisionID: page.currentRevisionID,
+		content:            newContent,
+		createdAt:          memoryNow(),
+		source:             string(source),
+		author:             author,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page.currentRevisionID = &revisionID
+	r.tx.pages.put(pageID, page)
+
+	err = r.MarkPageDraftsNeedRebase(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.tx.enqueue(pageRevisionAppendedTopicName, pageID.String())
+	r.log.Info("Enqueued revision appended message for page ", pageID)
+
+	r.log.Debug("Appended page ", pageID, " revision with id ", revisionID)
+
+	return &revisionID, nil
+}
+
+func (r *memoryRepository) DeletePageBySlug(yWikiSlug string) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	for pageID, page := range r.tx.pages.all() {
+		if page.ywikiSlug == yWikiSlug {
+			r.tx.pages.delete(pageID)
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetAllPageDigests() ([]api.PageDigest, error) {
+	pages := make([]api.PageDigest, 0, len(r.tx.pages.all()))
+	for _, page := range r.tx.pages.all() {
+		pages = append(pages, api.PageDigest{
+			PageId: page.pageID,
+			Title:  page.title,
+		})
+	}
+	return pages, nil
+}
+
+func (r *memoryRepository) GetPageTreeNodes() ([]internals.PageTreeNode, error) {
+	nodes := make([]internals.PageTreeNode, 0, len(r.tx.pages.all()))
+	for _, page := range r.tx.pages.all() {
+		nodes = append(nodes, internals.PageTreeNode{
+			PageId:       page.pageID,
+			Title:        page.title,
+			YwikiSlug:    page.ywikiSlug,
+			ParentPageId: page.parentPageID,
+		})
+	}
+	return nodes, nil
+}
+
+func (r *memoryRepository) GetPageByID(pageID api.PageID) (*api.Page, *internals.PageAdditionalInfo, error) {
+	page, ok := r.tx.pages.get(pageID)
+	if !ok {
+		return nil, nil, models.ErrNoRows
+	}
+	revision, ok := r.currentRevision(page)
+	if !ok {
+		return nil, nil, models.ErrNoRows
+	}
+
+	currentRevisionID := revision.revisionID
+	pageResult := &api.Page{
+		PageId:    page.pageID,
+		Title:     page.title,
+		Content:   revision.content,
+		YwikiSlug: page.ywikiSlug,
+	}
+	pageAdditionalInfo := &internals.PageAdditionalInfo{
+		CurrentRevisionId: &currentRevisionID,
+	}
+
+	return pageResult, pageAdditionalInfo, nil
+}
+
+// updatePage applies update to the page if it exists, like UPDATE does.
+func (r *memoryRepository) updatePage(pageID api.PageID, update func(page *memoryPage)) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	page, ok := r.tx.pages.get(pageID)
+	if !ok {
+		return nil
+	}
+	update(&page)
+	r.tx.pages.put(pageID, page)
+	return nil
+}
+
+func (r *memoryRepository) SetPageTitle(pageID api.PageID, newTitle string) error {
+	return r.updatePage(pageID, func(page *memoryPage) {
+		page.title = newTitle
+	})
+}
+
+func (r *memoryRepository) SetPageParentID(pageID api.PageID, parentPageID *api.PageID) error {
+	return r.updatePage(pageID, func(page *memoryPage) {
+		page.parentPageID = parentPageID
+	})
+}
+
+func (r *memoryRepository) UpsertPage(yWikiSlug string, title string, content string) (*api.PageID, error) {
+	page, err := r.GetPageBySlug(yWikiSlug)
+	if errors.Is(err, models.ErrNoRows) {
+		return r.CreatePage(yWikiSlug, title, content)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Content != content {
+		_, err := r.AppendPageRevision(page.PageId, content, api.YwikiSync, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if page.Title != title {
+		err := r.SetPageTitle(page.PageId, title)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &page.PageId, nil
+}
+
+func (r *memoryRepository) GetClosestAncestorPageID(yWikiSlug string) (*api.PageID, error) {
+	ancestorSlugs := make(map[string]struct{})
+	for _, slug := range ywiki_slug.AncestorSlugs(yWikiSlug) {
+		ancestorSlugs[slug] = struct{}{}
+	}
+
+	var closestPageID *api.PageID
+	closestSlugLength := 0
+	for _, page := range r.tx.pages.all() {
+		if _, ok := ancestorSlugs[page.ywikiSlug]; !ok {
+			continue
+		}
+		if len(page.ywikiSlug) > closestSlugLength {
+			pageID := page.pageID
+			closestPageID = &pageID
+			closestSlugLength = len(page.ywikiSlug)
+		}
+	}
+
+	return closestPageID, nil
+}
+
+// DeleteAllPages deletes pages and paragraphs only, as the YQL query does.
+func (r *memoryRepository) DeleteAllPages() error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	for key := range r.tx.paragraphs.all() {
+		r.tx.paragraphs.delete(key)
+	}
+	for pageID := range r.tx.pages.all() {
+		r.tx.pages.delete(pageID)
+	}
+	r.embeddingIndexUpdate.removeAllPages()
+
+	r.log.Info("All pages and paragraphs deleted successfully")
+	return nil
+}
+
+// domain_prompts.go
+
+func (r *memoryRepository) GetLatestPromptTemplateOverride(name string) (*internals.PromptTemplateOverride, error) {
+	overrides, err := r.ListPromptTemplateOverrides(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(overrides) == 0 {
+		return nil, models.ErrNoRows
+	}
+	return &overrides[0], nil
+}
+
+func (r *memoryRepository) ListPromptTemplateOverrides(name string) ([]internals.PromptTemplateOverride, error) {
+	overrides := make([]internals.PromptTemplateOverride, 0)
+	for key, override := range r.tx.promptOverrides.all() {
+		if key.name == name {
+			overrides = append(overrides, override)
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Version > overrides[j].Version
+	})
+	return overrides, nil
+}
+
+func (r *memoryRepository) CreatePromptTemplateOverride(override internals.PromptTemplateOverride) (*internals.PromptTemplateOverride, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	override.CreatedAt = memoryNow()
+	key := memoryPromptTemplateOverrideKey{name: override.Name, version: override.Version}
+	if err := r.tx.promptOverrides.insert(key, override); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// domain_revisions.go
+
+func (r *memoryRepository) ListPageRevisions(pageID api.PageID, cursor *api.Cursor, limit int64) ([]api.RevisionDigest, *api.NextInfo, error) {
+	idBefore := decodeRevisionsCursor(cursor)
+
+	rows := make([]memoryRevision, 0)
+	for _, revision := range r.tx.revisions.all() {
+		if revision.pageID == pageID && revision.revisionID < idBefore {
+			rows = append(rows, revision)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].revisionID > rows[j].revisionID
+	})
+
+	hasMore := len(rows) > int(limit)
+	rows = rows[:min(len(rows), int(limit))]
+
+	revisions := make([]api.RevisionDigest, 0, len(rows))
+	for _, row := range rows {
+		revisions = append(revisions, makeRevisionDigest(row.revisionID, row.pageID, row.previousRevisionID, row.createdAt, row.source, row.author))
+		idBefore = row.revisionID
+	}
+
+	return revisions, encodeRevisionsNextInfo(idBefore, hasMore), nil
+}
+
+func (r *memoryRepository) GetRevisionByID(revisionID api.RevisionID) (*api.Revision, error) {
+	revision, ok := r.tx.revisions.get(revisionID)
+	if !ok {
+		return nil, models.ErrNoRows
+	}
+
+	return &api.Revision{
+		RevisionDigest: makeRevisionDigest(revision.revisionID, revision.pageID, revision.previousRevisionID, revision.createdAt, revision.source, revision.author),
+		Content:        revision.content,
+	}, nil
+}
+
+// domain_tasks.go
+
+// makeMemoryTaskDigest fills digest with the same placeholders as domain_tasks.go.
+func makeMemoryTaskDigest(task memoryTask) (api.TaskDigest, internals.TaskState, error) {
+	var taskState internals.TaskState
+	if err := json.Unmarshal(task.state, &taskState); err != nil {
+		return api.TaskDigest{}, internals.TaskState{}, err
+	}
+
+	return api.TaskDigest{
+		TaskId:      task.taskID,
+		Status:      api.TaskStatus(task.status),
+		TriggeredBy: "system",           // Placeholder
+		Description: "Task description", // Placeholder
+	}, taskState, nil
+}
+
+func (r *memoryRepository) GetTaskByID(taskID api.TaskID) (*api.TaskDigest, *internals.TaskState, error) {
+	task, ok := r.tx.tasks.get(taskID)
+	if !ok {
+		return nil, nil, models.ErrNoRows
+	}
+
+	taskDigest, taskState, err := makeMemoryTaskDigest(task)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &taskDigest, &taskState, nil
+}
+
+func (r *memoryRepository) ListTasks(cursor *api.Cursor, limit int64) ([]api.TaskDigest, []internals.TaskState, *api.NextInfo, error) {
+	idUpperLimit := decodeTasksCursor(cursor)
+
+	rows := make([]memoryTask, 0)
+	for _, task := range r.tx.tasks.all() {
+		if task.taskID < idUpperLimit {
+			rows = append(rows, task)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].taskID > rows[j].taskID
+	})
+	rows = rows[:min(len(rows), int(limit))]
+
+	taskDigests := make([]api.TaskDigest, 0, len(rows))
+	taskStates := make([]internals.TaskState, 0, len(rows))
+	newIDFrom := int64(0)
+	for _, row := range rows {
+		taskDigest, taskState, err := makeMemoryTaskDigest(row)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		taskDigests = append(taskDigests, taskDigest)
+		taskStates = append(taskStates, taskState)
+		newIDFrom = row.taskID
+	}
+
+	return taskDigests, taskStates, encodeTasksNextInfo(newIDFrom, len(taskDigests)), nil
+}
+
+func (r *memoryRepository) CreateTask(taskState internals.TaskState) (*api.TaskID, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	stateBytes, err := json.Marshal(taskState)
+	if err != nil {
+		return nil, err
+	}
+
+	now := memoryNow()
+	taskID := r.tx.storage.nextSequenceValue(taskSequence)
+	err = r.tx.tasks.insert(taskID, memoryTask{
+		taskID:    taskID,
+		status:    string(api.Executing),
+		state:     stateBytes,
+		createdAt: now,
+		updatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &taskID, nil
+}
+
+// updateTask applies update to the task if it exists, like UPDATE does.
+func (r *memoryRepository) updateTask(taskID api.TaskID, update func(task *memoryTask)) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	task, ok := r.tx.tasks.get(taskID)
+	if !ok {
+		return nil
+	}
+	update(&task)
+	task.updatedAt = memoryNow()
+	r.tx.tasks.put(taskID, task)
+	return nil
+}
+
+func (r *memoryRepository) SetTaskStatus(taskID api.TaskID, newStatus api.TaskStatus) error {
+	return r.updateTask(taskID, func(task *memoryTask) {
+		task.status = string(newStatus)
+	})
+}
+
+func (r *memoryRepository) SetTaskState(taskID api.TaskID, newState internals.TaskState) error {
+	stateBytes, err := json.Marshal(newState)
+	if err != nil {
+		return err
+	}
+	return r.updateTask(taskID, func(task *memoryTask) {
+		task.state = stateBytes
+	})
+}
+
+func (r *memoryRepository) GetStaleTaskIDs() ([]api.TaskID, error) {
+	now := memoryNow()
+	taskIDs := make([]api.TaskID, 0)
+	for _, task := range r.tx.tasks.all() {
+		if task.status == string(api.Executing) && int64(now.Sub(task.updatedAt)/time.Minute) > 60 {
+			taskIDs = append(taskIDs, task.taskID)
+		}
+	}
+	return taskIDs, nil
+}
+
+// domain_task_actions.go
+
+func (r *memoryRepository) CreateTaskAction(taskID api.TaskID, actionState internals.TaskAction) (*internals.TaskActionID, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	actionBytes, err := json.Marshal(actionState)
+	if err != nil {
+		return nil, err
+	}
+
+	now := memoryNow()
+	taskActionID := r.tx.storage.nextSequenceValue(taskActionSequence)
+	err = r.tx.taskActions.insert(taskActionID, memoryTaskAction{
+		taskActionID: taskActionID,
+		taskID:       taskID,
+		status:       string(internals.New),
+		action:       actionBytes,
+		createdAt:    now,
+		updatedAt:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.log.Debug("Inserted task action with id ", taskActionID)
+
+	return &taskActionID, nil
+}
+
+func (r *memoryRepository) GetTaskActionByID(actionID internals.TaskActionID) (*internals.TaskAction, *internals.TaskActionAdditionalInfo, error) {
+	taskAction, ok := r.tx.taskActions.get(actionID)
+	if !ok {
+		return nil, nil, models.ErrNoRows
+	}
+
+	var action internals.TaskAction
+	if err := json.Unmarshal(taskAction.action, &action); err != nil {
+		return nil, nil, err
+	}
+
+	return &action, &internals.TaskActionAdditionalInfo{
+		CreatedAt: taskAction.createdAt,
+		Status:    internals.TaskActionStatus(taskAction.status),
+		TaskId:    taskAction.taskID,
+		UpdatedAt: taskAction.updatedAt,
+	}, nil
+}
+
+func (r *memoryRepository) GetTaskActionsByTaskID(taskID api.TaskID) ([]api.TaskActionWithResult, error) {
+	rows := make([]memoryTaskAction, 0)
+	for _, taskAction := range r.tx.taskActions.all() {
+		if taskAction.taskID == taskID {
+			rows = append(rows, taskAction)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].taskActionID < rows[j].taskActionID
+	})
+
+	taskActions := make([]api.TaskActionWithResult, 0, len(rows))
+	for _, row := range rows {
+		taskActionRaw := map[string]any{}
+		if err := json.Unmarshal(row.action, &taskActionRaw); err != nil {
+			return nil, err
+		}
+
+		var taskActionResultRaw *api.RawJSON
+		if taskActionResult, ok := r.tx.taskActionResults.get(row.taskActionID); ok {
+			taskActionResultRaw = &api.RawJSON{}
+			if err := json.Unmarshal(taskActionResult.result, taskActionResultRaw); err != nil {
+				return nil, err
+			}
+		}
+
+		updatedAt := row.updatedAt
+		taskActions = append(taskActions, api.TaskActionWithResult{
+			TaskActionId:     row.taskActionID,
+			CreatedAt:        row.createdAt,
+			UpdatedAt:        &updatedAt,
+			TaskAction:       taskActionRaw,
+			TaskActionResult: taskActionResultRaw,
+		})
+	}
+
+	return taskActions, nil
+}
+
+func (r *memoryRepository) EnqueueTaskAction(actionID internals.TaskActionID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	r.tx.enqueue(taskActionsTopicName, fmt.Sprintf("%d", actionID))
+	r.log.Info("Enqueued message for actionID ", actionID)
+	return nil
+}
+
+func (r *memoryRepository) SetTaskActionStatus(actionID internals.TaskActionID, newStatus internals.TaskActionStatus) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	taskAction, ok := r.tx.taskActions.get(actionID)
+	if ok {
+		taskAction.status = string(newStatus)
+		taskAction.updatedAt = memoryNow()
+		r.tx.taskActions.put(actionID, taskAction)
+	}
+
+	r.log.Debug("Updated task action status for action id ", actionID)
+
+	return nil
+}
+
+func (r *memoryRepository) CreateTaskActionResult(actionID internals.TaskActionID, result internals.TaskActionResult) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	resultBytes, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	err = r.tx.taskActionResults.insert(actionID, memoryTaskActionResult{
+		taskActionID: actionID,
+		result:       resultBytes,
+		createdAt:    memoryNow(),
+	})
+	if err != nil {
+		return err
+	}
+
+	r.log.Debug("Inserted task action result for action id ", actionID)
+
+	return nil
+}
+
+// GetTaskActionResultByID fills TaskId with action ID as domain_task_actions.go
+// does.
+func (r *memoryRepository) GetTaskActionResultByID(actionID internals.TaskActionID) (*internals.TaskActionResult, *internals.TaskActionResultAdditionalInfo, error) {
+	taskActionResult, ok := r.tx.taskActionResults.get(actionID)
+	if !ok {
+		return nil, nil, models.ErrNoRows
+	}
+
+	var result internals.TaskActionResult
+	if err := json.Unmarshal(taskActionResult.result, &result); err != nil {
+		return nil, nil, err
+	}
+
+	return &result, &internals.TaskActionResultAdditionalInfo{
+		CreatedAt: taskActionResult.createdAt,
+		TaskId:    api.TaskID(taskActionResult.taskActionID),
+	}, nil
+}
+
+func (r *memoryRepository) EnqueueTaskActionResult(actionID internals.TaskActionID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	r.tx.enqueue(taskActionResultsTopicName, fmt.Sprintf("%d", actionID))
+	r.log.Info("Enqueued result ready message for actionID ", actionID)
+	return nil
+}
+
+func (r *memoryRepository) GetTaskActionIDsByStatus(taskID api.TaskID, statuses []internals.TaskActionStatus) ([]internals.TaskActionID, error) {
+	actionIDs := make([]internals.TaskActionID, 0)
+	for _, taskAction := range r.tx.taskActions.all() {
+		if taskAction.taskID != taskID {
+			continue
+		}
+		for _, status := range statuses {
+			if taskAction.status == string(status) {
+				actionIDs = append(actionIDs, taskAction.taskActionID)
+				break
+			}
+		}
+	}
+	sort.Slice(actionIDs, func(i, j int) bool {
+		return actionIDs[i] < actionIDs[j]
+	})
+	return actionIDs, nil
+}
+
+func (r *memoryRepository) AbandonPendingTaskActions(taskID api.TaskID) error {
+	pendingActionIDs, err := r.GetTaskActionIDsByStatus(taskID, []internals.TaskActionStatus{
+		internals.New,
+		internals.Executing,
+		internals.Submitted,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, actionID := range pendingActionIDs {
+		err := r.SetTaskActionStatus(actionID, internals.Abandoned)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.log.Debug("Abandoned pending task actions for task id ", taskID)
+
+	return nil
+}
+
+func (r *memoryRepository) SetPendingLLMOperation(actionID internals.TaskActionID, operationID string) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	r.tx.llmOperations.put(actionID, memoryPendingLLMOperation{
+		operationID: operationID,
+		submittedAt: memoryNow(),
+	})
+
+	r.log.Debug("Set pending LLM operation for action id ", actionID)
+
+	return nil
+}
+
+func (r *memoryRepository) GetPendingLLMOperations() ([]internals.PendingLLMOperation, error) {
+	operations := make([]internals.PendingLLMOperation, 0)
+	for actionID, operation := range r.tx.llmOperations.all() {
+		operations = append(operations, internals.PendingLLMOperation{
+			TaskActionId: actionID,
+			OperationId:  operation.operationID,
+			SubmittedAt:  operation.submittedAt,
+		})
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		return operations[i].TaskActionId < operations[j].TaskActionId
+	})
+	return operations, nil
+}
+
+func (r *memoryRepository) RemovePendingLLMOperation(actionID internals.TaskActionID, operationID string) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	operation, ok := r.tx.llmOperations.get(actionID)
+	if !ok || operation.operationID != operationID {
+		return models.ErrNoRows
+	}
+	r.tx.llmOperations.delete(actionID)
+
+	r.log.Debug("Removed pending LLM operation for action id ", actionID)
+
+	return nil
+}
+
+// domain_users.go
+
+func (r *memoryRepository) GetUserByLogin(username string) (*models.User, error) {
+	user, ok := r.tx.users.get(username)
+	if !ok {
+		return nil, models.ErrNoRows
+	}
+	return &user, nil
+}
+
+// splitHeaders mirrors reading of Paragraph.headers, which are stored joined.
+func splitHeaders(headers []string) []string {
+	return strings.Split(strings.Join(headers, "\n"), "\n")
+}
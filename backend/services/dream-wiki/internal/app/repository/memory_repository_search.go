@@ -0,0 +1,501 @@
+package repository
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// domain_page_indexation.go
+
+func (r *memoryRepository) RemovePageIndexation(pageID api.PageID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	for key := range r.tx.paragraphs.all() {
+		if key.pageID == pageID {
+			r.tx.paragraphs.delete(key)
+		}
+	}
+	for key := range r.tx.terms.all() {
+		if key.pageID == pageID {
+			r.tx.terms.delete(key)
+		}
+	}
+	r.tx.pageTermStats.delete(pageID)
+	for key := range r.tx.termPageStats.all() {
+		if key.pageID == pageID {
+			r.tx.termPageStats.delete(key)
+		}
+	}
+	r.embeddingIndexUpdate.removePage(pageID)
+	return nil
+}
+
+// pageParagraphs returns paragraphs of the page ordered by index.
+func (r *memoryRepository) pageParagraphs(pageID api.PageID) []internals.ParagraphWithEmbedding {
+	paragraphs := make([]internals.ParagraphWithEmbedding, 0)
+	for key, paragraph := range r.tx.paragraphs.all() {
+		if key.pageID == pageID {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	sort.Slice(paragraphs, func(i, j int) bool {
+		return paragraphs[i].ParagraphIndex < paragraphs[j].ParagraphIndex
+	})
+	return paragraphs
+}
+
+func (r *memoryRepository) GetPageIndexedParagraphs(pageID api.PageID) ([]internals.IndexedParagraphDigest, error) {
+	paragraphs := make([]internals.IndexedParagraphDigest, 0)
+	for _, paragraph := range r.pageParagraphs(pageID) {
+		paragraphs = append(paragraphs, internals.IndexedParagraphDigest{
+			ParagraphIndex: paragraph.ParagraphIndex,
+			ContentHash:    paragraph.ContentHash,
+			Embedding:      paragraph.Embedding,
+		})
+	}
+	return paragraphs, nil
+}
+
+func (r *memoryRepository) GetPageTerms(pageID api.PageID) ([]internals.Term, error) {
+	terms := make([]internals.Term, 0)
+	for key, timesIn := range r.tx.terms.all() {
+		if key.pageID == pageID {
+			terms = append(terms, internals.Term{
+				Term:           key.term,
+				PageId:         key.pageID,
+				ParagraphIndex: key.paragraphIndex,
+				TimesIn:        timesIn,
+			})
+		}
+	}
+	return terms, nil
+}
+
+func (r *memoryRepository) AddIndexedParagraph(paragraph internals.ParagraphWithEmbedding) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	if len(paragraph.Embedding) == 0 {
+		return fmt.Errorf("embedding is empty for paragraph with page_id: %s, line_number: %d", paragraph.PageId, paragraph.LineNumber)
+	}
+
+	anchorLinkSlug := ""
+	if paragraph.AnchorSlug != nil {
+		anchorLinkSlug = *paragraph.AnchorSlug
+	}
+	paragraph.AnchorSlug = &anchorLinkSlug
+	paragraph.Headers = splitHeaders(paragraph.Headers)
+	paragraph.Embedding = slices.Clone(paragraph.Embedding)
+
+	err := r.tx.paragraphs.insert(memoryParagraphKey{pageID: paragraph.PageId, paragraphIndex: paragraph.ParagraphIndex}, paragraph)
+	if err != nil {
+		return err
+	}
+	r.embeddingIndexUpdate.addParagraph(paragraph)
+	return nil
+}
+
+func (r *memoryRepository) AddTerm(term string, pageID api.PageID, paragraphIndex int64, timesIn int64) error {
+	terms := []internals.Term{
+		{
+			Term:           term,
+			PageId:         pageID,
+			ParagraphIndex: paragraphIndex,
+			TimesIn:        timesIn,
+		},
+	}
+	return r.AddTerms(terms)
+}
+
+func (r *memoryRepository) AddTerms(terms []internals.Term) error {
+	if len(terms) == 0 {
+		return nil
+	}
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	for _, term := range terms {
+		key := memoryTermKey{term: term.Term, pageID: term.PageId, paragraphIndex: term.ParagraphIndex}
+		if err := r.tx.terms.insert(key, term.TimesIn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) SetPageTermStats(stats internals.PageTermStats) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	r.tx.pageTermStats.put(stats.PageId, memoryPageTermStats{
+		paragraphsCount: stats.ParagraphsCount,
+		termsCount:      stats.TermsCount,
+	})
+	for term, paragraphsCount := range stats.TermParagraphsCounts {
+		r.tx.termPageStats.put(memoryTermPageKey{term: term, pageID: stats.PageId}, paragraphsCount)
+	}
+	return nil
+}
+
+func (r *memoryRepository) GetParagraphEmbeddings() ([]internals.ParagraphEmbedding, error) {
+	embeddings := make([]internals.ParagraphEmbedding, 0)
+	for key, paragraph := range r.tx.paragraphs.all() {
+		embeddings = append(embeddings, internals.ParagraphEmbedding{
+			PageId:         key.pageID,
+			ParagraphIndex: key.paragraphIndex,
+			Embedding:      paragraph.Embedding,
+		})
+	}
+	return embeddings, nil
+}
+
+func (r *memoryRepository) AddPendingReindexationPage(pageID api.PageID, writtenAt time.Time) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	pending, ok := r.tx.reindexations.get(pageID)
+	if !ok {
+		pending.firstWrittenAt = writtenAt
+	}
+	pending.lastWrittenAt = writtenAt
+	r.tx.reindexations.put(pageID, pending)
+
+	r.log.Debug("Added pending reindexation of page ", pageID)
+
+	return nil
+}
+
+func (r *memoryRepository) GetDuePendingReindexationPages(lastWrittenBefore time.Time, firstWrittenBefore time.Time) ([]api.PageID, error) {
+	type duePage struct {
+		pageID         api.PageID
+		firstWrittenAt time.Time
+	}
+	due := make([]duePage, 0)
+	for pageID, pending := range r.tx.reindexations.all() {
+		if !pending.lastWrittenAt.After(lastWrittenBefore) || !pending.firstWrittenAt.After(firstWrittenBefore) {
+			due = append(due, duePage{pageID: pageID, firstWrittenAt: pending.firstWrittenAt})
+		}
+	}
+	slices.SortFunc(due, func(a, b duePage) int {
+		return cmp.Or(a.firstWrittenAt.Compare(b.firstWrittenAt), strings.Compare(a.pageID.String(), b.pageID.String()))
+	})
+
+	pageIDs := make([]api.PageID, 0, len(due))
+	for _, page := range due {
+		pageIDs = append(pageIDs, page.pageID)
+	}
+	return pageIDs, nil
+}
+
+func (r *memoryRepository) RemovePendingReindexationPages(pageIDs []api.PageID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	for _, pageID := range pageIDs {
+		r.tx.reindexations.delete(pageID)
+	}
+
+	r.log.Debug("Removed pending reindexation of ", len(pageIDs), " pages")
+
+	return nil
+}
+
+// domain_search.go
+
+// matchesSearchFilters mirrors searchFiltersCondition.
+func matchesSearchFilters(filters internals.SearchFilters, paragraph internals.ParagraphWithEmbedding) bool {
+	if filters.PageIds != nil {
+		found := false
+		for _, pageID := range *filters.PageIds {
+			if pageID == paragraph.PageId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !filters.IncludeHeaders && paragraph.IsHeader {
+		return false
+	}
+
+	if filters.HeaderPath != nil {
+		headerPath := strings.Join(*filters.HeaderPath, "\n")
+		headers := strings.Join(paragraph.Headers, "\n")
+		if headerPath != "" && headers != headerPath && !strings.HasPrefix(headers, headerPath+"\n") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func makeMemorySearchResultItem(paragraph internals.ParagraphWithEmbedding, page memoryPage) internals.SearchResultItem {
+	anchorLinkSlug := *paragraph.AnchorSlug
+	return internals.SearchResultItem{
+		PageId:           paragraph.PageId,
+		PageSlug:         page.ywikiSlug,
+		PageTitle:        page.title,
+		ParagraphContent: paragraph.Content,
+		AnchorSlug:       &anchorLinkSlug,
+		Headers:          paragraph.Headers,
+		ParagraphIndex:   paragraph.ParagraphIndex,
+		LineIndex:        paragraph.LineNumber,
+	}
+}
+
+func (r *memoryRepository) SearchByEmbedding(query string, queryEmbedding internals.Embedding, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error) {
+	return searchWithVectorIndex(r.embeddingIndex, queryEmbedding, filters, limit,
+		func(candidates []vectorindex.Key) ([]internals.SearchResultItem, error) {
+			paragraphs := make([]internals.ParagraphWithEmbedding, 0, len(candidates))
+			for _, candidate := range candidates {
+				paragraph, ok := r.tx.paragraphs.get(memoryParagraphKey{pageID: candidate.PageID, paragraphIndex: candidate.ParagraphIndex})
+				if ok {
+					paragraphs = append(paragraphs, paragraph)
+				}
+			}
+			return r.rankParagraphsByEmbedding(paragraphs, queryEmbedding, filters, limit), nil
+		},
+		func() ([]internals.SearchResultItem, error) {
+			paragraphs := make([]internals.ParagraphWithEmbedding, 0)
+			for _, paragraph := range r.tx.paragraphs.all() {
+				paragraphs = append(paragraphs, paragraph)
+			}
+			return r.rankParagraphsByEmbedding(paragraphs, queryEmbedding, filters, limit), nil
+		},
+	)
+}
+
+func (r *memoryRepository) rankParagraphsByEmbedding(paragraphs []internals.ParagraphWithEmbedding, queryEmbedding internals.Embedding, filters internals.SearchFilters, limit int) []internals.SearchResultItem {
+	searchResult := make([]internals.SearchResultItem, 0)
+	for _, paragraph := range paragraphs {
+		page, ok := r.tx.pages.get(paragraph.PageId)
+		if !ok || !matchesSearchFilters(filters, paragraph) {
+			continue
+		}
+		item := makeMemorySearchResultItem(paragraph, page)
+		item.Score = float64(vectorindex.CosineDistance(paragraph.Embedding, queryEmbedding))
+		searchResult = append(searchResult, item)
+	}
+
+	sort.Slice(searchResult, func(i, j int) bool {
+		if searchResult[i].Score != searchResult[j].Score {
+			return searchResult[i].Score < searchResult[j].Score
+		}
+		if searchResult[i].PageId != searchResult[j].PageId {
+			return searchResult[i].PageId.String() < searchResult[j].PageId.String()
+		}
+		return searchResult[i].ParagraphIndex < searchResult[j].ParagraphIndex
+	})
+
+	return searchResult[:min(len(searchResult), limit)]
+}
+
+func (r *memoryRepository) SearchByEmbeddingWithContext(query string, queryEmbedding internals.Embedding, contextSize int) ([]internals.ParagraphWithContext, error) {
+	initialResults, err := r.SearchByEmbedding(query, queryEmbedding, internals.SearchFilters{}, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	var allParagraphs []internals.ParagraphWithContext
+	allRetrievedParagraphs := make([]internals.ParagraphWithContext, 0)
+
+	for _, result := range initialResults {
+		for _, paragraph := range r.pageParagraphs(result.PageId) {
+			if paragraph.IsHeader || paragraph.ParagraphIndex < result.ParagraphIndex-contextSize || paragraph.ParagraphIndex > result.ParagraphIndex+contextSize {
+				continue
+			}
+			allRetrievedParagraphs = append(allRetrievedParagraphs, internals.ParagraphWithContext{
+				PageId:          paragraph.PageId,
+				ParagraphIndex:  paragraph.ParagraphIndex,
+				StartLineNumber: paragraph.LineNumber,
+				EndLineNumber:   paragraph.EndLineNumber,
+				Content:         paragraph.Content,
+			})
+		}
+	}
+
+	pageParagraphs := groupParagraphsByPages(allRetrievedParagraphs)
+
+	for _, paragraphs := range pageParagraphs {
+		merged := mergeOverlappingParagraphs(paragraphs)
+		allParagraphs = append(allParagraphs, merged...)
+	}
+
+	return allParagraphs, nil
+}
+
+func (r *memoryRepository) GetParagraphWithContext(pageID api.PageID, paragraphIndex int, contextSize int) (*internals.ParagraphWithContext, error) {
+	var paragraph *internals.ParagraphWithContext
+	for _, neighbour := range r.pageParagraphs(pageID) {
+		if neighbour.ParagraphIndex < paragraphIndex-contextSize || neighbour.ParagraphIndex > paragraphIndex+contextSize {
+			continue
+		}
+
+		if paragraph == nil {
+			paragraph = &internals.ParagraphWithContext{
+				PageId:          neighbour.PageId,
+				ParagraphIndex:  neighbour.ParagraphIndex,
+				StartLineNumber: neighbour.LineNumber,
+				EndLineNumber:   neighbour.EndLineNumber,
+				Content:         neighbour.Content,
+			}
+			continue
+		}
+		paragraph.Content += "\n\n" + neighbour.Content
+		paragraph.EndLineNumber = neighbour.EndLineNumber
+	}
+
+	if paragraph == nil {
+		return nil, models.ErrNoRows
+	}
+	return paragraph, nil
+}
+
+func (r *memoryRepository) GetTermVocabulary(prefix string) ([]string, error) {
+	terms := make(map[string]struct{})
+	for key := range r.tx.termPageStats.all() {
+		if strings.HasPrefix(key.term, prefix) {
+			terms[key.term] = struct{}{}
+		}
+	}
+
+	vocabulary := make([]string, 0, len(terms))
+	for term := range terms {
+		vocabulary = append(vocabulary, term)
+	}
+	return vocabulary, nil
+}
+
+func (r *memoryRepository) SearchByTerms(terms []string, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error) {
+	if len(terms) == 0 {
+		return []internals.SearchResultItem{}, nil
+	}
+
+	searchedTerms := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		searchedTerms[term] = struct{}{}
+	}
+
+	stats := termCorpusStats{termDocFreq: make(map[string]int64)}
+	for _, pageStats := range r.tx.pageTermStats.all() {
+		stats.paragraphsCount += pageStats.paragraphsCount
+		stats.termsCount += pageStats.termsCount
+	}
+	for key, paragraphsCount := range r.tx.termPageStats.all() {
+		if _, ok := searchedTerms[key.term]; ok {
+			stats.termDocFreq[key.term] += paragraphsCount
+		}
+	}
+
+	paragraphs := make(map[string]*termMatchedParagraph)
+	for key, timesIn := range r.tx.terms.all() {
+		if _, ok := searchedTerms[key.term]; !ok {
+			continue
+		}
+
+		paragraph, ok := r.tx.paragraphs.get(memoryParagraphKey{pageID: key.pageID, paragraphIndex: int(key.paragraphIndex)})
+		if !ok || !matchesSearchFilters(filters, paragraph) {
+			continue
+		}
+		page, ok := r.tx.pages.get(key.pageID)
+		if !ok {
+			continue
+		}
+
+		paragraphKey := fmt.Sprintf("%s_%d", key.pageID.String(), key.paragraphIndex)
+		if _, exists := paragraphs[paragraphKey]; !exists {
+			paragraphs[paragraphKey] = &termMatchedParagraph{
+				data:            makeMemorySearchResultItem(paragraph, page),
+				termFrequencies: make(map[string]int64),
+				length:          paragraph.TermsCount,
+			}
+		}
+		paragraphs[paragraphKey].termFrequencies[key.term] = timesIn
+	}
+
+	return rankTermMatchedParagraphs(paragraphs, stats, limit), nil
+}
+
+// domain_synonyms.go
+
+func (r *memoryRepository) ListSynonymGroups() ([]api.SynonymGroup, error) {
+	rows := make([]memorySynonymGroup, 0)
+	for _, group := range r.tx.synonymGroups.all() {
+		rows = append(rows, group)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if !rows[i].createdAt.Equal(rows[j].createdAt) {
+			return rows[i].createdAt.Before(rows[j].createdAt)
+		}
+		return rows[i].synonymGroupID.String() < rows[j].synonymGroupID.String()
+	})
+
+	groups := make([]api.SynonymGroup, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, api.SynonymGroup{
+			SynonymGroupId: row.synonymGroupID,
+			Phrases:        slices.Clone(row.phrases),
+		})
+	}
+	return groups, nil
+}
+
+func (r *memoryRepository) CreateSynonymGroup(phrases []string) (*api.SynonymGroupID, error) {
+	if err := r.tx.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	now := memoryNow()
+	synonymGroupID := uuid.New()
+	err := r.tx.synonymGroups.insert(synonymGroupID, memorySynonymGroup{
+		synonymGroupID: synonymGroupID,
+		phrases:        slices.Clone(phrases),
+		createdAt:      now,
+		updatedAt:      now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &synonymGroupID, nil
+}
+
+func (r *memoryRepository) SetSynonymGroupPhrases(synonymGroupID api.SynonymGroupID, phrases []string) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+
+	group, ok := r.tx.synonymGroups.get(synonymGroupID)
+	if !ok {
+		return models.ErrNoRows
+	}
+	group.phrases = slices.Clone(phrases)
+	group.updatedAt = memoryNow()
+	r.tx.synonymGroups.put(synonymGroupID, group)
+	return nil
+}
+
+func (r *memoryRepository) RemoveSynonymGroup(synonymGroupID api.SynonymGroupID) error {
+	if err := r.tx.checkWritable(); err != nil {
+		return err
+	}
+	r.tx.synonymGroups.delete(synonymGroupID)
+	return nil
+}
@@ -0,0 +1,544 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+var (
+	// errTransactionLocksInvalidated is returned on commit of memory
+	// transaction, which wrote rows changed by another transaction since it
+	// started, like YDB does.
+	errTransactionLocksInvalidated = errors.New("transaction locks invalidated")
+	errWriteInReadOnlyTransaction  = errors.New("write in read only transaction")
+	errDuplicateKey                = errors.New("conflict with existing key")
+)
+
+type (
+	// memoryTable is an immutable committed state of a table. Commit replaces
+	// tables it changes, so that transactions can read their snapshots without
+	// locks.
+	memoryTable[K comparable, V any] struct {
+		rows map[K]V
+		// versions keeps commit version of the last write of every key,
+		// including deleted ones.
+		versions map[K]uint64
+	}
+
+	// memoryTxTable is a table as seen by transaction: its snapshot and own
+	// writes on top of it.
+	memoryTxTable[K comparable, V any] struct {
+		slot     **memoryTable[K, V]
+		snapshot *memoryTable[K, V]
+		// rows is a copy of snapshot rows made on the first write.
+		rows    map[K]V
+		written map[K]struct{}
+	}
+
+	memoryTxTableCommitter interface {
+		isWritten() bool
+		hasConflicts(startVersion uint64) bool
+		commit(version uint64)
+	}
+
+	memoryPage struct {
+		pageID            api.PageID
+		title             string
+		ywikiSlug         string
+		parentPageID      *api.PageID
+		currentRevisionID *int64
+	}
+
+	memoryRevision struct {
+		revisionID         int64
+		pageID             api.PageID
+		previousRevisionID *int64
+		content            string
+		createdAt          time.Time
+		source             string
+		author             *string
+	}
+
+	memoryParagraphKey struct {
+		pageID         api.PageID
+		paragraphIndex int
+	}
+
+	memoryTermKey struct {
+		term           string
+		pageID         api.PageID
+		paragraphIndex int64
+	}
+
+	memoryPageTermStats struct {
+		paragraphsCount int64
+		termsCount      int64
+	}
+
+	memoryTermPageKey struct {
+		term   string
+		pageID api.PageID
+	}
+
+	memoryIntegrationLogField struct {
+		fieldID       int64
+		integrationID string
+		logText       string
+		createdAt     time.Time
+	}
+
+	memoryTask struct {
+		taskID    api.TaskID
+		status    string
+		state     []byte
+		createdAt time.Time
+		updatedAt time.Time
+	}
+
+	memoryDraft struct {
+		draftID        api.DraftID
+		pageRevisionID int64
+		status         string
+		title          string
+		content        string
+		sourceTaskID   *int64
+		// sourceParagraphIndexes is nil for drafts without the value.
+		sourceParagraphIndexes []int
+		createdAt              time.Time
+		updatedAt              time.Time
+	}
+
+	memorySynonymGroup struct {
+		synonymGroupID api.SynonymGroupID
+		phrases        []string
+		createdAt      time.Time
+		updatedAt      time.Time
+	}
+
+	memoryTaskAction struct {
+		taskActionID internals.TaskActionID
+		taskID       api.TaskID
+		status       string
+		action       []byte
+		createdAt    time.Time
+		updatedAt    time.Time
+	}
+
+	memoryPendingLLMOperation struct {
+		operationID string
+		submittedAt time.Time
+	}
+
+	memoryPendingReindexation struct {
+		firstWrittenAt time.Time
+		lastWrittenAt  time.Time
+	}
+
+	memoryPromptTemplateOverrideKey struct {
+		name    string
+		version int
+	}
+
+	memoryTaskActionResult struct {
+		taskActionID internals.TaskActionID
+		result       []byte
+		createdAt    time.Time
+	}
+
+	// memoryTables mirrors tables of backend/ydb/schema.yql.
+	memoryTables struct {
+		pages           *memoryTable[api.PageID, memoryPage]
+		revisions       *memoryTable[int64, memoryRevision]
+		paragraphs      *memoryTable[memoryParagraphKey, internals.ParagraphWithEmbedding]
+		terms           *memoryTable[memoryTermKey, int64]
+		pageTermStats   *memoryTable[api.PageID, memoryPageTermStats]
+		termPageStats   *memoryTable[memoryTermPageKey, int64]
+		synonymGroups   *memoryTable[api.SynonymGroupID, memorySynonymGroup]
+		integrationLogs *memoryTable[int64, memoryIntegrationLogField]
+		// users are keyed by login, which is how they are looked up.
+		users             *memoryTable[string, models.User]
+		tasks             *memoryTable[api.TaskID, memoryTask]
+		drafts            *memoryTable[api.DraftID, memoryDraft]
+		taskActions       *memoryTable[internals.TaskActionID, memoryTaskAction]
+		taskActionResults *memoryTable[internals.TaskActionID, memoryTaskActionResult]
+		llmOperations     *memoryTable[internals.TaskActionID, memoryPendingLLMOperation]
+		promptOverrides   *memoryTable[memoryPromptTemplateOverrideKey, internals.PromptTemplateOverride]
+		reindexations     *memoryTable[api.PageID, memoryPendingReindexation]
+	}
+
+	// memoryTopic is a queue read by a single consumer, as YDB topics are read
+	// by dream_wiki consumer.
+	memoryTopic struct {
+		mu       sync.Mutex
+		messages [][]byte
+		// notify has a value when messages may be non-empty.
+		notify chan struct{}
+	}
+
+	memoryStorage struct {
+		log            logger.Logger
+		embeddingIndex *vectorindex.Index
+
+		mu      sync.Mutex
+		version uint64
+		tables  memoryTables
+		topics  map[string]*memoryTopic
+
+		// sequences emulate Serial8 columns.
+		sequenceMu sync.Mutex
+		sequences  map[string]int64
+	}
+
+	memoryTransaction struct {
+		storage      *memoryStorage
+		readOnly     bool
+		startVersion uint64
+		closed       bool
+
+		pages             *memoryTxTable[api.PageID, memoryPage]
+		revisions         *memoryTxTable[int64, memoryRevision]
+		paragraphs        *memoryTxTable[memoryParagraphKey, internals.ParagraphWithEmbedding]
+		terms             *memoryTxTable[memoryTermKey, int64]
+		pageTermStats     *memoryTxTable[api.PageID, memoryPageTermStats]
+		termPageStats     *memoryTxTable[memoryTermPageKey, int64]
+		synonymGroups     *memoryTxTable[api.SynonymGroupID, memorySynonymGroup]
+		integrationLogs   *memoryTxTable[int64, memoryIntegrationLogField]
+		users             *memoryTxTable[string, models.User]
+		tasks             *memoryTxTable[api.TaskID, memoryTask]
+		drafts            *memoryTxTable[api.DraftID, memoryDraft]
+		taskActions       *memoryTxTable[internals.TaskActionID, memoryTaskAction]
+		taskActionResults *memoryTxTable[internals.TaskActionID, memoryTaskActionResult]
+		llmOperations     *memoryTxTable[internals.TaskActionID, memoryPendingLLMOperation]
+		promptOverrides   *memoryTxTable[memoryPromptTemplateOverrideKey, internals.PromptTemplateOverride]
+		reindexations     *memoryTxTable[api.PageID, memoryPendingReindexation]
+
+		// messages are published to topics on commit.
+		messages []memoryTopicMessage
+	}
+
+	memoryTopicMessage struct {
+		topicName string
+		data      []byte
+	}
+
+	memoryTopicReader struct {
+		topic           *memoryTopic
+		messageCallback db_adapter.TopicReaderCallback
+	}
+)
+
+var (
+	_ Storage                = &memoryStorage{}
+	_ db_adapter.TopicReader = &memoryTopicReader{}
+)
+
+func newMemoryTable[K comparable, V any]() *memoryTable[K, V] {
+	return &memoryTable[K, V]{
+		rows:     make(map[K]V),
+		versions: make(map[K]uint64),
+	}
+}
+
+// NewMemoryStorage creates empty storage which keeps everything in process
+// memory. Transactions have snapshot isolation: commit fails if another
+// transaction has written the same rows since the start. Topic messages are
+// published on commit. users are the only accounts able to log in.
+// embeddingIndex may be nil.
+func NewMemoryStorage(log logger.Logger, users []models.User, embeddingIndex *vectorindex.Index) Storage {
+	s := &memoryStorage{
+		log:            log,
+		embeddingIndex: embeddingIndex,
+		tables: memoryTables{
+			pages:             newMemoryTable[api.PageID, memoryPage](),
+			revisions:         newMemoryTable[int64, memoryRevision](),
+			paragraphs:        newMemoryTable[memoryParagraphKey, internals.ParagraphWithEmbedding](),
+			terms:             newMemoryTable[memoryTermKey, int64](),
+			pageTermStats:     newMemoryTable[api.PageID, memoryPageTermStats](),
+			termPageStats:     newMemoryTable[memoryTermPageKey, int64](),
+			synonymGroups:     newMemoryTable[api.SynonymGroupID, memorySynonymGroup](),
+			integrationLogs:   newMemoryTable[int64, memoryIntegrationLogField](),
+			users:             newMemoryTable[string, models.User](),
+			tasks:             newMemoryTable[api.TaskID, memoryTask](),
+			drafts:            newMemoryTable[api.DraftID, memoryDraft](),
+			taskActions:       newMemoryTable[internals.TaskActionID, memoryTaskAction](),
+			taskActionResults: newMemoryTable[internals.TaskActionID, memoryTaskActionResult](),
+			llmOperations:     newMemoryTable[internals.TaskActionID, memoryPendingLLMOperation](),
+			promptOverrides:   newMemoryTable[memoryPromptTemplateOverrideKey, internals.PromptTemplateOverride](),
+			reindexations:     newMemoryTable[api.PageID, memoryPendingReindexation](),
+		},
+		topics:    make(map[string]*memoryTopic),
+		sequences: make(map[string]int64),
+	}
+	for _, topicName := range []string{taskActionsTopicName, taskActionResultsTopicName, pageRevisionAppendedTopicName} {
+		s.topics[topicName] = &memoryTopic{notify: make(chan struct{}, 1)}
+	}
+	for _, user := range users {
+		s.tables.users.rows[user.Login] = user
+	}
+	return s
+}
+
+func (s *memoryStorage) NewRepository(ctx context.Context, mode db_adapter.TransactionMode) AppRepository {
+	return &memoryRepository{
+		ctx:            ctx,
+		log:            s.log,
+		tx:             s.begin(mode == db_adapter.SnapshotReadOnly),
+		embeddingIndex: s.embeddingIndex,
+	}
+}
+
+func (s *memoryStorage) NewTopicReader(topicName string, messageCallback db_adapter.TopicReaderCallback) db_adapter.TopicReader {
+	topic, ok := s.topics[topicName]
+	if !ok {
+		panic(fmt.Sprintf("no such topic: %s", topicName))
+	}
+	return &memoryTopicReader{
+		topic:           topic,
+		messageCallback: messageCallback,
+	}
+}
+
+// nextSequenceValue returns next value of Serial8 column. Like in YDB, values
+// taken by rolled back transactions are lost.
+func (s *memoryStorage) nextSequenceValue(name string) int64 {
+	s.sequenceMu.Lock()
+	defer s.sequenceMu.Unlock()
+	s.sequences[name]++
+	return s.sequences[name]
+}
+
+func newMemoryTxTable[K comparable, V any](slot **memoryTable[K, V]) *memoryTxTable[K, V] {
+	return &memoryTxTable[K, V]{
+		slot:     slot,
+		snapshot: *slot,
+		written:  make(map[K]struct{}),
+	}
+}
+
+func (s *memoryStorage) begin(readOnly bool) *memoryTransaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return &memoryTransaction{
+		storage:           s,
+		readOnly:          readOnly,
+		startVersion:      s.version,
+		pages:             newMemoryTxTable(&s.tables.pages),
+		revisions:         newMemoryTxTable(&s.tables.revisions),
+		paragraphs:        newMemoryTxTable(&s.tables.paragraphs),
+		terms:             newMemoryTxTable(&s.tables.terms),
+		pageTermStats:     newMemoryTxTable(&s.tables.pageTermStats),
+		termPageStats:     newMemoryTxTable(&s.tables.termPageStats),
+		synonymGroups:     newMemoryTxTable(&s.tables.synonymGroups),
+		integrationLogs:   newMemoryTxTable(&s.tables.integrationLogs),
+		users:             newMemoryTxTable(&s.tables.users),
+		tasks:             newMemoryTxTable(&s.tables.tasks),
+		drafts:            newMemoryTxTable(&s.tables.drafts),
+		taskActions:       newMemoryTxTable(&s.tables.taskActions),
+		taskActionResults: newMemoryTxTable(&s.tables.taskActionResults),
+		llmOperations:     newMemoryTxTable(&s.tables.llmOperations),
+		promptOverrides:   newMemoryTxTable(&s.tables.promptOverrides),
+		reindexations:     newMemoryTxTable(&s.tables.reindexations),
+	}
+}
+
+func (t *memoryTxTable[K, V]) get(key K) (V, bool) {
+	row, ok := t.all()[key]
+	return row, ok
+}
+
+// all returns rows visible in transaction. Returned map must not be modified.
+func (t *memoryTxTable[K, V]) all() map[K]V {
+	if t.rows != nil {
+		return t.rows
+	}
+	return t.snapshot.rows
+}
+
+func (t *memoryTxTable[K, V]) put(key K, row V) {
+	if t.rows == nil {
+		t.rows = maps.Clone(t.snapshot.rows)
+	}
+	t.rows[key] = row
+	t.written[key] = struct{}{}
+}
+
+// insert adds row failing on existing key like INSERT INTO does.
+func (t *memoryTxTable[K, V]) insert(key K, row V) error {
+	if _, ok := t.get(key); ok {
+		return errDuplicateKey
+	}
+	t.put(key, row)
+	return nil
+}
+
+func (t *memoryTxTable[K, V]) delete(key K) {
+	if _, ok := t.get(key); !ok {
+		return
+	}
+	if t.rows == nil {
+		t.rows = maps.Clone(t.snapshot.rows)
+	}
+	delete(t.rows, key)
+	t.written[key] = struct{}{}
+}
+
+func (t *memoryTxTable[K, V]) isWritten() bool {
+	return len(t.written) > 0
+}
+
+func (t *memoryTxTable[K, V]) hasConflicts(startVersion uint64) bool {
+	committed := *t.slot
+	for key := range t.written {
+		if committed.versions[key] > startVersion {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *memoryTxTable[K, V]) commit(version uint64) {
+	committed := *t.slot
+	table := &memoryTable[K, V]{
+		rows:     maps.Clone(committed.rows),
+		versions: maps.Clone(committed.versions),
+	}
+	for key := range t.written {
+		row, ok := t.rows[key]
+		if ok {
+			table.rows[key] = row
+		} else {
+			delete(table.rows, key)
+		}
+		table.versions[key] = version
+	}
+	*t.slot = table
+}
+
+func (tx *memoryTransaction) committers() []memoryTxTableCommitter {
+	return []memoryTxTableCommitter{
+		tx.pages,
+		tx.revisions,
+		tx.paragraphs,
+		tx.terms,
+		tx.pageTermStats,
+		tx.termPageStats,
+		tx.synonymGroups,
+		tx.integrationLogs,
+		tx.users,
+		tx.tasks,
+		tx.drafts,
+		tx.taskActions,
+		tx.taskActionResults,
+		tx.llmOperations,
+		tx.promptOverrides,
+		tx.reindexations,
+	}
+}
+
+// checkWritable must be called before any write.
+func (tx *memoryTransaction) checkWritable() error {
+	if tx.readOnly {
+		return errWriteInReadOnlyTransaction
+	}
+	return nil
+}
+
+func (tx *memoryTransaction) enqueue(topicName string, data string) {
+	tx.messages = append(tx.messages, memoryTopicMessage{topicName: topicName, data: []byte(data)})
+}
+
+func (tx *memoryTransaction) commit() error {
+	if tx.closed {
+		return nil
+	}
+	tx.closed = true
+
+	s := tx.storage
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	committers := tx.committers()
+	for _, table := range committers {
+		if table.hasConflicts(tx.startVersion) {
+			return errTransactionLocksInvalidated
+		}
+	}
+
+	s.version++
+	for _, table := range committers {
+		if table.isWritten() {
+			table.commit(s.version)
+		}
+	}
+
+	for _, message := range tx.messages {
+		s.topics[message.topicName].publish(message.data)
+	}
+	return nil
+}
+
+func (tx *memoryTransaction) rollback() {
+	tx.closed = true
+}
+
+func (t *memoryTopic) publish(data []byte) {
+	t.mu.Lock()
+	t.messages = append(t.messages, data)
+	t.mu.Unlock()
+
+	select {
+	case t.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (t *memoryTopic) pop() ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.messages) == 0 {
+		return nil, false
+	}
+	message := t.messages[0]
+	t.messages = t.messages[1:]
+	if len(t.messages) > 0 {
+		select {
+		case t.notify <- struct{}{}:
+		default:
+		}
+	}
+	return message, true
+}
+
+func (r *memoryTopicReader) ReadMessages(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.topic.notify:
+		}
+
+		message, ok := r.topic.pop()
+		if ok {
+			r.messageCallback(message)
+		}
+	}
+}
+
+// memoryNow returns current time with precision of Datetime columns, which
+// are filled by CurrentUtcDatetime() in YDB.
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
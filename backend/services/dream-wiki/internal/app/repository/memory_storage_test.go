@@ -0,0 +1,358 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func newTestMemoryStorage() Storage {
+	return NewMemoryStorage(logger.InitTestLogger(), []models.User{{Login: "admin", PasswordHash: "hash"}}, nil)
+}
+
+func createTestPage(t *testing.T, storage Storage, slug string, content string) api.PageID {
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	pageID, err := repo.CreatePage(slug, slug, content)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+	return *pageID
+}
+
+func TestMemoryStorageTransactionIsolation(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+	ctx := context.Background()
+
+	writer := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer writer.Rollback()
+	pageID, err := writer.CreatePage("docs", "Docs", "content")
+	require.NoError(t, err)
+
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+	_, _, err = reader.GetPageByID(*pageID)
+	require.ErrorIs(t, err, models.ErrNoRows)
+
+	require.NoError(t, writer.Commit())
+
+	// Snapshot is taken at the transaction start.
+	_, _, err = reader.GetPageByID(*pageID)
+	require.ErrorIs(t, err, models.ErrNoRows)
+
+	newReader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer newReader.Rollback()
+	page, _, err := newReader.GetPageByID(*pageID)
+	require.NoError(t, err)
+	require.Equal(t, "content", page.Content)
+}
+
+func TestMemoryStorageRollback(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+	ctx := context.Background()
+
+	repo := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	_, err := repo.CreatePage("docs", "Docs", "content")
+	require.NoError(t, err)
+	repo.Rollback()
+
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+	pages, err := reader.GetAllPageDigests()
+	require.NoError(t, err)
+	require.Empty(t, pages)
+}
+
+func TestMemoryStorageWriteConflict(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+	ctx := context.Background()
+	pageID := createTestPage(t, storage, "docs", "content")
+
+	first := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer first.Rollback()
+	second := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer second.Rollback()
+
+	require.NoError(t, first.SetPageTitle(pageID, "First"))
+	require.NoError(t, second.SetPageTitle(pageID, "Second"))
+
+	require.NoError(t, first.Commit())
+	require.ErrorIs(t, second.Commit(), errTransactionLocksInvalidated)
+
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+	page, _, err := reader.GetPageByID(pageID)
+	require.NoError(t, err)
+	require.Equal(t, "First", page.Title)
+}
+
+func TestMemoryStorageReadOnlyTransaction(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	_, err := repo.CreateTask(internals.TaskState{})
+	require.ErrorIs(t, err, errWriteInReadOnlyTransaction)
+}
+
+func TestMemoryStorageTopicMessagesArePublishedOnCommit(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	messages := make(chan string, 10)
+	reader := storage.NewTopicReader(taskActionsTopicName, func(message []byte) {
+		messages <- string(message)
+	})
+	go func() {
+		_ = reader.ReadMessages(ctx)
+	}()
+
+	rolledBack := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	require.NoError(t, rolledBack.EnqueueTaskAction(1))
+	rolledBack.Rollback()
+
+	committed := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	require.NoError(t, committed.EnqueueTaskAction(2))
+	require.NoError(t, committed.EnqueueTaskAction(3))
+	require.NoError(t, committed.Commit())
+
+	for _, expected := range []string{"2", "3"} {
+		select {
+		case message := <-messages:
+			require.Equal(t, expected, message)
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered")
+		}
+	}
+	select {
+	case message := <-messages:
+		t.Fatalf("unexpected message %s", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemoryRepositoryDraftsNeedRebase(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+	ctx := context.Background()
+	pageID := createTestPage(t, storage, "docs", "v1")
+
+	repo := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	draftID, err := repo.CreateDraft(pageID, "Draft", "v2", nil, []int{0})
+	require.NoError(t, err)
+	_, err = repo.UpsertPage("docs", "docs", "v1 edited")
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+	draft, info, err := reader.GetDraftByID(*draftID)
+	require.NoError(t, err)
+	require.Equal(t, api.NeedsRebase, draft.DraftDigest.Status)
+	require.Equal(t, "v1", *draft.OriginalPageContent)
+	require.NotEqual(t, info.BaseRevisionId, info.PageCurrentRevisionId)
+	require.Equal(t, &[]int{0}, draft.SourceParagraphIndexes)
+
+	revisions, nextInfo, err := reader.ListPageRevisions(pageID, nil, 1)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, info.PageCurrentRevisionId, revisions[0].RevisionId)
+	require.True(t, nextInfo.HasMore)
+}
+
+func TestMemoryRepositorySearch(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+	ctx := context.Background()
+	firstPageID := createTestPage(t, storage, "first", "")
+	secondPageID := createTestPage(t, storage, "second", "")
+
+	repo := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	paragraphs := []internals.ParagraphWithEmbedding{
+		{PageId: firstPageID, ParagraphIndex: 0, Content: "Deploy", Headers: []string{"Deploy"}, IsHeader: true, Embedding: internals.Embedding{1, 0}},
+		{PageId: firstPageID, ParagraphIndex: 1, Content: "deploy with helm", Headers: []string{"Deploy"}, Embedding: internals.Embedding{1, 0.1}},
+		{PageId: secondPageID, ParagraphIndex: 0, Content: "build with make", Headers: []string{"Build"}, Embedding: internals.Embedding{0, 1}},
+	}
+	for _, paragraph := range paragraphs {
+		require.NoError(t, repo.AddIndexedParagraph(paragraph))
+	}
+	require.NoError(t, repo.AddTerms([]internals.Term{
+		{Term: "deploy", PageId: firstPageID, ParagraphIndex: 0, TimesIn: 1},
+		{Term: "deploy", PageId: firstPageID, ParagraphIndex: 1, TimesIn: 1},
+		{Term: "helm", PageId: firstPageID, ParagraphIndex: 1, TimesIn: 1},
+		{Term: "make", PageId: secondPageID, ParagraphIndex: 0, TimesIn: 1},
+	}))
+	require.ErrorIs(t, repo.AddTerm("helm", firstPageID, 1, 1), errDuplicateKey)
+	require.NoError(t, repo.SetPageTermStats(internals.PageTermStats{
+		PageId:               firstPageID,
+		ParagraphsCount:      2,
+		TermsCount:           3,
+		TermParagraphsCounts: map[string]int64{"deploy": 2, "helm": 1},
+	}))
+	require.NoError(t, repo.SetPageTermStats(internals.PageTermStats{
+		PageId:               secondPageID,
+		ParagraphsCount:      1,
+		TermsCount:           1,
+		TermParagraphsCounts: map[string]int64{"make": 1},
+	}))
+	require.NoError(t, repo.Commit())
+
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+
+	byEmbedding, err := reader.SearchByEmbedding("", internals.Embedding{0, 1}, internals.SearchFilters{}, 10)
+	require.NoError(t, err)
+	require.Len(t, byEmbedding, 2)
+	require.Equal(t, secondPageID, byEmbedding[0].PageId)
+	require.InDelta(t, 0, byEmbedding[0].Score, 1e-6)
+
+	byTerms, err := reader.SearchByTerms([]string{"deploy", "helm"}, internals.SearchFilters{}, 10)
+	require.NoError(t, err)
+	require.Len(t, byTerms, 1)
+	require.Equal(t, 1, byTerms[0].ParagraphIndex)
+	require.Equal(t, []string{"deploy", "helm"}, *byTerms[0].MatchedTerms)
+
+	headerPath := internals.HeadersList{"Build"}
+	filtered, err := reader.SearchByEmbedding("", internals.Embedding{1, 0}, internals.SearchFilters{HeaderPath: &headerPath}, 10)
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	require.Equal(t, secondPageID, filtered[0].PageId)
+
+	withContext, err := reader.GetParagraphWithContext(firstPageID, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, "Deploy\n\ndeploy with helm", withContext.Content)
+}
+
+func TestMemoryRepositoryUsers(t *testing.T) {
+	t.Parallel()
+	storage := newTestMemoryStorage()
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	user, err := repo.GetUserByLogin("admin")
+	require.NoError(t, err)
+	require.Equal(t, "hash", user.PasswordHash)
+
+	_, err = repo.GetUserByLogin("guest")
+	require.ErrorIs(t, err, models.ErrNoRows)
+}
+
+func TestMemoryRepositorySearchWithVectorIndex(t *testing.T) {
+	t.Parallel()
+	index := vectorindex.NewIndex(1, 1)
+	storage := NewMemoryStorage(logger.InitTestLogger(), nil, index)
+	ctx := context.Background()
+	pageID := createTestPage(t, storage, "docs", "")
+
+	repo := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	paragraphs := []internals.ParagraphWithEmbedding{
+		{PageId: pageID, ParagraphIndex: 0, Content: "deploy", Headers: []string{"Deploy"}, Embedding: internals.Embedding{1, 0}},
+		{PageId: pageID, ParagraphIndex: 1, Content: "build", Headers: []string{"Build"}, Embedding: internals.Embedding{0, 1}},
+	}
+	for _, paragraph := range paragraphs {
+		require.NoError(t, repo.AddIndexedParagraph(paragraph))
+	}
+	require.NoError(t, repo.Commit())
+
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+
+	// Index is not built yet.
+	searchResult, err := reader.SearchByEmbedding("", internals.Embedding{1, 0}, internals.SearchFilters{}, 1)
+	require.NoError(t, err)
+	require.Len(t, searchResult, 1)
+	require.Equal(t, 0, searchResult[0].ParagraphIndex)
+	require.Equal(t, int64(1), index.Diagnostics().ExactSearchesCount)
+
+	embeddings, err := reader.GetParagraphEmbeddings()
+	require.NoError(t, err)
+	require.Len(t, embeddings, 2)
+	entries := make([]vectorindex.Entry, 0, len(embeddings))
+	for _, embedding := range embeddings {
+		entries = append(entries, vectorindex.Entry{
+			Key:       vectorindex.Key{PageID: embedding.PageId, ParagraphIndex: embedding.ParagraphIndex},
+			Embedding: embedding.Embedding,
+		})
+	}
+	index.Rebuild(entries, 0)
+
+	searchResult, err = reader.SearchByEmbedding("", internals.Embedding{1, 0}, internals.SearchFilters{}, 1)
+	require.NoError(t, err)
+	require.Len(t, searchResult, 1)
+	require.Equal(t, 0, searchResult[0].ParagraphIndex)
+	require.Equal(t, int64(1), index.Diagnostics().ApproximateSearchesCount)
+
+	// Header filter leaves fewer results than requested, so search falls back
+	// to the full scan.
+	headerPath := internals.HeadersList{"Build"}
+	searchResult, err = reader.SearchByEmbedding("", internals.Embedding{1, 0}, internals.SearchFilters{HeaderPath: &headerPath}, 2)
+	require.NoError(t, err)
+	require.Len(t, searchResult, 1)
+	require.Equal(t, 1, searchResult[0].ParagraphIndex)
+	diagnostics := index.Diagnostics()
+	require.Equal(t, int64(1), diagnostics.FallbacksCount)
+	require.Equal(t, int64(2), diagnostics.ExactSearchesCount)
+}
+
+func TestMemoryRepositoryReindexationUpdatesVectorIndex(t *testing.T) {
+	t.Parallel()
+	index := vectorindex.NewIndex(1, 1)
+	storage := NewMemoryStorage(logger.InitTestLogger(), nil, index)
+	ctx := context.Background()
+	pageID := createTestPage(t, storage, "docs", "")
+
+	indexate := func(commit bool, paragraphs ...internals.ParagraphWithEmbedding) {
+		repo := storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+		defer repo.Rollback()
+		require.NoError(t, repo.RemovePageIndexation(pageID))
+		for _, paragraph := range paragraphs {
+			require.NoError(t, repo.AddIndexedParagraph(paragraph))
+		}
+		if commit {
+			require.NoError(t, repo.Commit())
+		}
+	}
+
+	indexate(true, internals.ParagraphWithEmbedding{PageId: pageID, ParagraphIndex: 0, Content: "deploy", Embedding: internals.Embedding{1, 0}})
+	index.Rebuild([]vectorindex.Entry{{Key: vectorindex.Key{PageID: pageID, ParagraphIndex: 0}, Embedding: internals.Embedding{1, 0}}}, index.UpdatesSequence())
+
+	// Rolled back reindexation does not touch the index.
+	indexate(false)
+	require.Equal(t, 1, index.Diagnostics().EntriesCount)
+
+	indexate(true,
+		internals.ParagraphWithEmbedding{PageId: pageID, ParagraphIndex: 0, Content: "deploy", Embedding: internals.Embedding{1, 0}},
+		internals.ParagraphWithEmbedding{PageId: pageID, ParagraphIndex: 1, Content: "build", Embedding: internals.Embedding{0, 1}},
+	)
+	require.Equal(t, 2, index.Diagnostics().EntriesCount)
+
+	// New paragraph is found by the index without rebuild and fallback.
+	reader := storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+	searchResult, err := reader.SearchByEmbedding("", internals.Embedding{0, 1}, internals.SearchFilters{}, 2)
+	require.NoError(t, err)
+	require.Len(t, searchResult, 2)
+	require.Equal(t, 1, searchResult[0].ParagraphIndex)
+	diagnostics := index.Diagnostics()
+	require.Equal(t, int64(1), diagnostics.ApproximateSearchesCount)
+	require.Equal(t, int64(0), diagnostics.FallbacksCount)
+}
@@ -2,11 +2,12 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
@@ -17,14 +18,15 @@ type (
 		Rollback()
 
 		// domain_drafts.go
-		CreateDraft(pageID api.PageID, draftTitle string, draftContent string) (*api.DraftID, error)
-		GetDraftByID(draftID api.DraftID) (*api.Draft, error)
+		CreateDraft(pageID api.PageID, draftTitle string, draftContent string, sourceTaskID *api.TaskID, sourceParagraphIndexes []int) (*api.DraftID, error)
+		GetDraftByID(draftID api.DraftID) (*api.Draft, *internals.DraftAdditionalInfo, error)
 		ListDrafts(cursor *api.Cursor, limit int64) ([]api.DraftDigest, *api.NextInfo, error)
 		RemoveDraft(draftID api.DraftID) error
 		SetDraftStatus(draftID api.DraftID, newStatus api.DraftStatus) error
 		SetDraftContent(draftID api.DraftID, newContent string) error
 		SetDraftTitle(draftID api.DraftID, newTitle string) error
 		SetDraftBaseRevision(draftID api.DraftID, newRevisionID internals.RevisionID) error
+		MarkPageDraftsNeedRebase(pageID api.PageID) error
 
 		// domain_integration_logs.go
 		WriteIntegrationLogField(integrationID api.IntegrationID, logText string) error
@@ -32,24 +34,52 @@ type (
 
 		// domain_page_indexation.go
 		RemovePageIndexation(pageID api.PageID) error
+		GetPageIndexedParagraphs(pageID api.PageID) ([]internals.IndexedParagraphDigest, error)
+		GetPageTerms(pageID api.PageID) ([]internals.Term, error)
 		AddIndexedParagraph(paragraph internals.ParagraphWithEmbedding) error
 		AddTerm(term string, pageID api.PageID, paragraphIndex int64, timesIn int64) error
 		AddTerms(terms []internals.Term) error
+		SetPageTermStats(stats internals.PageTermStats) error
+		GetParagraphEmbeddings() ([]internals.ParagraphEmbedding, error)
+		AddPendingReindexationPage(pageID api.PageID, writtenAt time.Time) error
+		GetDuePendingReindexationPages(lastWrittenBefore time.Time, firstWrittenBefore time.Time) ([]api.PageID, error)
+		RemovePendingReindexationPages(pageIDs []api.PageID) error
 
 		// domain_pages.go
 		GetPageBySlug(yWikiSlug string) (*api.Page, error)
 		CreatePage(yWikiSlug string, title string, content string) (*api.PageID, error)
-		AppendPageRevision(pageID api.PageID, newContent string) (*internals.RevisionID, error)
+		AppendPageRevision(pageID api.PageID, newContent string, source api.RevisionSource, author *string) (*internals.RevisionID, error)
 		DeletePageBySlug(yWikiSlug string) error
 		GetAllPageDigests() ([]api.PageDigest, error)
+		GetPageTreeNodes() ([]internals.PageTreeNode, error)
 		GetPageByID(pageID api.PageID) (*api.Page, *internals.PageAdditionalInfo, error)
 		SetPageTitle(pageID api.PageID, newTitle string) error
+		SetPageParentID(pageID api.PageID, parentPageID *api.PageID) error
+		UpsertPage(yWikiSlug string, title string, content string) (*api.PageID, error)
+		GetClosestAncestorPageID(yWikiSlug string) (*api.PageID, error)
 		DeleteAllPages() error
 
+		// domain_prompts.go
+		GetLatestPromptTemplateOverride(name string) (*internals.PromptTemplateOverride, error)
+		ListPromptTemplateOverrides(name string) ([]internals.PromptTemplateOverride, error)
+		CreatePromptTemplateOverride(override internals.PromptTemplateOverride) (*internals.PromptTemplateOverride, error)
+
+		// domain_revisions.go
+		ListPageRevisions(pageID api.PageID, cursor *api.Cursor, limit int64) ([]api.RevisionDigest, *api.NextInfo, error)
+		GetRevisionByID(revisionID api.RevisionID) (*api.Revision, error)
+
 		// domain_search.go
-		SearchByEmbedding(query string, queryEmbedding internals.Embedding, limit int) ([]internals.SearchResultItem, error)
+		SearchByEmbedding(query string, queryEmbedding internals.Embedding, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error)
 		SearchByEmbeddingWithContext(query string, queryEmbedding internals.Embedding, contextSize int) ([]internals.ParagraphWithContext, error)
-		SearchByTerms(terms []string, limit int) ([]internals.SearchResultItem, error)
+		SearchByTerms(terms []string, filters internals.SearchFilters, limit int) ([]internals.SearchResultItem, error)
+		GetParagraphWithContext(pageID api.PageID, paragraphIndex int, contextSize int) (*internals.ParagraphWithContext, error)
+		GetTermVocabulary(prefix string) ([]string, error)
+
+		// domain_synonyms.go
+		ListSynonymGroups() ([]api.SynonymGroup, error)
+		CreateSynonymGroup(phrases []string) (*api.SynonymGroupID, error)
+		SetSynonymGroupPhrases(synonymGroupID api.SynonymGroupID, phrases []string) error
+		RemoveSynonymGroup(synonymGroupID api.SynonymGroupID) error
 
 		// domain_tasks.go
 		GetTaskByID(taskID api.TaskID) (*api.TaskDigest, *internals.TaskState, error)
@@ -68,6 +98,11 @@ type (
 		CreateTaskActionResult(actionID internals.TaskActionID, result internals.TaskActionResult) error
 		GetTaskActionResultByID(actionID internals.TaskActionID) (*internals.TaskActionResult, *internals.TaskActionResultAdditionalInfo, error)
 		EnqueueTaskActionResult(actionID internals.TaskActionID) error
+		GetTaskActionIDsByStatus(taskID api.TaskID, statuses []internals.TaskActionStatus) ([]internals.TaskActionID, error)
+		AbandonPendingTaskActions(taskID api.TaskID) error
+		SetPendingLLMOperation(actionID internals.TaskActionID, operationID string) error
+		GetPendingLLMOperations() ([]internals.PendingLLMOperation, error)
+		RemovePendingLLMOperation(actionID internals.TaskActionID, operationID string) error
 
 		// domain_users.go
 		GetUserByLogin(username string) (*models.User, error)
@@ -77,6 +112,9 @@ type (
 		ctx context.Context
 		tx  db_adapter.Transaction
 		log logger.Logger
+		// embeddingIndex may be nil, then embedding search scans all paragraphs.
+		embeddingIndex       *vectorindex.Index
+		embeddingIndexUpdate embeddingIndexUpdate
 	}
 )
 
@@ -84,18 +122,25 @@ var (
 	_ AppRepository = &appRepositoryImpl{}
 )
 
-func NewAppRepository(ctx context.Context, deps *deps.RepositoryDeps) AppRepository {
+func newAppRepository(ctx context.Context, tx db_adapter.Transaction, log logger.Logger, embeddingIndex *vectorindex.Index) AppRepository {
 	return &appRepositoryImpl{
-		ctx: ctx,
-		log: deps.Deps.Logger,
-		tx:  deps.TX,
+		ctx:            ctx,
+		log:            log,
+		tx:             tx,
+		embeddingIndex: embeddingIndex,
 	}
 }
 
 func (r *appRepositoryImpl) Commit() error {
-	return r.tx.Commit()
+	err := r.tx.Commit()
+	if err != nil {
+		return err
+	}
+	r.embeddingIndexUpdate.apply(r.embeddingIndex)
+	return nil
 }
 
 func (r *appRepositoryImpl) Rollback() {
 	r.tx.Rollback()
+	r.embeddingIndexUpdate = embeddingIndexUpdate{}
 }
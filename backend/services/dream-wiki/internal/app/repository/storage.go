@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+)
+
+const (
+	taskActionsTopicName          = "TaskActionToExecute"
+	taskActionResultsTopicName    = "TaskActionResultReady"
+	pageRevisionAppendedTopicName = "PageRevisionAppended"
+)
+
+type (
+	// Storage keeps application data and topics. YDB storage is used in
+	// production, memory one runs without any database, see NewMemoryStorage.
+	Storage interface {
+		// NewRepository opens repository in a new transaction. Repository must be
+		// committed or rolled back.
+		NewRepository(ctx context.Context, mode db_adapter.TransactionMode) AppRepository
+		// NewTopicReader reads messages enqueued by repositories, e.g. by
+		// AppRepository.EnqueueTaskAction.
+		NewTopicReader(topicName string, messageCallback db_adapter.TopicReaderCallback) db_adapter.TopicReader
+	}
+
+	ydbStorage struct {
+		adapter        db_adapter.DBAdapter
+		log            logger.Logger
+		embeddingIndex *vectorindex.Index
+	}
+)
+
+var (
+	_ Storage = &ydbStorage{}
+)
+
+// NewYDBStorage creates storage on top of YDB. embeddingIndex speeds up
+// embedding search when ready and may be nil.
+func NewYDBStorage(adapter db_adapter.DBAdapter, log logger.Logger, embeddingIndex *vectorindex.Index) Storage {
+	return &ydbStorage{
+		adapter:        adapter,
+		log:            log,
+		embeddingIndex: embeddingIndex,
+	}
+}
+
+func (s *ydbStorage) NewRepository(ctx context.Context, mode db_adapter.TransactionMode) AppRepository {
+	return newAppRepository(ctx, s.adapter.NewTransaction(ctx, mode), s.log, s.embeddingIndex)
+}
+
+func (s *ydbStorage) NewTopicReader(topicName string, messageCallback db_adapter.TopicReaderCallback) db_adapter.TopicReader {
+	return s.adapter.NewTopicReader(topicName, messageCallback)
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 	"github.com/ydb-platform/ydb-go-sdk/v3/table/types"
 )
@@ -12,3 +16,19 @@ func embeddingToYDBList(embedding internals.Embedding) types.Value {
 	}
 	return types.ListValue(embeddingValues...)
 }
+
+// knnFloatFormat is the trailing byte of Knn::ToBinaryStringFloat output, which
+// is little-endian float32 values followed by the format byte.
+const knnFloatFormat = 1
+
+func embeddingFromYDBBinary(data []byte) (internals.Embedding, error) {
+	if len(data)%4 != 1 || data[len(data)-1] != knnFloatFormat {
+		return nil, fmt.Errorf("unexpected binary embedding format")
+	}
+
+	embedding := make(internals.Embedding, 0, len(data)/4)
+	for offset := 0; offset+4 < len(data); offset += 4 {
+		embedding = append(embedding, math.Float32frombits(binary.LittleEndian.Uint32(data[offset:])))
+	}
+	return embedding, nil
+}
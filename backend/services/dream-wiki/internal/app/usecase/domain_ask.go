@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const (
+	askResultMaxWait      = 30 * time.Second
+	askResultPollInterval = time.Second
+)
+
+// AskQuestion starts a task which answers the question using knowledge base
+// search results as the only source of truth.
+func (u *appUsecaseImpl) AskQuestion(req api.V1AskRequest) (*api.TaskID, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	filters, err := resolveSearchFilters(repo, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var taskState internals.TaskState
+	err = taskState.FromTaskStateAskQuestion(internals.TaskStateAskQuestion{
+		TaskType: internals.AskQuestion,
+		Question: req.Question,
+		Filters:  *filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := repo.CreateTask(taskState)
+	if err != nil {
+		return nil, err
+	}
+
+	taskAction := internals.TaskAction{}
+	taskAction.FromTaskActionNewTask(internals.TaskActionNewTask{TaskActionType: internals.NewTask})
+	taskActionID, err := repo.CreateTaskAction(*taskID, taskAction)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.EnqueueTaskAction(*taskActionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return taskID, repo.Commit()
+}
+
+func (u *appUsecaseImpl) getAskQuestionTask(taskID api.TaskID) (api.TaskStatus, *internals.TaskStateAskQuestion, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	taskDigest, taskState, err := repo.GetTaskByID(taskID)
+	if err != nil {
+		return "", nil, err
+	}
+	if discriminator, _ := taskState.Discriminator(); internals.TaskType(discriminator) != internals.AskQuestion {
+		return "", nil, models.ErrTaskNotAskQuestion
+	}
+
+	askState, err := taskState.AsTaskStateAskQuestion()
+	if err != nil {
+		return "", nil, err
+	}
+	return taskDigest.Status, &askState, nil
+}
+
+// GetAskResult returns the answer. While the task is executing it is polled for
+// up to waitSeconds, so that clients may long-poll instead of sleeping.
+func (u *appUsecaseImpl) GetAskResult(taskID api.TaskID, waitSeconds int) (*api.V1AskResultResponse, error) {
+	deadline := time.Now().Add(min(time.Duration(max(waitSeconds, 0))*time.Second, askResultMaxWait))
+
+	for {
+		status, state, err := u.getAskQuestionTask(taskID)
+		if err != nil {
+			return nil, err
+		}
+		if task_common.IsTerminalTaskStatus(status) || !time.Now().Before(deadline) {
+			return makeAskResult(status, state), nil
+		}
+
+		select {
+		case <-u.ctx.Done():
+			return nil, u.ctx.Err()
+		case <-time.After(min(askResultPollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+func makeAskResult(status api.TaskStatus, state *internals.TaskStateAskQuestion) *api.V1AskResultResponse {
+	result := &api.V1AskResultResponse{
+		Status:    status,
+		Answer:    state.Answer,
+		Citations: []api.AskCitation{},
+	}
+	if state.CitedSourceNumbers == nil || state.Sources == nil {
+		return result
+	}
+
+	sources := *state.Sources
+	for _, sourceNumber := range *state.CitedSourceNumbers {
+		if sourceNumber < 1 || sourceNumber > len(sources) {
+			continue
+		}
+		source := sources[sourceNumber-1]
+		result.Citations = append(result.Citations, api.AskCitation{
+			SourceNumber:    sourceNumber,
+			PageId:          source.PageId,
+			Title:           source.PageTitle,
+			YwikiAnchorLink: createYWikiAnchorLink(source.AnchorSlug, source.PageSlug),
+			AnchorSlug:      source.AnchorSlug,
+			LineIndex:       source.LineIndex,
+			Snippet:         source.Content,
+		})
+	}
+	return result
+}
@@ -1,7 +1,16 @@
 package usecase
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/diff"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/indexing"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
 
 func (u *appUsecaseImpl) CreateDraft(originalPageID api.PageID) (*api.DraftDigest, error) {
@@ -13,12 +22,12 @@ func (u *appUsecaseImpl) CreateDraft(originalPageID api.PageID) (*api.DraftDiges
 		return nil, err
 	}
 
-	draftID, err := repo.CreateDraft(page.PageId, page.Title, page.Content)
+	draftID, err := repo.CreateDraft(page.PageId, page.Title, page.Content, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	draft, err := repo.GetDraftByID(*draftID)
+	draft, _, err := repo.GetDraftByID(*draftID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +44,15 @@ func (u *appUsecaseImpl) DeleteDraft(draftID api.DraftID) error {
 	repo := u.createReadWriteRepository()
 	defer repo.Rollback()
 
-	err := repo.RemoveDraft(draftID)
+	draft, _, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return err
+	}
+	if draft.DraftDigest.Status == api.Publishing {
+		return models.ErrDraftPublishing
+	}
+
+	err = repo.RemoveDraft(draftID)
 	if err != nil {
 		return err
 	}
@@ -47,7 +64,7 @@ func (u *appUsecaseImpl) GetDraft(draftID api.DraftID) (*api.Draft, error) {
 	repo := u.createReadOnlyRepository()
 	defer repo.Rollback()
 
-	draft, err := repo.GetDraftByID(draftID)
+	draft, _, err := repo.GetDraftByID(draftID)
 	if err != nil {
 		return nil, err
 	}
@@ -55,26 +72,70 @@ func (u *appUsecaseImpl) GetDraft(draftID api.DraftID) (*api.Draft, error) {
 	return draft, nil
 }
 
-func (u *appUsecaseImpl) ApplyDraft(draftID api.DraftID) error {
+// ApplyDraft starts a task which publishes draft to YWiki and appends its content
+// as a new page revision after that. Only active drafts based on the current page
+// revision may be applied, others must be rebased first.
+func (u *appUsecaseImpl) ApplyDraft(draftID api.DraftID) (*api.TaskID, error) {
 	repo := u.createReadWriteRepository()
 	defer repo.Rollback()
 
-	draft, err := repo.GetDraftByID(draftID)
+	draft, draftAdditionalInfo, err := repo.GetDraftByID(draftID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	switch {
+	case draft.DraftDigest.Status == api.Merged:
+		return nil, models.ErrDraftMerged
+	case draft.DraftDigest.Status == api.Publishing:
+		return nil, models.ErrDraftPublishing
+	case draft.DraftDigest.Status == api.Conflicted:
+		return nil, models.ErrDraftHasConflicts
+	case draft.DraftDigest.Status == api.NeedsRebase || draftAdditionalInfo.BaseRevisionId != draftAdditionalInfo.PageCurrentRevisionId:
+		return nil, models.ErrDraftNeedsRebase
 	}
 
-	_, err = repo.AppendPageRevision(draft.DraftDigest.PageDigest.PageId, draft.Content)
+	var taskState internals.TaskState
+	err = taskState.FromTaskStateYWikiPublishDraft(internals.TaskStateYWikiPublishDraft{
+		TaskType:  internals.YwikiPublishDraft,
+		DraftId:   draftID,
+		PageTitle: draft.DraftDigest.PageDigest.Title,
+		AppliedBy: u.currentUsername(),
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	err = repo.SetDraftStatus(draftID, api.Merged)
+	taskID, err := repo.CreateTask(taskState)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return repo.Commit()
+	// Draft is marked before YWiki is written, so it can not be applied or
+	// changed while the task publishes it.
+	err = repo.SetDraftStatus(draftID, api.Publishing)
+	if err != nil {
+		return nil, err
+	}
+
+	taskAction := internals.TaskAction{}
+	taskAction.FromTaskActionNewTask(internals.TaskActionNewTask{TaskActionType: internals.NewTask})
+	taskActionID, err := repo.CreateTaskAction(*taskID, taskAction)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.EnqueueTaskAction(*taskActionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return taskID, nil
 }
 
 func (u *appUsecaseImpl) ListDrafts(cursor *string) ([]api.DraftDigest, *api.NextInfo, error) {
@@ -98,19 +159,111 @@ func (u *appUsecaseImpl) ListDrafts(cursor *string) ([]api.DraftDigest, *api.Nex
 	return drafts, newCursor, nil
 }
 
+// RebaseDraft moves draft to the current page revision with three-way merge of draft
+// changes and page changes. Conflicting hunks are left in draft content between
+// markers, and draft stays conflicted until its content is updated.
+func (u *appUsecaseImpl) RebaseDraft(draftID api.DraftID) (*api.V1DraftsRebaseResponse, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	draft, draftAdditionalInfo, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDraftEditable(draft)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := make([]api.DraftConflict, 0)
+	if draftAdditionalInfo.BaseRevisionId == draftAdditionalInfo.PageCurrentRevisionId {
+		return &api.V1DraftsRebaseResponse{Draft: *draft, Conflicts: conflicts}, nil
+	}
+
+	page, _, err := repo.GetPageByID(draft.DraftDigest.PageDigest.PageId)
+	if err != nil {
+		return nil, err
+	}
+
+	baseContent := ""
+	if draft.OriginalPageContent != nil {
+		baseContent = *draft.OriginalPageContent
+	}
+
+	mergeResult := diff.Merge3(diff.SplitLines(baseContent), diff.SplitLines(draft.Content), diff.SplitLines(page.Content))
+	for _, conflict := range mergeResult.Conflicts {
+		conflicts = append(conflicts, api.DraftConflict{
+			LineIndex:  conflict.MergedLineIndex,
+			BaseLines:  conflict.BaseLines,
+			DraftLines: conflict.OursLines,
+			PageLines:  conflict.TheirsLines,
+		})
+	}
+
+	err = repo.SetDraftContent(draftID, diff.JoinLines(mergeResult.Lines))
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.SetDraftBaseRevision(draftID, draftAdditionalInfo.PageCurrentRevisionId)
+	if err != nil {
+		return nil, err
+	}
+
+	newStatus := api.Active
+	if len(conflicts) > 0 {
+		newStatus = api.Conflicted
+	}
+	err = repo.SetDraftStatus(draftID, newStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	rebasedDraft, _, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.V1DraftsRebaseResponse{Draft: *rebasedDraft, Conflicts: conflicts}, nil
+}
+
+// UpdateDraft sets new draft content and title. New content of conflicted draft is
+// treated as conflicts resolution once it has no conflict markers left.
 func (u *appUsecaseImpl) UpdateDraft(draftID api.DraftID, newContent *string, newTitle *string) error {
 	repo := u.createReadWriteRepository()
 	defer repo.Rollback()
 
+	draft, _, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return err
+	}
+	err = checkDraftEditable(draft)
+	if err != nil {
+		return err
+	}
+
 	if newContent != nil {
-		err := repo.SetDraftContent(draftID, *newContent)
+		err = repo.SetDraftContent(draftID, *newContent)
 		if err != nil {
 			return err
 		}
+
+		if draft.DraftDigest.Status == api.Conflicted && !diff.HasConflictMarkers(diff.SplitLines(*newContent)) {
+			err = repo.SetDraftStatus(draftID, api.Active)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	if newTitle != nil {
-		err := repo.SetDraftTitle(draftID, *newTitle)
+		err = repo.SetDraftTitle(draftID, *newTitle)
 		if err != nil {
 			return err
 		}
@@ -118,3 +271,167 @@ func (u *appUsecaseImpl) UpdateDraft(draftID api.DraftID, newContent *string, ne
 
 	return repo.Commit()
 }
+
+// checkDraftEditable refuses changes of merged drafts and drafts being published.
+func checkDraftEditable(draft *api.Draft) error {
+	switch draft.DraftDigest.Status {
+	case api.Merged:
+		return models.ErrDraftMerged
+	case api.Publishing:
+		return models.ErrDraftPublishing
+	}
+	return nil
+}
+
+// draftVersion identifies draft content together with its base revision, so hunk
+// indexes received from reviewer can be checked for being up to date.
+func draftVersion(baseRevisionID int64, content string) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%d\n%s", baseRevisionID, content)))
+	return hex.EncodeToString(hash[:])
+}
+
+func blockBoundaries(blocks []indexing.ParagraphBlock) []int {
+	boundaries := make([]int, 0, 2*len(blocks))
+	for _, block := range blocks {
+		boundaries = append(boundaries, block.StartLine, block.EndLine)
+	}
+	return boundaries
+}
+
+func blocksParagraphIndexes(blocks []indexing.ParagraphBlock, startLine int, endLine int) []int {
+	paragraphIndexes := make([]int, 0)
+	for _, block := range blocks {
+		if block.StartLine < endLine && block.EndLine > startLine {
+			paragraphIndexes = append(paragraphIndexes, block.ParagraphIndexes...)
+		}
+	}
+	return paragraphIndexes
+}
+
+// chunkingPolicy is the policy pages are indexed with, so paragraph indexes
+// reported for drafts match indexed paragraphs.
+func (u *appUsecaseImpl) chunkingPolicy() indexing.ChunkingPolicy {
+	return indexing.ChunkingPolicy{
+		MaxTokens:     u.deps.Config.ChunkMaxTokens,
+		MinTokens:     u.deps.Config.ChunkMinTokens,
+		OverlapTokens: u.deps.Config.ChunkOverlapTokens,
+	}
+}
+
+// draftHunks returns line hunks which turn base content into draft content. Hunks
+// are widened to whole blocks of paragraphs, as indexing splits them.
+func draftHunks(baseContent string, draftContent string, policy indexing.ChunkingPolicy) ([]diff.Hunk, []api.DraftDiffHunk) {
+	baseLines := diff.SplitLines(baseContent)
+	draftLines := diff.SplitLines(draftContent)
+	baseBlocks := indexing.SplitPageToBlocks(baseContent, policy)
+	draftBlocks := indexing.SplitPageToBlocks(draftContent, policy)
+
+	hunks := diff.AlignHunks(
+		diff.Lines(baseLines, draftLines),
+		blockBoundaries(baseBlocks), len(baseLines),
+		blockBoundaries(draftBlocks), len(draftLines),
+	)
+
+	apiHunks := make([]api.DraftDiffHunk, 0, len(hunks))
+	for i, hunk := range hunks {
+		apiHunks = append(apiHunks, api.DraftDiffHunk{
+			HunkIndex:             i,
+			BaseLineStart:         hunk.AStart,
+			BaseLines:             slices.Clone(baseLines[hunk.AStart:hunk.AEnd]),
+			DraftLineStart:        hunk.BStart,
+			DraftLines:            slices.Clone(draftLines[hunk.BStart:hunk.BEnd]),
+			BaseParagraphIndexes:  blocksParagraphIndexes(baseBlocks, hunk.AStart, hunk.AEnd),
+			DraftParagraphIndexes: blocksParagraphIndexes(draftBlocks, hunk.BStart, hunk.BEnd),
+		})
+	}
+
+	return hunks, apiHunks
+}
+
+func makeDraftDiffResponse(baseRevisionID int64, baseContent string, draftContent string, policy indexing.ChunkingPolicy) *api.V1DraftsDiffResponse {
+	_, apiHunks := draftHunks(baseContent, draftContent, policy)
+	return &api.V1DraftsDiffResponse{
+		DraftVersion: draftVersion(baseRevisionID, draftContent),
+		Hunks:        apiHunks,
+	}
+}
+
+// DiffDraft returns changes of the draft relative to the revision it is based on.
+func (u *appUsecaseImpl) DiffDraft(draftID api.DraftID) (*api.V1DraftsDiffResponse, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	draft, draftAdditionalInfo, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseContent := ""
+	if draft.OriginalPageContent != nil {
+		baseContent = *draft.OriginalPageContent
+	}
+
+	return makeDraftDiffResponse(draftAdditionalInfo.BaseRevisionId, baseContent, draft.Content, u.chunkingPolicy()), nil
+}
+
+// RejectDraftHunks brings rejected hunks of the draft back to the base revision
+// content. Other hunks are considered accepted and stay in the draft.
+func (u *appUsecaseImpl) RejectDraftHunks(draftID api.DraftID, expectedDraftVersion string, rejectedHunkIndexes []int) (*api.V1DraftsDiffResponse, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	draft, draftAdditionalInfo, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkDraftEditable(draft)
+	if err != nil {
+		return nil, err
+	}
+	if draftVersion(draftAdditionalInfo.BaseRevisionId, draft.Content) != expectedDraftVersion {
+		return nil, models.ErrDraftChanged
+	}
+
+	baseContent := ""
+	if draft.OriginalPageContent != nil {
+		baseContent = *draft.OriginalPageContent
+	}
+
+	hunks, _ := draftHunks(baseContent, draft.Content, u.chunkingPolicy())
+	rejected := make([]bool, len(hunks))
+	for _, hunkIndex := range rejectedHunkIndexes {
+		if hunkIndex < 0 || hunkIndex >= len(hunks) {
+			return nil, models.ErrDraftHunkNotFound
+		}
+		rejected[hunkIndex] = true
+	}
+
+	baseLines := diff.SplitLines(baseContent)
+	draftLines := diff.SplitLines(draft.Content)
+	newLines := make([]string, 0, len(draftLines))
+	draftPosition := 0
+	for i, hunk := range hunks {
+		newLines = append(newLines, draftLines[draftPosition:hunk.BStart]...)
+		if rejected[i] {
+			newLines = append(newLines, baseLines[hunk.AStart:hunk.AEnd]...)
+		} else {
+			newLines = append(newLines, draftLines[hunk.BStart:hunk.BEnd]...)
+		}
+		draftPosition = hunk.BEnd
+	}
+	newLines = append(newLines, draftLines[draftPosition:]...)
+	newContent := diff.JoinLines(newLines)
+
+	err = repo.SetDraftContent(draftID, newContent)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return makeDraftDiffResponse(draftAdditionalInfo.BaseRevisionId, baseContent, newContent, u.chunkingPolicy()), nil
+}
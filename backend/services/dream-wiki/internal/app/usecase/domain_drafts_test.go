@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// createTestDraft stores page with base content and its draft.
+func createTestDraft(t *testing.T, storage repository.Storage, baseContent string, draftContent string) (api.PageID, api.DraftID) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	pageID, err := repo.CreatePage("page", "Page", baseContent)
+	require.NoError(t, err)
+	draftID, err := repo.CreateDraft(*pageID, "Page", draftContent, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	return *pageID, *draftID
+}
+
+func appendTestPageRevision(t *testing.T, storage repository.Storage, pageID api.PageID, content string) internals.RevisionID {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	revisionID, err := repo.AppendPageRevision(pageID, content, api.YwikiSync, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	return *revisionID
+}
+
+func getTestPageRevisionID(t *testing.T, storage repository.Storage, pageID api.PageID) internals.RevisionID {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	_, pageInfo, err := repo.GetPageByID(pageID)
+	require.NoError(t, err)
+	return *pageInfo.CurrentRevisionId
+}
+
+func setTestDraftStatus(t *testing.T, storage repository.Storage, draftID api.DraftID, status api.DraftStatus) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	require.NoError(t, repo.SetDraftStatus(draftID, status))
+	require.NoError(t, repo.Commit())
+}
+
+func getTestDraft(t *testing.T, storage repository.Storage, draftID api.DraftID) (*api.Draft, *internals.DraftAdditionalInfo) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	draft, draftInfo, err := repo.GetDraftByID(draftID)
+	require.NoError(t, err)
+	return draft, draftInfo
+}
+
+// getTestPageContents returns contents of page revisions from the oldest one.
+func getTestPageContents(t *testing.T, storage repository.Storage, pageID api.PageID) []string {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	revisionDigests, _, err := repo.ListPageRevisions(pageID, nil, 100)
+	require.NoError(t, err)
+	contents := make([]string, 0, len(revisionDigests))
+	for i := len(revisionDigests) - 1; i >= 0; i-- {
+		revision, err := repo.GetRevisionByID(revisionDigests[i].RevisionId)
+		require.NoError(t, err)
+		contents = append(contents, revision.Content)
+	}
+	return contents
+}
+
+func TestApplyDraft(t *testing.T) {
+	t.Parallel()
+
+	t.Run("up to date draft is published by task", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		pageID, draftID := createTestDraft(t, storage, "a\nb", "a\nB")
+
+		taskID, err := u.ApplyDraft(draftID)
+		require.NoError(t, err)
+		_, taskState := getTestTask(t, storage, *taskID)
+		publishState, err := taskState.AsTaskStateYWikiPublishDraft()
+		require.NoError(t, err)
+		require.Equal(t, draftID, publishState.DraftId)
+		require.Len(t, readEnqueuedTaskActions(t, storage), 1)
+
+		// Draft is merged by the task only after YWiki accepts it.
+		require.Equal(t, []string{"a\nb"}, getTestPageContents(t, storage, pageID))
+		draft, _ := getTestDraft(t, storage, draftID)
+		require.Equal(t, api.Publishing, draft.DraftDigest.Status)
+
+		// Draft being published can be neither applied again nor changed.
+		_, err = u.ApplyDraft(draftID)
+		require.ErrorIs(t, err, models.ErrDraftPublishing)
+		newContent := "a\nb\nc"
+		err = u.UpdateDraft(draftID, &newContent, nil)
+		require.ErrorIs(t, err, models.ErrDraftPublishing)
+		require.Empty(t, readEnqueuedTaskActions(t, storage))
+		draft, _ = getTestDraft(t, storage, draftID)
+		require.Equal(t, "a\nB", draft.Content)
+	})
+
+	t.Run("stale draft is refused", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		pageID, draftID := createTestDraft(t, storage, "a\nb", "a\nB")
+		appendTestPageRevision(t, storage, pageID, "A\nb")
+
+		_, err := u.ApplyDraft(draftID)
+		require.ErrorIs(t, err, models.ErrDraftNeedsRebase)
+		require.ErrorIs(t, err, models.ErrConflict)
+		require.Empty(t, readEnqueuedTaskActions(t, storage))
+		require.Equal(t, []string{"a\nb", "A\nb"}, getTestPageContents(t, storage, pageID))
+	})
+
+	t.Run("conflicted draft is refused", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		_, draftID := createTestDraft(t, storage, "a\nb", "a\nB")
+		setTestDraftStatus(t, storage, draftID, api.Conflicted)
+
+		_, err := u.ApplyDraft(draftID)
+		require.ErrorIs(t, err, models.ErrDraftHasConflicts)
+	})
+}
+
+func TestRebaseDraft(t *testing.T) {
+	t.Parallel()
+
+	t.Run("changes are merged without conflicts", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		pageID, draftID := createTestDraft(t, storage, "a\nb\nc", "a\nB\nc")
+		revisionID := appendTestPageRevision(t, storage, pageID, "a\nb\nC")
+
+		result, err := u.RebaseDraft(draftID)
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+		require.Equal(t, api.Active, result.Draft.DraftDigest.Status)
+		draft, draftInfo := getTestDraft(t, storage, draftID)
+		require.Equal(t, "a\nB\nC", draft.Content)
+		require.Equal(t, revisionID, draftInfo.BaseRevisionId)
+		require.Equal(t, api.Active, draft.DraftDigest.Status)
+
+		_, err = u.ApplyDraft(draftID)
+		require.NoError(t, err)
+	})
+
+	t.Run("conflicts are reported", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		pageID, draftID := createTestDraft(t, storage, "a\nb\nc", "a\ndraft\nc")
+		revisionID := appendTestPageRevision(t, storage, pageID, "a\npage\nc")
+
+		result, err := u.RebaseDraft(draftID)
+		require.NoError(t, err)
+		require.Equal(t, []api.DraftConflict{{
+			LineIndex:  1,
+			BaseLines:  []string{"b"},
+			DraftLines: []string{"draft"},
+			PageLines:  []string{"page"},
+		}}, result.Conflicts)
+		draft, draftInfo := getTestDraft(t, storage, draftID)
+		require.Equal(t, api.Conflicted, draft.DraftDigest.Status)
+		require.Equal(t, revisionID, draftInfo.BaseRevisionId)
+
+		// Conflict markers are left, so the draft stays conflicted.
+		newContent := "a\n<<<<<<< draft\ndraft and page\n=======\npage\n>>>>>>> page\nc"
+		err = u.UpdateDraft(draftID, &newContent, nil)
+		require.NoError(t, err)
+		draft, _ = getTestDraft(t, storage, draftID)
+		require.Equal(t, api.Conflicted, draft.DraftDigest.Status)
+
+		newContent = "a\ndraft and page\nc"
+		err = u.UpdateDraft(draftID, &newContent, nil)
+		require.NoError(t, err)
+		draft, _ = getTestDraft(t, storage, draftID)
+		require.Equal(t, api.Active, draft.DraftDigest.Status)
+	})
+
+	t.Run("merged draft can not be rebased", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		_, draftID := createTestDraft(t, storage, "a", "b")
+		setTestDraftStatus(t, storage, draftID, api.Merged)
+
+		_, err := u.RebaseDraft(draftID)
+		require.ErrorIs(t, err, models.ErrDraftMerged)
+	})
+}
+
+func TestUpdateDraft(t *testing.T) {
+	t.Parallel()
+
+	t.Run("merged draft can not be updated", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		_, draftID := createTestDraft(t, storage, "a", "b")
+		setTestDraftStatus(t, storage, draftID, api.Merged)
+
+		newContent := "c"
+		err := u.UpdateDraft(draftID, &newContent, nil)
+		require.ErrorIs(t, err, models.ErrDraftMerged)
+		draft, _ := getTestDraft(t, storage, draftID)
+		require.Equal(t, "b", draft.Content)
+	})
+
+	t.Run("partially resolved conflict stays conflicted", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		_, draftID := createTestDraft(t, storage, "a", "b")
+		setTestDraftStatus(t, storage, draftID, api.Conflicted)
+
+		newContent := "draft and page\n=======\npage"
+		err := u.UpdateDraft(draftID, &newContent, nil)
+		require.NoError(t, err)
+		draft, _ := getTestDraft(t, storage, draftID)
+		require.Equal(t, api.Conflicted, draft.DraftDigest.Status)
+	})
+}
+
+func TestDiffDraft(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newMemoryStorageUsecase(t)
+	_, draftID := createTestDraft(t, storage, "# Title\n\nfirst line\nsecond line\n\nlast", "# Title\n\nfirst line\nchanged line\n\nlast\n\nnew")
+
+	result, err := u.DiffDraft(draftID)
+	require.NoError(t, err)
+	require.Equal(t, []api.DraftDiffHunk{
+		{
+			HunkIndex:             0,
+			BaseLineStart:         2,
+			BaseLines:             []string{"first line", "second line"},
+			DraftLineStart:        2,
+			DraftLines:            []string{"first line", "changed line"},
+			BaseParagraphIndexes:  []int{1},
+			DraftParagraphIndexes: []int{1},
+		},
+		{
+			HunkIndex:             1,
+			BaseLineStart:         6,
+			BaseLines:             []string{},
+			DraftLineStart:        6,
+			DraftLines:            []string{"", "new"},
+			BaseParagraphIndexes:  []int{},
+			DraftParagraphIndexes: []int{3},
+		},
+	}, result.Hunks)
+}
+
+func TestRejectDraftHunks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rejected hunk is reverted", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		_, draftID := createTestDraft(t, storage, "a\n\nb\n\nc", "A\n\nb\n\nC")
+		diffResult, err := u.DiffDraft(draftID)
+		require.NoError(t, err)
+		require.Len(t, diffResult.Hunks, 2)
+
+		result, err := u.RejectDraftHunks(draftID, diffResult.DraftVersion, []int{1})
+		require.NoError(t, err)
+		draft, _ := getTestDraft(t, storage, draftID)
+		require.Equal(t, "A\n\nb\n\nc", draft.Content)
+		require.Len(t, result.Hunks, 1)
+		require.NotEqual(t, diffResult.DraftVersion, result.DraftVersion)
+	})
+
+	t.Run("outdated version is refused", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		_, draftID := createTestDraft(t, storage, "a", "b")
+
+		_, err := u.RejectDraftHunks(draftID, "outdated", []int{0})
+		require.ErrorIs(t, err, models.ErrDraftChanged)
+		draft, _ := getTestDraft(t, storage, draftID)
+		require.Equal(t, "b", draft.Content)
+	})
+}
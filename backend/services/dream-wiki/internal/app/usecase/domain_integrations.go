@@ -1,10 +1,11 @@
 package usecase
 
 import (
-	"errors"
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
 
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
@@ -28,45 +29,23 @@ func (u *appUsecaseImpl) FetchPageFromYWiki(pageURL string) error {
 		return err
 	}
 
-	pageFromYWIki := api.Page{
-		Content: *pageResponse.Content,
-		Title:   pageResponse.Title,
-	}
-	pageFromRepository, err := repo.GetPageBySlug(slug)
-	if err != nil && !errors.Is(err, models.ErrNoRows) {
-		u.log.Errorf("Failed to get page by slug: %w", err)
+	pageID, err := repo.UpsertPage(slug, pageResponse.Title, *pageResponse.Content)
+	if err != nil {
+		u.log.Errorf("Failed to upsert page: %w", err)
 		return err
 	}
-	var pageID api.PageID
 
-	if errors.Is(err, models.ErrNoRows) {
-		newPageID, err := repo.CreatePage(slug, pageFromYWIki.Title, pageFromYWIki.Content)
-		if err != nil {
-			return err
-		}
-		pageID = *newPageID
-	} else {
-		pageID = pageFromRepository.PageId
-		if pageFromRepository.Content != pageFromYWIki.Content {
-			_, err := repo.AppendPageRevision(pageID, pageFromYWIki.Content)
-			if err != nil {
-				return err
-			}
-		}
-		if pageFromRepository.Title != pageFromYWIki.Title {
-			err := repo.SetPageTitle(pageID, pageFromYWIki.Title)
-			if err != nil {
-				return err
-			}
-		}
+	parentPageID, err := repo.GetClosestAncestorPageID(slug)
+	if err != nil {
+		return err
 	}
 
-	_, err = u.CreatePageReindexationTask([]api.PageID{pageID})
+	err = repo.SetPageParentID(*pageID, parentPageID)
 	if err != nil {
-		u.log.Errorf("Failed to indexate page: %w", err)
 		return err
 	}
 
+	// Page is reindexated by PageRevisionsReindexer if its content has changed.
 	return repo.Commit()
 }
 
@@ -117,6 +96,74 @@ func (u *appUsecaseImpl) GithubAccountPRAsync(prURL string) (*api.TaskID, error)
 	return taskID, nil
 }
 
-func (u *appUsecaseImpl) YwikiFetchAllAsync() (*api.TaskID, error) {
-	panic("unimplemented")
+// YwikiFetchAllAsync starts a task which mirrors the root page and all its
+// descendants from YWiki. Without root page all pages known to DreamWiki are
+// fetched again.
+func (u *appUsecaseImpl) YwikiFetchAllAsync(rootPageURL *string) (*api.TaskID, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	taskState := internals.TaskStateYWikiFetchAll{
+		TaskType:       internals.YwikiFetchAll,
+		FetchedPageIds: []api.PageID{},
+		SkippedPages:   []internals.YWikiFetchFailure{},
+	}
+
+	if rootPageURL != nil {
+		taskState.RootSlug = extractYWikiSlugFromURL(*rootPageURL)
+		taskState.PageSlugs = []string{taskState.RootSlug}
+	} else {
+		nodes, err := repo.GetPageTreeNodes()
+		if err != nil {
+			return nil, err
+		}
+		taskState.PageSlugs = make([]string, 0, len(nodes))
+		for _, node := range nodes {
+			taskState.PageSlugs = append(taskState.PageSlugs, node.YwikiSlug)
+		}
+		// Parents are fetched before children, as after listing of a subtree.
+		slices.SortFunc(taskState.PageSlugs, func(a, b string) int {
+			return cmp.Or(strings.Count(a, "/")-strings.Count(b, "/"), strings.Compare(a, b))
+		})
+		taskState.ListingFinished = true
+	}
+
+	logText := fmt.Sprintf("Fetching all pages under slug: %s", taskState.RootSlug)
+	if rootPageURL == nil {
+		logText = fmt.Sprintf("Fetching all %d known pages", len(taskState.PageSlugs))
+	}
+	err := repo.WriteIntegrationLogField("ywiki", logText)
+	if err != nil {
+		u.log.Errorf("Failed to write integration log: %v", err)
+	}
+
+	var taskStateUnion internals.TaskState
+	err = taskStateUnion.FromTaskStateYWikiFetchAll(taskState)
+	if err != nil {
+		return nil, err
+	}
+
+	taskID, err := repo.CreateTask(taskStateUnion)
+	if err != nil {
+		return nil, err
+	}
+
+	taskAction := internals.TaskAction{}
+	taskAction.FromTaskActionNewTask(internals.TaskActionNewTask{TaskActionType: internals.NewTask})
+	taskActionID, err := repo.CreateTaskAction(*taskID, taskAction)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.EnqueueTaskAction(*taskActionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return taskID, nil
 }
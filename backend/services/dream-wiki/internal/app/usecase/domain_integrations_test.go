@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func TestYwikiFetchAllAsync(t *testing.T) {
+	t.Parallel()
+	u, storage := newMemoryStorageUsecase(t)
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	for _, slug := range []string{"docs/a/b", "docs", "docs/a", "blog"} {
+		_, err := repo.CreatePage(slug, slug, "")
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.Commit())
+
+	rootPageURL := "https://wiki.yandex.ru/docs/a/"
+	taskID, err := u.YwikiFetchAllAsync(&rootPageURL)
+	require.NoError(t, err)
+	state := getYWikiFetchAllState(t, u, *taskID)
+	require.Equal(t, "docs/a", state.RootSlug)
+	require.Equal(t, []string{"docs/a"}, state.PageSlugs)
+	require.False(t, state.ListingFinished)
+
+	// Without root page every known page is fetched, parents first.
+	taskID, err = u.YwikiFetchAllAsync(nil)
+	require.NoError(t, err)
+	state = getYWikiFetchAllState(t, u, *taskID)
+	require.Empty(t, state.RootSlug)
+	require.Equal(t, []string{"blog", "docs", "docs/a", "docs/a/b"}, state.PageSlugs)
+	require.True(t, state.ListingFinished)
+}
+
+func getYWikiFetchAllState(t *testing.T, u *appUsecaseImpl, taskID api.TaskID) internals.TaskStateYWikiFetchAll {
+	t.Helper()
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+	_, taskState, err := repo.GetTaskByID(taskID)
+	require.NoError(t, err)
+	state, err := taskState.AsTaskStateYWikiFetchAll()
+	require.NoError(t, err)
+	return state
+}
@@ -1,6 +1,8 @@
 package usecase
 
 import (
+	"time"
+
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 )
 
@@ -14,3 +16,53 @@ func (u *appUsecaseImpl) IndexatePage(pageID api.PageID) (*api.V1IndexatePageRes
 		PageId: pageID,
 	}, nil
 }
+
+// DeferPageReindexation remembers write of the page. Pending pages are stored,
+// so they are reindexated even if the service restarts meanwhile.
+func (u *appUsecaseImpl) DeferPageReindexation(pageID api.PageID, writtenAt time.Time) error {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	err := repo.AddPendingReindexationPage(pageID, writtenAt)
+	if err != nil {
+		return err
+	}
+
+	return repo.Commit()
+}
+
+// ScheduleDuePagesReindexation creates one reindexation task for pending pages
+// which have not been written since lastWrittenBefore or are pending since
+// firstWrittenBefore. Pages stop being pending in the same transaction. Task ID
+// is nil if no page is due.
+func (u *appUsecaseImpl) ScheduleDuePagesReindexation(lastWrittenBefore time.Time, firstWrittenBefore time.Time) (*api.TaskID, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	pageIDs, err := repo.GetDuePendingReindexationPages(lastWrittenBefore, firstWrittenBefore)
+	if err != nil {
+		return nil, err
+	}
+	if len(pageIDs) == 0 {
+		return nil, nil
+	}
+
+	taskID, err := createPageReindexationTask(repo, pageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.RemovePendingReindexationPages(pageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	u.log.Info("Created reindexation task ", *taskID, " for ", len(pageIDs), " written pages")
+
+	return taskID, nil
+}
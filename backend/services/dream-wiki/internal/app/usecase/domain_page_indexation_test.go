@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+// scheduleDuePages schedules pages due at now with the given debounce delays and
+// returns pages of the created task.
+func scheduleDuePages(t *testing.T, u *appUsecaseImpl, storage repository.Storage, now time.Time, delay time.Duration, maxDelay time.Duration) []api.PageID {
+	t.Helper()
+
+	taskID, err := u.ScheduleDuePagesReindexation(now.Add(-delay), now.Add(-maxDelay))
+	require.NoError(t, err)
+	if taskID == nil {
+		return nil
+	}
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	_, taskState, err := repo.GetTaskByID(*taskID)
+	require.NoError(t, err)
+	state, err := taskState.AsTaskStateReindexatePages()
+	require.NoError(t, err)
+	return state.PagesToIndexateIds
+}
+
+func TestScheduleDuePagesReindexationWaitsForPauseInWrites(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newMemoryStorageUsecase(t)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := uuid.New()
+	second := uuid.New()
+
+	require.NoError(t, u.DeferPageReindexation(first, start))
+	require.NoError(t, u.DeferPageReindexation(second, start.Add(2*time.Second)))
+	require.NoError(t, u.DeferPageReindexation(first, start.Add(5*time.Second)))
+
+	require.Empty(t, scheduleDuePages(t, u, storage, start.Add(9*time.Second), 10*time.Second, time.Minute))
+	require.Equal(t, []api.PageID{second}, scheduleDuePages(t, u, storage, start.Add(12*time.Second), 10*time.Second, time.Minute))
+	require.Equal(t, []api.PageID{first}, scheduleDuePages(t, u, storage, start.Add(15*time.Second), 10*time.Second, time.Minute))
+	require.Empty(t, scheduleDuePages(t, u, storage, start.Add(time.Hour), 10*time.Second, time.Minute))
+}
+
+func TestScheduleDuePagesReindexationReleasesContinuouslyWrittenPage(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newMemoryStorageUsecase(t)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pageID := uuid.New()
+
+	for elapsed := time.Duration(0); elapsed < 30*time.Second; elapsed += 5 * time.Second {
+		require.NoError(t, u.DeferPageReindexation(pageID, start.Add(elapsed)))
+		require.Empty(t, scheduleDuePages(t, u, storage, start.Add(elapsed), 10*time.Second, 30*time.Second))
+	}
+	require.Equal(t, []api.PageID{pageID}, scheduleDuePages(t, u, storage, start.Add(30*time.Second), 10*time.Second, 30*time.Second))
+}
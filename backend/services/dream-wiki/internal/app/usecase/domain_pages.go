@@ -1,28 +1,115 @@
 package usecase
 
 import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/ywiki_slug"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
 
+// GetPagesTree returns top level pages, ancestors of active pages and active pages
+// expanded. Children of collapsed pages are not returned, the client requests them
+// by adding the collapsed page to active pages.
 func (u *appUsecaseImpl) GetPagesTree(activePagesIDs []api.PageID) ([]api.TreeItem, error) {
 	repo := u.createReadOnlyRepository()
 	defer repo.Rollback()
 
-	items, err := repo.GetAllPageDigests()
+	nodes, err := repo.GetPageTreeNodes()
 	if err != nil {
 		return nil, err
 	}
 
-	result := make([]api.TreeItem, 0, len(items))
-	for _, item := range items {
-		result = append(result, api.TreeItem{
-			PageDigest: item,
-			Children:   nil,
-			Expanded:   false,
+	return buildPagesTree(nodes, activePagesIDs), nil
+}
+
+// resolvePageParents maps every page to its parent. Parent link is taken from
+// parent_page_id, and pages without it are attached to the closest ancestor by slug.
+// Pages whose parent links form a loop are moved to the top level.
+func resolvePageParents(nodes []internals.PageTreeNode) map[api.PageID]api.PageID {
+	pageIDBySlug := make(map[string]api.PageID, len(nodes))
+	knownPages := make(map[api.PageID]bool, len(nodes))
+	for _, node := range nodes {
+		pageIDBySlug[strings.Trim(node.YwikiSlug, "/")] = node.PageId
+		knownPages[node.PageId] = true
+	}
+
+	parents := make(map[api.PageID]api.PageID)
+	for _, node := range nodes {
+		if node.ParentPageId != nil && knownPages[*node.ParentPageId] && *node.ParentPageId != node.PageId {
+			parents[node.PageId] = *node.ParentPageId
+			continue
+		}
+		for _, ancestorSlug := range ywiki_slug.AncestorSlugs(node.YwikiSlug) {
+			if parentID, ok := pageIDBySlug[ancestorSlug]; ok {
+				parents[node.PageId] = parentID
+				break
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		visited := map[api.PageID]bool{node.PageId: true}
+		for current, ok := parents[node.PageId]; ok; current, ok = parents[current] {
+			if visited[current] {
+				if current == node.PageId {
+					delete(parents, node.PageId)
+				}
+				break
+			}
+			visited[current] = true
+		}
+	}
+
+	return parents
+}
+
+func buildPagesTree(nodes []internals.PageTreeNode, activePagesIDs []api.PageID) []api.TreeItem {
+	parents := resolvePageParents(nodes)
+
+	children := make(map[api.PageID][]internals.PageTreeNode)
+	roots := make([]internals.PageTreeNode, 0)
+	for _, node := range nodes {
+		parentID, ok := parents[node.PageId]
+		if !ok {
+			roots = append(roots, node)
+			continue
+		}
+		children[parentID] = append(children[parentID], node)
+	}
+
+	expanded := make(map[api.PageID]bool)
+	for _, pageID := range activePagesIDs {
+		for current, ok := pageID, true; ok && !expanded[current]; current, ok = parents[current] {
+			expanded[current] = true
+		}
+	}
+
+	var makeItems func(nodes []internals.PageTreeNode) []api.TreeItem
+	makeItems = func(nodes []internals.PageTreeNode) []api.TreeItem {
+		slices.SortFunc(nodes, func(a, b internals.PageTreeNode) int {
+			return strings.Compare(a.Title, b.Title)
 		})
+
+		items := make([]api.TreeItem, 0, len(nodes))
+		for _, node := range nodes {
+			item := api.TreeItem{
+				PageDigest:  api.PageDigest{PageId: node.PageId, Title: node.Title},
+				HasChildren: len(children[node.PageId]) > 0,
+				Expanded:    expanded[node.PageId],
+			}
+			if item.HasChildren && item.Expanded {
+				childItems := makeItems(children[node.PageId])
+				item.Children = &childItems
+			}
+			items = append(items, item)
+		}
+		return items
 	}
 
-	return result, nil
+	return makeItems(roots)
 }
 
 func (u *appUsecaseImpl) GetDiagnosticInfo(req api.V1DiagnosticInfoGetRequest) (*api.V1DiagnosticInfoGetResponse, error) {
@@ -35,6 +122,34 @@ func (u *appUsecaseImpl) GetDiagnosticInfo(req api.V1DiagnosticInfoGetRequest) (
 	}
 
 	return &api.V1DiagnosticInfoGetResponse{
-		Page: *page,
+		Page:        *page,
+		VectorIndex: u.vectorIndexDiagnostics(),
 	}, nil
 }
+
+func (u *appUsecaseImpl) vectorIndexDiagnostics() *api.VectorIndexDiagnostics {
+	if u.deps.VectorIndex == nil {
+		return nil
+	}
+	diagnostics := u.deps.VectorIndex.Diagnostics()
+
+	result := &api.VectorIndexDiagnostics{
+		Ready:                             diagnostics.Ready,
+		EntriesCount:                      diagnostics.EntriesCount,
+		ClustersCount:                     diagnostics.ClustersCount,
+		Probes:                            diagnostics.Probes,
+		MinEntries:                        diagnostics.MinEntries,
+		BuiltAt:                           diagnostics.BuiltAt,
+		Recall:                            diagnostics.Recall,
+		FallbacksCount:                    diagnostics.FallbacksCount,
+		ApproximateSearchesCount:          diagnostics.ApproximateSearchesCount,
+		ApproximateSearchAverageLatencyMs: float64(diagnostics.ApproximateSearchAverageLatency) / float64(time.Millisecond),
+		ExactSearchesCount:                diagnostics.ExactSearchesCount,
+		ExactSearchAverageLatencyMs:       float64(diagnostics.ExactSearchAverageLatency) / float64(time.Millisecond),
+	}
+	if diagnostics.Ready {
+		buildDurationMs := diagnostics.BuildDuration.Milliseconds()
+		result.BuildDurationMs = &buildDurationMs
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func TestBuildPagesTree(t *testing.T) {
+	t.Parallel()
+
+	root := api.PageID{1}
+	child := api.PageID{2}
+	grandchild := api.PageID{3}
+	sibling := api.PageID{4}
+	other := api.PageID{5}
+
+	nodes := []internals.PageTreeNode{
+		{PageId: grandchild, Title: "C", YwikiSlug: "root/child/grandchild"},
+		{PageId: sibling, Title: "B", YwikiSlug: "root/sibling", ParentPageId: &root},
+		{PageId: child, Title: "A", YwikiSlug: "root/child", ParentPageId: &root},
+		{PageId: root, Title: "Root", YwikiSlug: "root"},
+		{PageId: other, Title: "Other", YwikiSlug: "other"},
+	}
+
+	t.Run("without active pages only top level is returned", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildPagesTree(nodes, nil)
+		require.Len(t, tree, 2)
+		require.Equal(t, other, tree[0].PageDigest.PageId)
+		require.False(t, tree[0].HasChildren)
+		require.Nil(t, tree[0].Children)
+		require.Equal(t, root, tree[1].PageDigest.PageId)
+		require.True(t, tree[1].HasChildren)
+		require.False(t, tree[1].Expanded)
+		require.Nil(t, tree[1].Children)
+	})
+
+	t.Run("ancestors of active page are expanded", func(t *testing.T) {
+		t.Parallel()
+
+		tree := buildPagesTree(nodes, []api.PageID{grandchild})
+		rootItem := tree[1]
+		require.True(t, rootItem.Expanded)
+		require.NotNil(t, rootItem.Children)
+
+		rootChildren := *rootItem.Children
+		require.Len(t, rootChildren, 2)
+		require.Equal(t, child, rootChildren[0].PageDigest.PageId)
+		require.True(t, rootChildren[0].Expanded)
+		require.Equal(t, sibling, rootChildren[1].PageDigest.PageId)
+		require.False(t, rootChildren[1].Expanded)
+
+		childChildren := *rootChildren[0].Children
+		require.Len(t, childChildren, 1)
+		require.Equal(t, grandchild, childChildren[0].PageDigest.PageId)
+		require.False(t, childChildren[0].HasChildren)
+	})
+
+	t.Run("loop in parent links does not hide pages", func(t *testing.T) {
+		t.Parallel()
+
+		loopNodes := []internals.PageTreeNode{
+			{PageId: root, Title: "A", YwikiSlug: "a", ParentPageId: &child},
+			{PageId: child, Title: "B", YwikiSlug: "b", ParentPageId: &root},
+		}
+
+		tree := buildPagesTree(loopNodes, []api.PageID{root, child})
+		require.Len(t, tree, 1)
+		require.True(t, tree[0].Expanded)
+		require.Len(t, *tree[0].Children, 1)
+	})
+}
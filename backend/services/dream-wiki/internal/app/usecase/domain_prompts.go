@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/prompts"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func promptTemplateToAPI(template prompts.Template) api.PromptTemplate {
+	return api.PromptTemplate{
+		Name:         template.Name,
+		Description:  template.Description,
+		Version:      template.Version,
+		Overridden:   template.Overridden,
+		CreatedAt:    template.CreatedAt,
+		Variables:    slices.Clone(template.Variables),
+		SystemPrompt: template.SystemPrompt,
+		UserPrompt:   template.UserPrompt,
+		Temperature:  template.Temperature,
+		MaxTokens:    template.MaxTokens,
+	}
+}
+
+// ListPromptTemplates returns active version of every template.
+func (u *appUsecaseImpl) ListPromptTemplates() ([]api.PromptTemplate, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	templates := make([]api.PromptTemplate, 0)
+	for _, builtin := range prompts.Builtins() {
+		template, err := prompts.Active(repo, builtin.Name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, promptTemplateToAPI(template))
+	}
+	return templates, nil
+}
+
+// ListPromptTemplateVersions returns overrides and the built-in version of
+// the template from the newest one.
+func (u *appUsecaseImpl) ListPromptTemplateVersions(name string) ([]api.PromptTemplate, error) {
+	builtin, ok := prompts.Builtin(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", models.ErrNoPromptTemplate, name)
+	}
+
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	overrides, err := repo.ListPromptTemplateOverrides(name)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]api.PromptTemplate, 0, len(overrides)+1)
+	builtinAdded := false
+	for _, override := range overrides {
+		if !builtinAdded && builtin.Version >= override.Version {
+			templates = append(templates, promptTemplateToAPI(builtin))
+			builtinAdded = true
+		}
+		templates = append(templates, promptTemplateToAPI(builtin.WithOverride(override)))
+	}
+	if !builtinAdded {
+		templates = append(templates, promptTemplateToAPI(builtin))
+	}
+	return templates, nil
+}
+
+// OverridePromptTemplate stores the next version of the template. Model
+// options not set in the request are taken from the active version.
+func (u *appUsecaseImpl) OverridePromptTemplate(req api.V1PromptsOverrideRequest) (*api.PromptTemplate, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	active, err := prompts.Active(repo, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	override := internals.PromptTemplateOverride{
+		Name:         req.Name,
+		Version:      active.Version + 1,
+		SystemPrompt: req.SystemPrompt,
+		UserPrompt:   req.UserPrompt,
+		Temperature:  active.Temperature,
+		MaxTokens:    active.MaxTokens,
+	}
+	if req.Temperature != nil {
+		override.Temperature = *req.Temperature
+	}
+	if req.MaxTokens != nil {
+		override.MaxTokens = *req.MaxTokens
+	}
+	if err := active.WithOverride(override).Validate(); err != nil {
+		return nil, err
+	}
+
+	created, err := repo.CreatePromptTemplateOverride(override)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Commit(); err != nil {
+		return nil, err
+	}
+
+	u.log.Info("prompt template ", created.Name, " overridden with version ", created.Version)
+
+	template := promptTemplateToAPI(active.WithOverride(*created))
+	return &template, nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/prompts"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+func TestOverridePromptTemplate(t *testing.T) {
+	t.Parallel()
+	u, _ := newMemoryStorageUsecase(t)
+
+	_, err := u.OverridePromptTemplate(api.V1PromptsOverrideRequest{Name: "unknown", UserPrompt: "text"})
+	require.ErrorIs(t, err, models.ErrNoPromptTemplate)
+
+	_, err = u.OverridePromptTemplate(api.V1PromptsOverrideRequest{
+		Name:       prompts.GitHubPRSearchQueries,
+		UserPrompt: "Запросы для {{.pr_patch}}",
+	})
+	require.ErrorIs(t, err, models.ErrBadPromptTemplate)
+
+	builtin, _ := prompts.Builtin(prompts.GitHubPRSearchQueries)
+	maxTokens := 100
+	template, err := u.OverridePromptTemplate(api.V1PromptsOverrideRequest{
+		Name:       prompts.GitHubPRSearchQueries,
+		UserPrompt: "Запросы для {{.product_changes}}",
+		MaxTokens:  &maxTokens,
+	})
+	require.NoError(t, err)
+	require.Equal(t, builtin.Version+1, template.Version)
+	require.True(t, template.Overridden)
+	require.NotNil(t, template.CreatedAt)
+	require.Equal(t, builtin.Temperature, template.Temperature)
+	require.Equal(t, 100, template.MaxTokens)
+
+	templates, err := u.ListPromptTemplates()
+	require.NoError(t, err)
+	require.Len(t, templates, len(prompts.Builtins()))
+	for _, listed := range templates {
+		require.Equal(t, listed.Name == prompts.GitHubPRSearchQueries, listed.Overridden, listed.Name)
+	}
+
+	// The next override is based on the overridden version.
+	template, err = u.OverridePromptTemplate(api.V1PromptsOverrideRequest{
+		Name:       prompts.GitHubPRSearchQueries,
+		UserPrompt: "Поисковые запросы для {{.product_changes}}",
+	})
+	require.NoError(t, err)
+	require.Equal(t, builtin.Version+2, template.Version)
+	require.Equal(t, 100, template.MaxTokens)
+
+	versions, err := u.ListPromptTemplateVersions(prompts.GitHubPRSearchQueries)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	require.Equal(t, builtin.Version+2, versions[0].Version)
+	require.Equal(t, builtin.Version+1, versions[1].Version)
+	require.False(t, versions[2].Overridden)
+	require.Equal(t, builtin.UserPrompt, versions[2].UserPrompt)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"fmt"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/diff"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+func (u *appUsecaseImpl) ListPageRevisions(pageID api.PageID, cursor *api.Cursor) ([]api.RevisionDigest, *api.NextInfo, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	revisions, nextInfo, err := repo.ListPageRevisions(pageID, cursor, 50)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return revisions, nextInfo, nil
+}
+
+func (u *appUsecaseImpl) GetRevision(revisionID api.RevisionID) (*api.Revision, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	return repo.GetRevisionByID(revisionID)
+}
+
+func (u *appUsecaseImpl) DiffRevisions(oldRevisionID api.RevisionID, newRevisionID api.RevisionID, mode api.DiffMode, contextLines int) (*api.V1RevisionsDiffResponse, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	oldRevision, err := repo.GetRevisionByID(oldRevisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	newRevision, err := repo.GetRevisionByID(newRevisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldRevision.RevisionDigest.PageId != newRevision.RevisionDigest.PageId {
+		return nil, models.ErrRevisionsMismatch
+	}
+
+	switch mode {
+	case api.Unified:
+		unifiedDiff := diff.Unified(
+			fmt.Sprintf("revision %d", oldRevisionID),
+			fmt.Sprintf("revision %d", newRevisionID),
+			diff.SplitLines(oldRevision.Content),
+			diff.SplitLines(newRevision.Content),
+			max(contextLines, 0),
+		)
+		return &api.V1RevisionsDiffResponse{UnifiedDiff: &unifiedDiff}, nil
+
+	case api.Words:
+		chunks := diff.Words(oldRevision.Content, newRevision.Content)
+		wordChunks := make([]api.WordDiffChunk, 0, len(chunks))
+		for _, chunk := range chunks {
+			wordChunks = append(wordChunks, api.WordDiffChunk{
+				Kind: wordChunkKind(chunk.Kind),
+				Text: chunk.Text,
+			})
+		}
+		return &api.V1RevisionsDiffResponse{WordChunks: &wordChunks}, nil
+	}
+
+	return nil, fmt.Errorf("unknown diff mode: %s", mode)
+}
+
+func wordChunkKind(kind diff.ChunkKind) api.WordDiffChunkKind {
+	switch kind {
+	case diff.ChunkDeleted:
+		return api.Deleted
+	case diff.ChunkInserted:
+		return api.Inserted
+	}
+	return api.Equal
+}
+
+// RevertPageToRevision appends a new page revision with content of the given one.
+// Drafts of the page have to be rebased after that, as after any other change.
+func (u *appUsecaseImpl) RevertPageToRevision(revisionID api.RevisionID) (*api.RevisionDigest, error) {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	revision, err := repo.GetRevisionByID(revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	pageID := revision.RevisionDigest.PageId
+	_, pageAdditionalInfo, err := repo.GetPageByID(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageAdditionalInfo.CurrentRevisionId != nil && *pageAdditionalInfo.CurrentRevisionId == revisionID {
+		return nil, models.ErrRevisionIsCurrent
+	}
+
+	newRevisionID, err := repo.AppendPageRevision(pageID, revision.Content, api.Revert, u.currentUsername())
+	if err != nil {
+		return nil, err
+	}
+
+	newRevision, err := repo.GetRevisionByID(*newRevisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &newRevision.RevisionDigest, nil
+}
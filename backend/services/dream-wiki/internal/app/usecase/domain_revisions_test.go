@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func TestRevertPageToRevision(t *testing.T) {
+	t.Parallel()
+
+	t.Run("old revision content becomes current", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		pageID, _ := createTestDraft(t, storage, "a", "a")
+		revisionIDs := []internals.RevisionID{getTestPageRevisionID(t, storage, pageID)}
+		revisionIDs = append(revisionIDs, appendTestPageRevision(t, storage, pageID, "b"))
+
+		revision, err := u.RevertPageToRevision(revisionIDs[0])
+		require.NoError(t, err)
+		require.Equal(t, getTestPageRevisionID(t, storage, pageID), revision.RevisionId)
+		require.NotContains(t, revisionIDs, revision.RevisionId)
+		require.Equal(t, api.Revert, revision.Source)
+		require.Equal(t, []string{"a", "b", "a"}, getTestPageContents(t, storage, pageID))
+	})
+
+	t.Run("current revision is not reverted", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		pageID, _ := createTestDraft(t, storage, "a", "a")
+
+		_, err := u.RevertPageToRevision(getTestPageRevisionID(t, storage, pageID))
+		require.ErrorIs(t, err, models.ErrRevisionIsCurrent)
+		require.Len(t, getTestPageContents(t, storage, pageID), 1)
+	})
+}
+
+func TestDiffRevisions(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newMemoryStorageUsecase(t)
+	pageID, _ := createTestDraft(t, storage, "a\nb\nc", "a")
+	baseRevisionID := getTestPageRevisionID(t, storage, pageID)
+	revisionID := appendTestPageRevision(t, storage, pageID, "a\nB\nc")
+
+	result, err := u.DiffRevisions(baseRevisionID, revisionID, api.Unified, 0)
+	require.NoError(t, err)
+	require.Nil(t, result.WordChunks)
+	require.Equal(t, fmt.Sprintf("--- revision %d\n+++ revision %d\n@@ -2 +2 @@\n-b\n+B\n", baseRevisionID, revisionID), *result.UnifiedDiff)
+
+	result, err = u.DiffRevisions(baseRevisionID, revisionID, api.Words, 0)
+	require.NoError(t, err)
+	require.Nil(t, result.UnifiedDiff)
+	require.Equal(t, []api.WordDiffChunk{
+		{Kind: api.Equal, Text: "a\n"},
+		{Kind: api.Deleted, Text: "b"},
+		{Kind: api.Inserted, Text: "B"},
+		{Kind: api.Equal, Text: "\nc"},
+	}, *result.WordChunks)
+}
+
+func TestDiffRevisionsOfDifferentPages(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newMemoryStorageUsecase(t)
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	firstPageID, err := repo.CreatePage("first", "First", "a")
+	require.NoError(t, err)
+	secondPageID, err := repo.CreatePage("second", "Second", "b")
+	require.NoError(t, err)
+	_, firstPageInfo, err := repo.GetPageByID(*firstPageID)
+	require.NoError(t, err)
+	_, secondPageInfo, err := repo.GetPageByID(*secondPageID)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	_, err = u.DiffRevisions(*firstPageInfo.CurrentRevisionId, *secondPageInfo.CurrentRevisionId, api.Unified, 0)
+	require.ErrorIs(t, err, models.ErrBadRequest)
+
+	_, err = u.DiffRevisions(*firstPageInfo.CurrentRevisionId, 1000, api.Unified, 0)
+	require.ErrorIs(t, err, models.ErrNotFound)
+}
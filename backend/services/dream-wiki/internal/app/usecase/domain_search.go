@@ -2,63 +2,317 @@ package usecase
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/ranking"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/searchquery"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
 
-func (u *appUsecaseImpl) Search(req api.V1SearchRequest) (*api.V1SearchResponse, error) {
-	embedding, err := u.deps.InferenceClient.GenerateEmbedding(u.ctx, req.Query)
+const (
+	searchDefaultLimit = 10
+	searchMaxLimit     = 50
+	// searchMaxCandidates is number of results taken from every retriever for
+	// every page of results. Fused ranking depends on candidates, so a window of
+	// the same size keeps pages consistent with each other. Pagination stops there.
+	searchMaxCandidates = 200
+
+	termsRetriever     = "terms"
+	embeddingRetriever = "embedding"
+)
+
+// Search cursor is an offset in the fused results list.
+func decodeSearchCursor(cursor *api.Cursor) int {
+	if cursor == nil {
+		return 0
+	}
+	offset, err := strconv.Atoi(*cursor)
+	if err != nil || offset < 0 {
+		return 0
+	}
+	return offset
+}
+
+func searchResultKey(item internals.SearchResultItem) string {
+	return fmt.Sprintf("%s-%d", item.PageId, item.ParagraphIndex)
+}
+
+// subtreePageIDs returns pages which are roots themselves or have a root among
+// their ancestors.
+func subtreePageIDs(nodes []internals.PageTreeNode, isRoot func(node internals.PageTreeNode) bool) map[api.PageID]bool {
+	roots := make(map[api.PageID]bool)
+	for _, node := range nodes {
+		if isRoot(node) {
+			roots[node.PageId] = true
+		}
+	}
+
+	parents := resolvePageParents(nodes)
+	subtree := make(map[api.PageID]bool)
+	for _, node := range nodes {
+		for current, ok := node.PageId, true; ok; current, ok = parents[current] {
+			if roots[current] {
+				subtree[node.PageId] = true
+				break
+			}
+		}
+	}
+	return subtree
+}
+
+func intersectPageIDs(pageIDs []api.PageID, allowed map[api.PageID]bool) []api.PageID {
+	intersection := make([]api.PageID, 0, len(pageIDs))
+	for _, pageID := range pageIDs {
+		if allowed[pageID] {
+			intersection = append(intersection, pageID)
+		}
+	}
+	return intersection
+}
+
+// resolveSearchFilters turns subtree filters into a list of page IDs. Nil
+// PageIds in the result means that pages are not filtered.
+func resolveSearchFilters(repo repository.AppRepository, filters *api.SearchFilters) (*internals.SearchFilters, error) {
+	resolved := &internals.SearchFilters{}
+	if filters == nil {
+		return resolved, nil
+	}
+	if filters.IncludeHeaders != nil {
+		resolved.IncludeHeaders = *filters.IncludeHeaders
+	}
+	if filters.HeaderPath != nil && len(*filters.HeaderPath) > 0 {
+		resolved.HeaderPath = filters.HeaderPath
+	}
+
+	var pageIDs []api.PageID
+	if filters.PageIds != nil {
+		pageIDs = *filters.PageIds
+	}
+
+	if filters.SubtreeSlug != nil || filters.SubtreePageId != nil {
+		nodes, err := repo.GetPageTreeNodes()
+		if err != nil {
+			return nil, err
+		}
+		if pageIDs == nil {
+			pageIDs = make([]api.PageID, 0, len(nodes))
+			for _, node := range nodes {
+				pageIDs = append(pageIDs, node.PageId)
+			}
+		}
+
+		if filters.SubtreeSlug != nil {
+			prefix := strings.Trim(*filters.SubtreeSlug, "/")
+			pageIDs = intersectPageIDs(pageIDs, subtreePageIDs(nodes, func(node internals.PageTreeNode) bool {
+				slug := strings.Trim(node.YwikiSlug, "/")
+				return prefix == "" || slug == prefix || strings.HasPrefix(slug, prefix+"/")
+			}))
+		}
+		if filters.SubtreePageId != nil {
+			rootID := *filters.SubtreePageId
+			pageIDs = intersectPageIDs(pageIDs, subtreePageIDs(nodes, func(node internals.PageTreeNode) bool {
+				return node.PageId == rootID
+			}))
+		}
+	}
+
+	if pageIDs != nil {
+		resolved.PageIds = &pageIDs
+	}
+	return resolved, nil
+}
+
+// searchTerms turns query into indexed terms the way paragraphs are turned
+// into them on indexation. Phrases synonymous to the query are added, and
+// stems absent from the index are replaced with the closest indexed terms, so
+// that misspelled words still match.
+func (u *appUsecaseImpl) searchTerms(repo repository.AppRepository, query string) ([]string, error) {
+	synonymGroups, err := repo.ListSynonymGroups()
+	if err != nil {
+		return nil, err
+	}
+	groups := make([][]string, 0, len(synonymGroups))
+	for _, group := range synonymGroups {
+		groups = append(groups, group.Phrases)
+	}
+	texts := append([]string{query}, searchquery.SynonymExpansions(query, groups)...)
+
+	stems, err := u.deps.InferenceClient.GenerateStems(u.ctx, texts)
 	if err != nil {
 		return nil, err
 	}
+
+	terms := make([]string, 0)
+	added := make(map[string]bool)
+	// Vocabularies are loaded by the first letter, typos in it are not corrected.
+	vocabularies := make(map[string][]string)
+	for _, textStems := range stems {
+		for _, stem := range textStems {
+			corrections, err := correctSearchTerm(repo, stem, vocabularies)
+			if err != nil {
+				return nil, err
+			}
+			for _, term := range corrections {
+				if !added[term] {
+					added[term] = true
+					terms = append(terms, term)
+				}
+			}
+		}
+	}
+	return terms, nil
+}
+
+// correctSearchTerm returns term itself if it is indexed or can not be
+// corrected, and the closest indexed terms otherwise.
+func correctSearchTerm(repo repository.AppRepository, term string, vocabularies map[string][]string) ([]string, error) {
+	if searchquery.MaxTypoDistance(term) == 0 {
+		return []string{term}, nil
+	}
+
+	firstLetter, _ := utf8.DecodeRuneInString(term)
+	prefix := string(firstLetter)
+	vocabulary, loaded := vocabularies[prefix]
+	if !loaded {
+		var err error
+		vocabulary, err = repo.GetTermVocabulary(prefix)
+		if err != nil {
+			return nil, err
+		}
+		vocabularies[prefix] = vocabulary
+	}
+
+	corrections := searchquery.CorrectTypo(term, vocabulary)
+	if len(corrections) == 0 {
+		return []string{term}, nil
+	}
+	return corrections, nil
+}
+
+// Search runs terms and embedding searches and merges their results with weighted
+// reciprocal rank fusion. Weights and RRF constant come from config.
+func (u *appUsecaseImpl) Search(req api.V1SearchRequest) (*api.V1SearchResponse, error) {
+	limit := searchDefaultLimit
+	if req.Limit != nil {
+		limit = min(max(*req.Limit, 1), searchMaxLimit)
+	}
+	offset := decodeSearchCursor(req.Cursor)
+
+	if offset >= searchMaxCandidates {
+		return &api.V1SearchResponse{
+			ResultItems: []api.SearchResultItem{},
+			NextInfo:    api.NextInfo{Cursor: strconv.Itoa(offset), HasMore: false},
+		}, nil
+	}
+
 	repo := u.createReadOnlyRepository()
 	defer repo.Rollback()
 
-	embeddingResults, err := repo.SearchByEmbedding(req.Query, internals.Embedding(embedding), 5)
+	filters, err := resolveSearchFilters(repo, req.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if filters.PageIds != nil && len(*filters.PageIds) == 0 {
+		return &api.V1SearchResponse{
+			ResultItems: []api.SearchResultItem{},
+			NextInfo:    api.NextInfo{Cursor: strconv.Itoa(offset), HasMore: false},
+		}, nil
+	}
+
+	embedding, err := u.deps.InferenceClient.GenerateEmbedding(u.ctx, req.Query)
 	if err != nil {
 		return nil, err
 	}
 
-	terms := strings.Fields(req.Query)
-	termResults, err := repo.SearchByTerms(terms, 5)
+	embeddingResults, err := repo.SearchByEmbedding(req.Query, internals.Embedding(embedding), *filters, searchMaxCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	terms, err := u.searchTerms(repo, req.Query)
+	if err != nil {
+		return nil, err
+	}
+	termResults, err := repo.SearchByTerms(terms, *filters, searchMaxCandidates)
 	if err != nil {
 		return nil, err
 	}
 
 	u.log.Info("Search: ", len(termResults), " term results, ", len(embeddingResults), " embedding results")
 
-	results := append(termResults, embeddingResults...)
+	return u.fuseSearchResults(termResults, embeddingResults, offset, limit), nil
+}
 
-	seen := make(map[string]bool)
-	uniqueResults := make([]internals.SearchResultItem, 0)
-	for _, result := range results {
-		key := fmt.Sprintf("%s-%d", result.PageId, result.ParagraphIndex)
-		if !seen[key] {
-			seen[key] = true
-			uniqueResults = append(uniqueResults, result)
+func (u *appUsecaseImpl) fuseSearchResults(termResults []internals.SearchResultItem, embeddingResults []internals.SearchResultItem, offset int, limit int) *api.V1SearchResponse {
+	items := make(map[string]internals.SearchResultItem)
+	rankedLists := []ranking.RankedList{
+		{Retriever: termsRetriever, Weight: u.deps.Config.SearchTermsWeight},
+		{Retriever: embeddingRetriever, Weight: u.deps.Config.SearchEmbeddingWeight},
+	}
+	termResultsByKey := make(map[string]internals.SearchResultItem)
+	embeddingResultsByKey := make(map[string]internals.SearchResultItem)
+	for _, result := range termResults {
+		key := searchResultKey(result)
+		items[key] = result
+		termResultsByKey[key] = result
+		rankedLists[0].Keys = append(rankedLists[0].Keys, key)
+	}
+	for _, result := range embeddingResults {
+		key := searchResultKey(result)
+		if _, exists := items[key]; !exists {
+			items[key] = result
 		}
+		embeddingResultsByKey[key] = result
+		rankedLists[1].Keys = append(rankedLists[1].Keys, key)
 	}
 
-	apiResults := make([]api.SearchResultItem, len(uniqueResults))
-	for i, result := range uniqueResults {
-		apiResults[i] = api.SearchResultItem{
+	fused := ranking.ReciprocalRankFusion(u.deps.Config.SearchRRFK, rankedLists...)
+
+	pageEnd := min(offset+limit, len(fused))
+	apiResults := make([]api.SearchResultItem, 0, max(pageEnd-offset, 0))
+	for i := offset; i < pageEnd; i++ {
+		fusedItem := fused[i]
+		result := items[fusedItem.Key]
+
+		explanation := api.SearchMatchExplanation{}
+		if rank, found := fusedItem.Ranks[termsRetriever]; found {
+			termResult := termResultsByKey[fusedItem.Key]
+			explanation.TermsRank = &rank
+			explanation.TermsScore = &termResult.Score
+			explanation.MatchedTerms = termResult.MatchedTerms
+		}
+		if rank, found := fusedItem.Ranks[embeddingRetriever]; found {
+			embeddingResult := embeddingResultsByKey[fusedItem.Key]
+			explanation.EmbeddingRank = &rank
+			explanation.EmbeddingDistance = &embeddingResult.Score
+		}
+
+		apiResults = append(apiResults, api.SearchResultItem{
 			PageId:          result.PageId,
 			Title:           result.PageTitle,
 			Snippet:         result.ParagraphContent,
 			LineIndex:       result.LineIndex,
 			YwikiAnchorLink: createYWikiAnchorLink(result.AnchorSlug, result.PageSlug),
-		}
+			Score:           fusedItem.Score,
+			Explanation:     explanation,
+		})
 	}
 
 	return &api.V1SearchResponse{
 		ResultItems: apiResults,
-	}, nil
+		NextInfo: api.NextInfo{
+			Cursor:  strconv.Itoa(max(pageEnd, offset)),
+			HasMore: pageEnd < len(fused),
+		},
+	}
 }
 
 func createYWikiAnchorLink(anchorSlug *string, pageSlug string) string {
-	if anchorSlug == nil {
+	if anchorSlug == nil || *anchorSlug == "" {
 		return fmt.Sprintf("https://wiki.yandex.ru/%s", pageSlug)
 	}
 	return fmt.Sprintf("https://wiki.yandex.ru/%s#%s", pageSlug, *anchorSlug)
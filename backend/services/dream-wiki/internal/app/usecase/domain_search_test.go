@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func makeSearchResult(paragraphIndex int, score float64) internals.SearchResultItem {
+	return internals.SearchResultItem{
+		PageId:           api.PageID{1},
+		PageSlug:         "page",
+		ParagraphIndex:   paragraphIndex,
+		ParagraphContent: "paragraph",
+		Score:            score,
+	}
+}
+
+func TestFuseSearchResults(t *testing.T) {
+	t.Parallel()
+
+	u, _ := newMemoryStorageUsecase(t)
+	u.deps.Config = &config.Config{SearchTermsWeight: 1, SearchEmbeddingWeight: 1, SearchRRFK: 60}
+
+	termResults := []internals.SearchResultItem{makeSearchResult(1, 3), makeSearchResult(2, 2)}
+	embeddingResults := []internals.SearchResultItem{makeSearchResult(3, 0.1), makeSearchResult(2, 0.2)}
+
+	firstPage := u.fuseSearchResults(termResults, embeddingResults, 0, 2)
+	require.Len(t, firstPage.ResultItems, 2)
+	require.True(t, firstPage.NextInfo.HasMore)
+	require.Equal(t, "2", firstPage.NextInfo.Cursor)
+
+	// Paragraph 2 is found by both searches, so it goes first.
+	best := firstPage.ResultItems[0]
+	require.InDelta(t, 1.0/62+1.0/62, best.Score, 1e-12)
+	require.Equal(t, 2, *best.Explanation.TermsRank)
+	require.Equal(t, 2.0, *best.Explanation.TermsScore)
+	require.Equal(t, 2, *best.Explanation.EmbeddingRank)
+	require.Equal(t, 0.2, *best.Explanation.EmbeddingDistance)
+	require.Equal(t, "https://wiki.yandex.ru/page", best.YwikiAnchorLink)
+
+	secondPage := u.fuseSearchResults(termResults, embeddingResults, 2, 2)
+	require.Len(t, secondPage.ResultItems, 1)
+	require.False(t, secondPage.NextInfo.HasMore)
+	require.Nil(t, secondPage.ResultItems[0].Explanation.TermsRank)
+	require.NotNil(t, secondPage.ResultItems[0].Explanation.EmbeddingRank)
+}
+
+// createTestPageTree stores pages with given slugs, the first page being the
+// parent of the last one.
+func createTestPageTree(t *testing.T, storage repository.Storage, slugs ...string) []api.PageID {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	pageIDs := make([]api.PageID, 0, len(slugs))
+	for _, slug := range slugs {
+		pageID, err := repo.CreatePage(slug, slug, "")
+		require.NoError(t, err)
+		pageIDs = append(pageIDs, *pageID)
+	}
+	require.NoError(t, repo.SetPageParentID(pageIDs[len(pageIDs)-1], &pageIDs[0]))
+	require.NoError(t, repo.Commit())
+
+	return pageIDs
+}
+
+func TestResolveSearchFilters(t *testing.T) {
+	t.Parallel()
+
+	_, storage := newMemoryStorageUsecase(t)
+	// Last page is attached to guide by parent link, not by slug.
+	pageIDs := createTestPageTree(t, storage, "docs/guide", "docs", "docs/guide/install", "docs-other", "moved")
+	guide, docs, install, other, orphan := pageIDs[0], pageIDs[1], pageIDs[2], pageIDs[3], pageIDs[4]
+	newRepository := func(t *testing.T) repository.AppRepository {
+		repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+		t.Cleanup(repo.Rollback)
+		return repo
+	}
+
+	t.Run("no filters", func(t *testing.T) {
+		t.Parallel()
+
+		filters, err := resolveSearchFilters(newRepository(t), nil)
+		require.NoError(t, err)
+		require.Nil(t, filters.PageIds)
+		require.False(t, filters.IncludeHeaders)
+	})
+
+	t.Run("subtree by slug", func(t *testing.T) {
+		t.Parallel()
+
+		slug := "/docs/"
+		filters, err := resolveSearchFilters(newRepository(t), &api.SearchFilters{SubtreeSlug: &slug})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []api.PageID{docs, guide, install, orphan}, *filters.PageIds)
+	})
+
+	t.Run("subtree by page intersected with page ids", func(t *testing.T) {
+		t.Parallel()
+
+		pageIDs := []api.PageID{install, other}
+		includeHeaders := true
+		headerPath := []string{"Setup"}
+		filters, err := resolveSearchFilters(newRepository(t), &api.SearchFilters{
+			SubtreePageId:  &guide,
+			PageIds:        &pageIDs,
+			IncludeHeaders: &includeHeaders,
+			HeaderPath:     &headerPath,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []api.PageID{install}, *filters.PageIds)
+		require.True(t, filters.IncludeHeaders)
+		require.Equal(t, headerPath, *filters.HeaderPath)
+	})
+
+	t.Run("empty subtree", func(t *testing.T) {
+		t.Parallel()
+
+		slug := "missing"
+		filters, err := resolveSearchFilters(newRepository(t), &api.SearchFilters{SubtreeSlug: &slug})
+		require.NoError(t, err)
+		require.Empty(t, *filters.PageIds)
+	})
+}
+
+type fakeSearchInferenceClient struct {
+	fakeStemmer
+}
+
+func (c *fakeSearchInferenceClient) GenerateEmbedding(context.Context, string) (internals.Embedding, error) {
+	return internals.Embedding{1}, nil
+}
+
+// createTestSearchParagraphs stores page with paragraphs containing "query",
+// ranked differently by terms and by embedding.
+func createTestSearchParagraphs(t *testing.T, storage repository.Storage, paragraphsCount int) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	pageID, err := repo.CreatePage("page", "Page", "")
+	require.NoError(t, err)
+	for i := range paragraphsCount {
+		require.NoError(t, repo.AddIndexedParagraph(internals.ParagraphWithEmbedding{
+			PageId:         *pageID,
+			ParagraphIndex: i,
+			Content:        "query",
+			Embedding:      internals.Embedding{1, float32(i * 7 % paragraphsCount)},
+			TermsCount:     int64(paragraphsCount),
+		}))
+		require.NoError(t, repo.AddTerm("query", *pageID, int64(i), int64(paragraphsCount-i)))
+	}
+	require.NoError(t, repo.SetPageTermStats(internals.PageTermStats{
+		PageId:               *pageID,
+		ParagraphsCount:      int64(paragraphsCount),
+		TermsCount:           int64(paragraphsCount * paragraphsCount),
+		TermParagraphsCounts: map[string]int64{"query": int64(paragraphsCount)},
+	}))
+	require.NoError(t, repo.Commit())
+}
+
+func TestSearchPagination(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newMemoryStorageUsecase(t)
+	createTestSearchParagraphs(t, storage, 30)
+	u.deps.Config = &config.Config{SearchTermsWeight: 1, SearchEmbeddingWeight: 1, SearchRRFK: 60}
+	u.deps.InferenceClient = &fakeSearchInferenceClient{}
+
+	limit := 30
+	all, err := u.Search(api.V1SearchRequest{Query: "query", Limit: &limit})
+	require.NoError(t, err)
+	require.Len(t, all.ResultItems, 30)
+
+	// Pages concatenate into the same ranking, as every page is cut from
+	// the same candidates.
+	limit = 7
+	var cursor *api.Cursor
+	paged := make([]api.SearchResultItem, 0)
+	for {
+		page, err := u.Search(api.V1SearchRequest{Query: "query", Limit: &limit, Cursor: cursor})
+		require.NoError(t, err)
+		paged = append(paged, page.ResultItems...)
+		if !page.NextInfo.HasMore {
+			break
+		}
+		cursor = &page.NextInfo.Cursor
+	}
+	require.Equal(t, all.ResultItems, paged)
+}
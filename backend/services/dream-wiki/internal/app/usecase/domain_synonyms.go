@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/searchquery"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+)
+
+func (u *appUsecaseImpl) ListSynonymGroups() ([]api.SynonymGroup, error) {
+	repo := u.createReadOnlyRepository()
+	defer repo.Rollback()
+
+	return repo.ListSynonymGroups()
+}
+
+// cleanSynonymPhrases trims phrases and drops ones which match the same words
+// as a previous phrase.
+func cleanSynonymPhrases(phrases []string) []string {
+	cleaned := make([]string, 0, len(phrases))
+	seen := make(map[string]bool)
+	for _, phrase := range phrases {
+		normalized := searchquery.NormalizePhrase(phrase)
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		cleaned = append(cleaned, strings.TrimSpace(phrase))
+	}
+	return cleaned
+}
+
+// UpsertSynonymGroup creates a group when synonymGroupID is nil and replaces
+// phrases of the existing group otherwise.
+func (u *appUsecaseImpl) UpsertSynonymGroup(synonymGroupID *api.SynonymGroupID, phrases []string) (*api.SynonymGroupID, error) {
+	phrases = cleanSynonymPhrases(phrases)
+	if len(phrases) < 2 {
+		return nil, models.ErrBadSynonymGroup
+	}
+
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	if synonymGroupID == nil {
+		var err error
+		synonymGroupID, err = repo.CreateSynonymGroup(phrases)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		err := repo.SetSynonymGroupPhrases(*synonymGroupID, phrases)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := repo.Commit(); err != nil {
+		return nil, err
+	}
+	return synonymGroupID, nil
+}
+
+func (u *appUsecaseImpl) DeleteSynonymGroup(synonymGroupID api.SynonymGroupID) error {
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	if err := repo.RemoveSynonymGroup(synonymGroupID); err != nil {
+		return err
+	}
+	return repo.Commit()
+}
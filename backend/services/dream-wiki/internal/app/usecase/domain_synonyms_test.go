@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/inference_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// fakeStemmer stems by lowercasing words.
+type fakeStemmer struct {
+	inference_client.InferenceClient
+}
+
+func (c *fakeStemmer) GenerateStems(_ context.Context, paragraphs []string) ([][]string, error) {
+	stems := make([][]string, len(paragraphs))
+	for i, paragraph := range paragraphs {
+		stems[i] = strings.Fields(strings.ToLower(paragraph))
+	}
+	return stems, nil
+}
+
+func newMemoryStorageUsecase(t *testing.T) (*appUsecaseImpl, repository.Storage) {
+	t.Helper()
+	storage := repository.NewMemoryStorage(logger.InitTestLogger(), nil, nil)
+	u := &appUsecaseImpl{
+		ctx:  context.Background(),
+		deps: &deps.Deps{Logger: logger.InitTestLogger(), InferenceClient: &fakeStemmer{}, Config: &config.Config{ChunkMaxTokens: 256}},
+		log:  logger.InitTestLogger(),
+		newRepository: func(mode db_adapter.TransactionMode) repository.AppRepository {
+			return storage.NewRepository(context.Background(), mode)
+		},
+	}
+	return u, storage
+}
+
+func TestUpsertSynonymGroup(t *testing.T) {
+	t.Parallel()
+	u, _ := newMemoryStorageUsecase(t)
+
+	_, err := u.UpsertSynonymGroup(nil, []string{"PR", " pr ", ""})
+	require.ErrorIs(t, err, models.ErrBadSynonymGroup)
+
+	missingID := uuid.New()
+	_, err = u.UpsertSynonymGroup(&missingID, []string{"PR", "pull request"})
+	require.ErrorIs(t, err, models.ErrNotFound)
+
+	groupID, err := u.UpsertSynonymGroup(nil, []string{" PR", "pull request", "Pull  Request"})
+	require.NoError(t, err)
+	groups, err := u.ListSynonymGroups()
+	require.NoError(t, err)
+	require.Equal(t, []api.SynonymGroup{{SynonymGroupId: *groupID, Phrases: []string{"PR", "pull request"}}}, groups)
+
+	_, err = u.UpsertSynonymGroup(groupID, []string{"PR", "merge request"})
+	require.NoError(t, err)
+	groups, err = u.ListSynonymGroups()
+	require.NoError(t, err)
+	require.Equal(t, []string{"PR", "merge request"}, groups[0].Phrases)
+
+	require.NoError(t, u.DeleteSynonymGroup(*groupID))
+	groups, err = u.ListSynonymGroups()
+	require.NoError(t, err)
+	require.Empty(t, groups)
+}
+
+func TestSearchTerms(t *testing.T) {
+	t.Parallel()
+	u, storage := newMemoryStorageUsecase(t)
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	require.NoError(t, repo.SetPageTermStats(internals.PageTermStats{
+		PageId:          uuid.New(),
+		ParagraphsCount: 1,
+		TermsCount:      5,
+		TermParagraphsCounts: map[string]int64{
+			"deploy": 1, "deployment": 1, "pull": 1, "request": 1, "helm": 1,
+		},
+	}))
+	_, err := repo.CreateSynonymGroup([]string{"PR", "pull request"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	reader := u.createReadOnlyRepository()
+	defer reader.Rollback()
+
+	terms, err := u.searchTerms(reader, "Depoly PR with helm")
+	require.NoError(t, err)
+	// Misspelled "depoly" is corrected, unknown "with" is kept as is, synonyms
+	// of "PR" are added.
+	require.Equal(t, []string{"deploy", "pr", "with", "helm", "pull", "request"}, terms)
+}
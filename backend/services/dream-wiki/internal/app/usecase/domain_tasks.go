@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"math"
+	"slices"
 	"time"
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_factory"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
@@ -20,6 +22,15 @@ func makeTaskDescription(taskDigest api.TaskDigest, state internals.TaskState) s
 	if discriminator, _ := state.Discriminator(); internals.TaskType(discriminator) == internals.ReindexatePages {
 		return "Проиндексировать страницы"
 	}
+	if discriminator, _ := state.Discriminator(); internals.TaskType(discriminator) == internals.YwikiFetchAll {
+		return "Выгрузить страницы из YWiki"
+	}
+	if discriminator, _ := state.Discriminator(); internals.TaskType(discriminator) == internals.YwikiPublishDraft {
+		return "Опубликовать черновик в YWiki"
+	}
+	if discriminator, _ := state.Discriminator(); internals.TaskType(discriminator) == internals.AskQuestion {
+		return "Ответить на вопрос"
+	}
 	return "Какая-то задача"
 }
 
@@ -55,12 +66,113 @@ func (u *appUsecaseImpl) ListTasks(cursor *api.Cursor) (tasks []api.TaskDigest,
 	return taskDigests, newCursor, nil
 }
 
+// RetryTask re-enqueues unfinished actions of a failed task. Task state is left as
+// is, so the task logic resumes from the stage where it stopped.
 func (u *appUsecaseImpl) RetryTask(taskID api.TaskID) error {
-	panic("unimplemented")
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	taskDigest, _, err := repo.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	if taskDigest.Status != api.FailedByError && taskDigest.Status != api.FailedByTimeout {
+		return models.ErrTaskNotRetryable
+	}
+
+	// Besides failed action, actions in flight were dropped when the task failed:
+	// their messages were skipped, and a timed out task has its action stuck.
+	// Tasks which run actions concurrently wait for all of them.
+	actionIDs, err := repo.GetTaskActionIDsByStatus(taskID, []internals.TaskActionStatus{
+		internals.Failed, internals.New, internals.Executing, internals.Submitted,
+	})
+	if err != nil {
+		return err
+	}
+	if len(actionIDs) == 0 {
+		return models.ErrNothingToRetry
+	}
+	slices.Sort(actionIDs)
+
+	err = repo.SetTaskStatus(taskID, api.Executing)
+	if err != nil {
+		return err
+	}
+
+	for _, actionID := range actionIDs {
+		err = repo.SetTaskActionStatus(actionID, internals.New)
+		if err != nil {
+			return err
+		}
+
+		err = repo.EnqueueTaskAction(actionID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return repo.Commit()
 }
 
+// CancelTask moves task to cancelled status. Pending actions are abandoned,
+// so action executor skips them when they are read from queue.
 func (u *appUsecaseImpl) CancelTask(taskID api.TaskID) error {
-	panic("unimplemented")
+	repo := u.createReadWriteRepository()
+	defer repo.Rollback()
+
+	taskDigest, taskState, err := repo.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	if taskDigest.Status == api.Cancelled {
+		return nil
+	}
+	if task_common.IsTerminalTaskStatus(taskDigest.Status) {
+		return models.ErrTaskNotCancellable
+	}
+
+	err = repo.SetTaskStatus(taskID, api.Cancelled)
+	if err != nil {
+		return err
+	}
+
+	err = repo.AbandonPendingTaskActions(taskID)
+	if err != nil {
+		return err
+	}
+
+	err = releasePublishingDraft(repo, taskState)
+	if err != nil {
+		return err
+	}
+
+	return repo.Commit()
+}
+
+// releasePublishingDraft makes draft of cancelled publishing task active again.
+func releasePublishingDraft(repo repository.AppRepository, taskState *internals.TaskState) error {
+	taskType, err := taskState.Discriminator()
+	if err != nil || internals.TaskType(taskType) != internals.YwikiPublishDraft {
+		return nil
+	}
+	publishState, err := taskState.AsTaskStateYWikiPublishDraft()
+	if err != nil {
+		return err
+	}
+
+	draft, _, err := repo.GetDraftByID(publishState.DraftId)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if draft.DraftDigest.Status != api.Publishing {
+		return nil
+	}
+	return repo.SetDraftStatus(publishState.DraftId, api.Active)
 }
 
 func (u *appUsecaseImpl) GetTaskDetails(taskID api.TaskID) (api.Task, error) {
@@ -136,6 +248,20 @@ func (u *appUsecaseImpl) CreatePageReindexationTask(pageIDs []api.PageID) (*api.
 	repo := u.createReadWriteRepository()
 	defer repo.Rollback()
 
+	taskID, err := createPageReindexationTask(repo, pageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return taskID, nil
+}
+
+func createPageReindexationTask(repo repository.AppRepository, pageIDs []api.PageID) (*api.TaskID, error) {
 	pageTitles := make(map[string]string)
 	for _, pageID := range pageIDs {
 		page, _, err := repo.GetPageByID(pageID)
@@ -152,6 +278,7 @@ func (u *appUsecaseImpl) CreatePageReindexationTask(pageIDs []api.PageID) (*api.
 	taskState := internals.TaskStateReindexatePages{
 		PagesToIndexateIds: pageIDs,
 		IndexatedPageIds:   []api.PageID{},
+		FailedPages:        []internals.ReindexationFailure{},
 		PageTitles:         pageTitles,
 		TaskType:           internals.ReindexatePages,
 	}
@@ -179,10 +306,5 @@ func (u *appUsecaseImpl) CreatePageReindexationTask(pageIDs []api.PageID) (*api.
 		return nil, err
 	}
 
-	err = repo.Commit()
-	if err != nil {
-		return nil, err
-	}
-
 	return taskID, nil
 }
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const taskActionsTopicName = "TaskActionToExecute"
+
+func makeReindexationTaskState(t *testing.T, indexatedPageIDs []api.PageID) internals.TaskState {
+	t.Helper()
+
+	var state internals.TaskState
+	err := state.FromTaskStateReindexatePages(internals.TaskStateReindexatePages{
+		TaskType:             internals.ReindexatePages,
+		PagesToIndexateIds:   []api.PageID{{1}, {2}, {3}, {4}},
+		DispatchedPagesCount: 4,
+		IndexatedPageIds:     indexatedPageIDs,
+		PageTitles:           map[string]string{},
+	})
+	require.NoError(t, err)
+	return state
+}
+
+// createTestTask stores task with given status and one action per given action
+// status.
+func createTestTask(t *testing.T, storage repository.Storage, status api.TaskStatus, state internals.TaskState, actionStatuses ...internals.TaskActionStatus) (api.TaskID, []internals.TaskActionID) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+	require.NoError(t, repo.SetTaskStatus(*taskID, status))
+
+	var action internals.TaskAction
+	require.NoError(t, action.FromTaskActionIndexatePage(internals.TaskActionIndexatePage{
+		TaskActionType: internals.IndexatePage,
+	}))
+	actionIDs := make([]internals.TaskActionID, 0, len(actionStatuses))
+	for _, actionStatus := range actionStatuses {
+		actionID, err := repo.CreateTaskAction(*taskID, action)
+		require.NoError(t, err)
+		require.NoError(t, repo.SetTaskActionStatus(*actionID, actionStatus))
+		actionIDs = append(actionIDs, *actionID)
+	}
+	require.NoError(t, repo.Commit())
+
+	return *taskID, actionIDs
+}
+
+func getTestTask(t *testing.T, storage repository.Storage, taskID api.TaskID) (*api.TaskDigest, *internals.TaskState) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	taskDigest, taskState, err := repo.GetTaskByID(taskID)
+	require.NoError(t, err)
+	return taskDigest, taskState
+}
+
+func requireTaskActionStatuses(t *testing.T, storage repository.Storage, actionIDs []internals.TaskActionID, statuses ...internals.TaskActionStatus) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	actualStatuses := make([]internals.TaskActionStatus, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		_, actionInfo, err := repo.GetTaskActionByID(actionID)
+		require.NoError(t, err)
+		actualStatuses = append(actualStatuses, actionInfo.Status)
+	}
+	require.Equal(t, statuses, actualStatuses)
+}
+
+// readEnqueuedTaskActions drains task actions topic. Messages are published on
+// commit, so the ones already enqueued are read before the timeout.
+func readEnqueuedTaskActions(t *testing.T, storage repository.Storage) []internals.TaskActionID {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	actionIDs := make([]internals.TaskActionID, 0)
+	reader := storage.NewTopicReader(taskActionsTopicName, func(message []byte) {
+		actionID, err := strconv.ParseInt(string(message), 10, 64)
+		require.NoError(t, err)
+		actionIDs = append(actionIDs, actionID)
+	})
+	require.ErrorIs(t, reader.ReadMessages(ctx), context.DeadlineExceeded)
+	return actionIDs
+}
+
+func TestCancelTask(t *testing.T) {
+	t.Parallel()
+
+	t.Run("executing task is cancelled and pending actions are abandoned", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, actionIDs := createTestTask(t, storage, api.Executing, makeReindexationTaskState(t, nil),
+			internals.Finished, internals.New, internals.Executing)
+		_, otherActionIDs := createTestTask(t, storage, api.Executing, makeReindexationTaskState(t, nil), internals.New)
+
+		err := u.CancelTask(taskID)
+		require.NoError(t, err)
+
+		taskDigest, _ := getTestTask(t, storage, taskID)
+		require.Equal(t, api.Cancelled, taskDigest.Status)
+		requireTaskActionStatuses(t, storage, actionIDs, internals.Finished, internals.Abandoned, internals.Abandoned)
+		requireTaskActionStatuses(t, storage, otherActionIDs, internals.New)
+	})
+
+	t.Run("cancelling cancelled task is no-op", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, actionIDs := createTestTask(t, storage, api.Cancelled, makeReindexationTaskState(t, nil), internals.New)
+
+		err := u.CancelTask(taskID)
+		require.NoError(t, err)
+		requireTaskActionStatuses(t, storage, actionIDs, internals.New)
+	})
+
+	t.Run("finished task can not be cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, actionIDs := createTestTask(t, storage, api.Done, makeReindexationTaskState(t, nil), internals.Finished)
+
+		err := u.CancelTask(taskID)
+		require.ErrorIs(t, err, models.ErrTaskNotCancellable)
+		require.ErrorIs(t, err, models.ErrConflict)
+
+		taskDigest, _ := getTestTask(t, storage, taskID)
+		require.Equal(t, api.Done, taskDigest.Status)
+		requireTaskActionStatuses(t, storage, actionIDs, internals.Finished)
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		t.Parallel()
+
+		u, _ := newMemoryStorageUsecase(t)
+
+		err := u.CancelTask(1)
+		require.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
+func TestRetryTask(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failed and in flight actions are re-enqueued and state is kept", func(t *testing.T) {
+		t.Parallel()
+
+		// Pages are indexated concurrently: second action indexated page 1, third
+		// one failed the task, the last two were in flight.
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, actionIDs := createTestTask(t, storage, api.FailedByError, makeReindexationTaskState(t, []api.PageID{{1}}),
+			internals.Finished, internals.Finished, internals.Failed, internals.New, internals.Executing)
+		_, otherActionIDs := createTestTask(t, storage, api.Executing, makeReindexationTaskState(t, nil), internals.New)
+
+		err := u.RetryTask(taskID)
+		require.NoError(t, err)
+
+		taskDigest, taskState := getTestTask(t, storage, taskID)
+		require.Equal(t, api.Executing, taskDigest.Status)
+		require.ElementsMatch(t, actionIDs[2:], readEnqueuedTaskActions(t, storage))
+		requireTaskActionStatuses(t, storage, actionIDs,
+			internals.Finished, internals.Finished, internals.New, internals.New, internals.New)
+		requireTaskActionStatuses(t, storage, otherActionIDs, internals.New)
+
+		reindexationState, err := taskState.AsTaskStateReindexatePages()
+		require.NoError(t, err)
+		require.Equal(t, []api.PageID{{1}}, reindexationState.IndexatedPageIds)
+	})
+
+	t.Run("stuck action of timed out task is re-enqueued", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, actionIDs := createTestTask(t, storage, api.FailedByTimeout, makeReindexationTaskState(t, nil),
+			internals.Finished, internals.Executing)
+
+		err := u.RetryTask(taskID)
+		require.NoError(t, err)
+
+		taskDigest, _ := getTestTask(t, storage, taskID)
+		require.Equal(t, api.Executing, taskDigest.Status)
+		require.Equal(t, actionIDs[1:], readEnqueuedTaskActions(t, storage))
+		requireTaskActionStatuses(t, storage, actionIDs, internals.Finished, internals.New)
+	})
+
+	t.Run("executing task can not be retried", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, actionIDs := createTestTask(t, storage, api.Executing, makeReindexationTaskState(t, nil), internals.Failed)
+
+		err := u.RetryTask(taskID)
+		require.ErrorIs(t, err, models.ErrTaskNotRetryable)
+		require.Empty(t, readEnqueuedTaskActions(t, storage))
+		requireTaskActionStatuses(t, storage, actionIDs, internals.Failed)
+	})
+
+	t.Run("cancelled task can not be retried", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, _ := createTestTask(t, storage, api.Cancelled, makeReindexationTaskState(t, nil), internals.Abandoned)
+
+		err := u.RetryTask(taskID)
+		require.ErrorIs(t, err, models.ErrTaskNotRetryable)
+	})
+
+	t.Run("failed task without actions to retry", func(t *testing.T) {
+		t.Parallel()
+
+		u, storage := newMemoryStorageUsecase(t)
+		taskID, _ := createTestTask(t, storage, api.FailedByError, makeReindexationTaskState(t, nil), internals.Finished)
+
+		err := u.RetryTask(taskID)
+		require.ErrorIs(t, err, models.ErrNothingToRetry)
+		require.Empty(t, readEnqueuedTaskActions(t, storage))
+	})
+}
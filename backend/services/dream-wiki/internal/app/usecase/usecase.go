@@ -2,7 +2,10 @@ package usecase
 
 import (
 	"context"
+	"time"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
@@ -10,6 +13,10 @@ import (
 
 type (
 	AppUsecase interface {
+		// domain_ask.go
+		AskQuestion(req api.V1AskRequest) (*api.TaskID, error)
+		GetAskResult(taskID api.TaskID, waitSeconds int) (*api.V1AskResultResponse, error)
+
 		// domain_auth.go
 		Login(req api.V1LoginRequest) (*api.V1LoginResponse, error)
 
@@ -18,25 +25,46 @@ type (
 		DeleteDraft(draftID api.DraftID) error
 		GetDraft(draftID api.DraftID) (*api.Draft, error)
 		UpdateDraft(draftID api.DraftID, newContent *string, newTitle *string) error
-		ApplyDraft(draftID api.DraftID) error
+		ApplyDraft(draftID api.DraftID) (*api.TaskID, error)
+		RebaseDraft(draftID api.DraftID) (*api.V1DraftsRebaseResponse, error)
+		DiffDraft(draftID api.DraftID) (*api.V1DraftsDiffResponse, error)
+		RejectDraftHunks(draftID api.DraftID, draftVersion string, rejectedHunkIndexes []int) (*api.V1DraftsDiffResponse, error)
 		ListDrafts(cursor *api.Cursor) ([]api.DraftDigest, *api.NextInfo, error)
 
 		// domain_integrations.go
 		FetchPageFromYWiki(pageURL string) error
-		YwikiFetchAllAsync() (*api.TaskID, error)
+		YwikiFetchAllAsync(rootPageURL *string) (*api.TaskID, error)
 		GetIntegrationLogs(integrationID api.IntegrationID, cursor *api.Cursor) ([]api.IntegrationLogField, *api.NextInfo, error)
 		GithubAccountPRAsync(prURL string) (*api.TaskID, error)
 
 		// domain_page_indexation.go
 		IndexatePage(pageID api.PageID) (*api.V1IndexatePageResponse, error)
+		DeferPageReindexation(pageID api.PageID, writtenAt time.Time) error
+		ScheduleDuePagesReindexation(lastWrittenBefore time.Time, firstWrittenBefore time.Time) (*api.TaskID, error)
 
 		// domain_pages.go
 		GetDiagnosticInfo(req api.V1DiagnosticInfoGetRequest) (*api.V1DiagnosticInfoGetResponse, error)
 		GetPagesTree(activePagesIDs []api.PageID) ([]api.TreeItem, error)
 
+		// domain_prompts.go
+		ListPromptTemplates() ([]api.PromptTemplate, error)
+		ListPromptTemplateVersions(name string) ([]api.PromptTemplate, error)
+		OverridePromptTemplate(req api.V1PromptsOverrideRequest) (*api.PromptTemplate, error)
+
+		// domain_revisions.go
+		ListPageRevisions(pageID api.PageID, cursor *api.Cursor) ([]api.RevisionDigest, *api.NextInfo, error)
+		GetRevision(revisionID api.RevisionID) (*api.Revision, error)
+		DiffRevisions(oldRevisionID api.RevisionID, newRevisionID api.RevisionID, mode api.DiffMode, contextLines int) (*api.V1RevisionsDiffResponse, error)
+		RevertPageToRevision(revisionID api.RevisionID) (*api.RevisionDigest, error)
+
 		// domain_search.go
 		Search(req api.V1SearchRequest) (*api.V1SearchResponse, error)
 
+		// domain_synonyms.go
+		ListSynonymGroups() ([]api.SynonymGroup, error)
+		UpsertSynonymGroup(synonymGroupID *api.SynonymGroupID, phrases []string) (*api.SynonymGroupID, error)
+		DeleteSynonymGroup(synonymGroupID api.SynonymGroupID) error
+
 		// domain_tasks.go
 		CancelTask(taskID api.TaskID) error
 		GetTaskDetails(taskID api.TaskID) (api.Task, error)
@@ -51,9 +79,14 @@ type (
 		ctx  context.Context
 		deps *deps.Deps
 		log  logger.Logger
+
+		// newRepository opens repository in a new transaction. Tests replace it with fakes.
+		newRepository func(mode db_adapter.TransactionMode) repository.AppRepository
 	}
 )
 
 func NewAppUsecaseImpl(ctx context.Context, deps *deps.Deps) AppUsecase {
-	return &appUsecaseImpl{ctx: ctx, deps: deps, log: deps.Logger}
+	u := &appUsecaseImpl{ctx: ctx, deps: deps, log: deps.Logger}
+	u.newRepository = u.newStorageRepository
+	return u
 }
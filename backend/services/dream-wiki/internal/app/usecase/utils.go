@@ -5,7 +5,7 @@ import (
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/middleware/auth"
 )
 
 func extractYWikiSlugFromURL(pageURL string) string {
@@ -14,16 +14,23 @@ func extractYWikiSlugFromURL(pageURL string) string {
 	return strings.TrimSuffix(strings.TrimPrefix(pageURL, prefix), "/")
 }
 
+func (u *appUsecaseImpl) newStorageRepository(mode db_adapter.TransactionMode) repository.AppRepository {
+	return u.deps.Storage.NewRepository(u.ctx, mode)
+}
+
 func (u *appUsecaseImpl) createReadOnlyRepository() repository.AppRepository {
-	return repository.NewAppRepository(u.ctx, &deps.RepositoryDeps{
-		TX:   u.deps.YDBDriver.NewTransaction(u.ctx, db_adapter.SnapshotReadOnly),
-		Deps: u.deps,
-	})
+	return u.newRepository(db_adapter.SnapshotReadOnly)
 }
 
 func (u *appUsecaseImpl) createReadWriteRepository() repository.AppRepository {
-	return repository.NewAppRepository(u.ctx, &deps.RepositoryDeps{
-		TX:   u.deps.YDBDriver.NewTransaction(u.ctx, db_adapter.SerializableReadWrite),
-		Deps: u.deps,
-	})
+	return u.newRepository(db_adapter.SerializableReadWrite)
+}
+
+// currentUsername returns name of the user who made the request, if any.
+func (u *appUsecaseImpl) currentUsername() *string {
+	user, ok := auth.GetUserFromContext(u.ctx)
+	if !ok {
+		return nil
+	}
+	return &user.Username
 }
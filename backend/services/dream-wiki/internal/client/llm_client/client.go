@@ -0,0 +1,83 @@
+package llm_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+
+	DefaultTemperature = 0.1
+	DefaultMaxTokens   = 2000
+)
+
+// ErrOperationNotFound is returned by GetOperation when provider does not know
+// the operation, e.g. in-process operation was lost on restart.
+var ErrOperationNotFound = errors.New("LLM operation not found")
+
+type (
+	// Request is provider-neutral completion request. SystemPrompt, if set, is
+	// sent before Messages.
+	Request struct {
+		Model        internals.LLMModel
+		Temperature  float64
+		MaxTokens    int
+		SystemPrompt string
+		Messages     []internals.LLMMessage
+	}
+
+	Operation struct {
+		Done bool
+		// Text is the model answer, set when operation is done without Error.
+		Text string
+		// Error is set when provider failed the request.
+		Error string
+		// ServerResponse is raw provider response kept in action result.
+		ServerResponse map[string]any
+	}
+
+	// LLMClient runs completions asynchronously: StartRequest returns
+	// operation ID which is polled with GetOperation until it is done.
+	LLMClient interface {
+		StartRequest(ctx context.Context, request Request) (string, error)
+		GetOperation(ctx context.Context, operationID string) (*Operation, error)
+	}
+)
+
+// NewLLMClient creates client of provider selected by LLM_PROVIDER.
+func NewLLMClient(appConfig *config.Config) (LLMClient, error) {
+	switch appConfig.LLMProvider {
+	case config.LLMProviderYandexGPT:
+		return newYandexGPTClient(appConfig)
+	case config.LLMProviderOpenAI:
+		return newOpenAIClient(appConfig)
+	case config.LLMProviderMock:
+		script := defaultScript
+		if appConfig.LLMMockScriptPath != "" {
+			var err error
+			script, err = LoadScript(appConfig.LLMMockScriptPath)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return NewScriptedLLMClient(script), nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider: %s", appConfig.LLMProvider)
+}
+
+// requestMessages returns system prompt and messages of request as a single
+// list, which is how both YandexGPT and OpenAI APIs accept them.
+func requestMessages(request Request) []internals.LLMMessage {
+	messages := make([]internals.LLMMessage, 0, len(request.Messages)+1)
+	if request.SystemPrompt != "" {
+		messages = append(messages, internals.LLMMessage{Role: RoleSystem, Content: request.SystemPrompt})
+	}
+	return append(messages, request.Messages...)
+}
@@ -0,0 +1,120 @@
+package llm_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/openai_client_gen"
+)
+
+func waitOperation(t *testing.T, client LLMClient, operationID string) *Operation {
+	t.Helper()
+	var operation *Operation
+	require.Eventually(t, func() bool {
+		var err error
+		operation, err = client.GetOperation(context.Background(), operationID)
+		require.NoError(t, err)
+		return operation.Done
+	}, 5*time.Second, 10*time.Millisecond)
+	return operation
+}
+
+func TestOpenAIClient(t *testing.T) {
+	t.Parallel()
+
+	var received openai_client_gen.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": "1", "choices": [{"index": 0, "message": {"role": "assistant", "content": "answer"}}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewLLMClient(&config.Config{
+		LLMProvider:  config.LLMProviderOpenAI,
+		OpenAIAPIURL: server.URL + "/v1",
+		OpenAIAPIKey: "key",
+		OpenAIModel:  "local-model",
+	})
+	require.NoError(t, err)
+
+	operationID, err := client.StartRequest(context.Background(), Request{
+		Model:        internals.Yandexgpt5Lite,
+		Temperature:  0.5,
+		MaxTokens:    100,
+		SystemPrompt: "system",
+		Messages:     []internals.LLMMessage{{Role: RoleUser, Content: "question"}},
+	})
+	require.NoError(t, err)
+
+	operation := waitOperation(t, client, operationID)
+	require.Empty(t, operation.Error)
+	require.Equal(t, "answer", operation.Text)
+	require.Equal(t, "1", operation.ServerResponse["id"])
+
+	require.Equal(t, "local-model", received.Model)
+	require.Equal(t, 0.5, *received.Temperature)
+	require.Equal(t, 100, *received.MaxTokens)
+	require.Equal(t, []openai_client_gen.ChatMessage{
+		{Role: openai_client_gen.System, Content: "system"},
+		{Role: openai_client_gen.User, Content: "question"},
+	}, received.Messages)
+
+	_, err = client.GetOperation(context.Background(), "unknown")
+	require.ErrorIs(t, err, ErrOperationNotFound)
+}
+
+func TestOpenAIClientServerError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model is not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, err := NewLLMClient(&config.Config{
+		LLMProvider:  config.LLMProviderOpenAI,
+		OpenAIAPIURL: server.URL,
+		OpenAIModel:  "local-model",
+	})
+	require.NoError(t, err)
+
+	operationID, err := client.StartRequest(context.Background(), Request{})
+	require.NoError(t, err)
+	operation := waitOperation(t, client, operationID)
+	require.Contains(t, operation.Error, "503")
+}
+
+func TestScriptedLLMClient(t *testing.T) {
+	t.Parallel()
+
+	client := NewScriptedLLMClient([]ScriptedResponse{
+		{Contains: "PR Patch", Response: "changes"},
+		{Contains: "", Response: "anything"},
+	})
+
+	first, err := client.StartRequest(context.Background(), Request{SystemPrompt: "PR Patch: diff"})
+	require.NoError(t, err)
+	second, err := client.StartRequest(context.Background(), Request{
+		Messages: []internals.LLMMessage{{Role: RoleUser, Content: "question"}},
+	})
+	require.NoError(t, err)
+
+	operation, err := client.GetOperation(context.Background(), first)
+	require.NoError(t, err)
+	require.Equal(t, &Operation{Done: true, Text: "changes", ServerResponse: map[string]any{"scripted": "PR Patch"}}, operation)
+	operation, err = client.GetOperation(context.Background(), second)
+	require.NoError(t, err)
+	require.Equal(t, "anything", operation.Text)
+	require.Len(t, client.Requests(), 2)
+}
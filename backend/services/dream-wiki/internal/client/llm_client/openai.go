@@ -0,0 +1,143 @@
+package llm_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/openai_client_gen"
+)
+
+const (
+	// openAIRequestTimeout bounds a single chat completion, local models may
+	// be slow.
+	openAIRequestTimeout = 3 * time.Minute
+	// openAIOperationTTL is how long done operation may be polled again, e.g.
+	// when commit of the action result failed.
+	openAIOperationTTL = 10 * time.Minute
+)
+
+// openAIClient calls chat completions API of OpenAI-compatible server, e.g.
+// local vLLM, llama.cpp or Ollama. The API is synchronous, so requests run in
+// background goroutines and operations live in process memory: they are lost
+// on restart and are only visible to the instance which started them.
+type openAIClient struct {
+	client *openai_client_gen.ClientWithResponses
+	model  string
+
+	mu         sync.Mutex
+	operations map[string]*openAIOperation
+}
+
+type openAIOperation struct {
+	operation  Operation
+	finishedAt time.Time
+}
+
+var _ LLMClient = &openAIClient{}
+
+func newOpenAIClient(appConfig *config.Config) (*openAIClient, error) {
+	options := []openai_client_gen.ClientOption{}
+	if appConfig.OpenAIAPIKey != "" {
+		options = append(options, openai_client_gen.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", appConfig.OpenAIAPIKey))
+			return nil
+		}))
+	}
+
+	client, err := openai_client_gen.NewClientWithResponses(appConfig.OpenAIAPIURL, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openAIClient{
+		client:     client,
+		model:      appConfig.OpenAIModel,
+		operations: make(map[string]*openAIOperation),
+	}, nil
+}
+
+// StartRequest ignores request.Model: local server serves the configured
+// model.
+func (c *openAIClient) StartRequest(_ context.Context, request Request) (string, error) {
+	operationID := uuid.NewString()
+
+	c.mu.Lock()
+	for id, operation := range c.operations {
+		if operation.operation.Done && time.Since(operation.finishedAt) > openAIOperationTTL {
+			delete(c.operations, id)
+		}
+	}
+	c.operations[operationID] = &openAIOperation{}
+	c.mu.Unlock()
+
+	go func() {
+		operation := c.complete(request)
+		c.mu.Lock()
+		c.operations[operationID] = &openAIOperation{
+			operation:  *operation,
+			finishedAt: time.Now(),
+		}
+		c.mu.Unlock()
+	}()
+
+	return operationID, nil
+}
+
+func (c *openAIClient) complete(request Request) *Operation {
+	ctx, cancel := context.WithTimeout(context.Background(), openAIRequestTimeout)
+	defer cancel()
+
+	messages := make([]openai_client_gen.ChatMessage, 0, len(request.Messages)+1)
+	for _, msg := range requestMessages(request) {
+		messages = append(messages, openai_client_gen.ChatMessage{
+			Role:    openai_client_gen.ChatMessageRole(msg.Role),
+			Content: msg.Content,
+		})
+	}
+	stream := false
+
+	response, err := c.client.PostChatCompletionsWithResponse(ctx, openai_client_gen.ChatCompletionRequest{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: &request.Temperature,
+		MaxTokens:   &request.MaxTokens,
+		Stream:      &stream,
+	})
+	if err != nil {
+		return &Operation{Done: true, Error: err.Error()}
+	}
+
+	operation := &Operation{Done: true}
+	if err := json.Unmarshal(response.Body, &operation.ServerResponse); err != nil {
+		operation.ServerResponse = map[string]any{"body": string(response.Body)}
+	}
+	switch {
+	case response.JSON200 == nil:
+		operation.Error = fmt.Sprintf("unexpected chat completions status %d", response.StatusCode())
+	case len(response.JSON200.Choices) == 0:
+		operation.Error = "chat completions returned no choices"
+	default:
+		operation.Text = response.JSON200.Choices[0].Message.Content
+	}
+	return operation
+}
+
+// GetOperation returns done operations for openAIOperationTTL after they
+// finish, old ones are forgotten on the next StartRequest.
+func (c *openAIClient) GetOperation(_ context.Context, operationID string) (*Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	operation, ok := c.operations[operationID]
+	if !ok {
+		return nil, ErrOperationNotFound
+	}
+	result := operation.operation
+	return &result, nil
+}
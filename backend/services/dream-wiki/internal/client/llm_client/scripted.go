@@ -0,0 +1,113 @@
+package llm_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+type (
+	// ScriptedResponse answers requests whose system prompt or messages
+	// contain Contains. Empty Contains matches any request.
+	ScriptedResponse struct {
+		Contains string `json:"contains"`
+		Response string `json:"response"`
+	}
+
+	// ScriptedLLMClient is deterministic offline LLMClient: answer is the
+	// response of the first matching script entry, operations are done
+	// immediately. Request without a matching entry fails.
+	ScriptedLLMClient struct {
+		script []ScriptedResponse
+
+		mu         sync.Mutex
+		requests   []Request
+		operations map[string]Operation
+	}
+)
+
+var _ LLMClient = &ScriptedLLMClient{}
+
+// defaultScript is used by mock provider when LLM_MOCK_SCRIPT_PATH is not set.
+var defaultScript = []ScriptedResponse{
+	{Response: "Это ответ тестовой языковой модели."},
+}
+
+func NewScriptedLLMClient(script []ScriptedResponse) *ScriptedLLMClient {
+	return &ScriptedLLMClient{
+		script:     script,
+		operations: make(map[string]Operation),
+	}
+}
+
+// LoadScript reads JSON list of ScriptedResponse.
+func LoadScript(path string) ([]ScriptedResponse, error) {
+	scriptBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LLM mock script: %w", err)
+	}
+	var script []ScriptedResponse
+	if err := json.Unmarshal(scriptBytes, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse LLM mock script: %w", err)
+	}
+	return script, nil
+}
+
+func (c *ScriptedLLMClient) StartRequest(_ context.Context, request Request) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, request)
+	operationID := fmt.Sprintf("scripted-%d", len(c.requests))
+
+	operation := Operation{
+		Done:  true,
+		Error: "no scripted response matches the request",
+	}
+	for _, entry := range c.script {
+		if requestContains(request, entry.Contains) {
+			operation = Operation{
+				Done:           true,
+				Text:           entry.Response,
+				ServerResponse: map[string]any{"scripted": entry.Contains},
+			}
+			break
+		}
+	}
+	c.operations[operationID] = operation
+
+	return operationID, nil
+}
+
+func (c *ScriptedLLMClient) GetOperation(_ context.Context, operationID string) (*Operation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	operation, ok := c.operations[operationID]
+	if !ok {
+		return nil, ErrOperationNotFound
+	}
+	return &operation, nil
+}
+
+// Requests returns requests started so far.
+func (c *ScriptedLLMClient) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Request(nil), c.requests...)
+}
+
+func requestContains(request Request, text string) bool {
+	if strings.Contains(request.SystemPrompt, text) {
+		return true
+	}
+	for _, message := range request.Messages {
+		if strings.Contains(message.Content, text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package llm_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ycloud_client_gen"
+)
+
+const yandexCloudFolderID = "b1gji9k43bb3qbc31oim"
+
+// yandexGPTModelNames maps models to names in YandexGPT model URIs.
+var yandexGPTModelNames = map[internals.LLMModel]string{
+	internals.Yandexgpt5Lite: "yandexgpt-lite/rc",
+}
+
+// yandexGPTClient uses asynchronous completions of Yandex Cloud Foundation
+// Models, operations are kept by Yandex Cloud.
+type yandexGPTClient struct {
+	operationClient *ycloud_client_gen.ClientWithResponses
+	llmClient       *ycloud_client_gen.ClientWithResponses
+}
+
+var _ LLMClient = &yandexGPTClient{}
+
+func newYandexGPTClient(appConfig *config.Config) (*yandexGPTClient, error) {
+	authorizationRequestEditor := func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", appConfig.YandexCloudToken))
+		return nil
+	}
+
+	llmClient, err := ycloud_client_gen.NewClientWithResponses(
+		"https://llm.api.cloud.yandex.net",
+		ycloud_client_gen.WithRequestEditorFn(authorizationRequestEditor),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	operationClient, err := ycloud_client_gen.NewClientWithResponses(
+		"https://operation.api.cloud.yandex.net",
+		ycloud_client_gen.WithRequestEditorFn(authorizationRequestEditor),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &yandexGPTClient{
+		llmClient:       llmClient,
+		operationClient: operationClient,
+	}, nil
+}
+
+func (c *yandexGPTClient) StartRequest(ctx context.Context, request Request) (string, error) {
+	modelName, ok := yandexGPTModelNames[request.Model]
+	if !ok {
+		return "", fmt.Errorf("model %s is not supported by YandexGPT", request.Model)
+	}
+
+	var messages []ycloud_client_gen.Message
+	for _, msg := range requestMessages(request) {
+		messages = append(messages, ycloud_client_gen.Message{
+			Role: ycloud_client_gen.MessageRole(msg.Role),
+			Text: msg.Content,
+		})
+	}
+
+	response, err := c.llmClient.PostFoundationModelsV1CompletionAsyncWithResponse(ctx, ycloud_client_gen.FoundationModelsV1CompletionAsyncRequest{
+		CompletionOptions: ycloud_client_gen.CompletionOptions{
+			Stream:      false,
+			Temperature: float32(request.Temperature),
+			MaxTokens:   strconv.Itoa(request.MaxTokens),
+			ReasoningOptions: ycloud_client_gen.ReasoningOptions{
+				Mode: "DISABLED",
+			},
+		},
+		Messages: messages,
+		ModelUri: fmt.Sprintf("gpt://%s/%s", yandexCloudFolderID, modelName),
+	},
+		func(ctx context.Context, req *http.Request) error {
+			req.Header.Add("Content-Type", "application/json")
+			req.Header.Add("X-Folder-ID", yandexCloudFolderID)
+			return nil
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	if response.JSON200 == nil {
+		return "", fmt.Errorf("unexpected YandexGPT response status %d: %s", response.StatusCode(), string(response.Body))
+	}
+
+	return response.JSON200.Id, nil
+}
+
+func (c *yandexGPTClient) GetOperation(ctx context.Context, operationID string) (*Operation, error) {
+	response, err := c.operationClient.GetOperationsOperationIdWithResponse(ctx, operationID)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode() == http.StatusNotFound {
+		return nil, ErrOperationNotFound
+	}
+	if response.JSON200 == nil {
+		return nil, fmt.Errorf("unexpected YandexGPT operation status %d: %s", response.StatusCode(), string(response.Body))
+	}
+
+	yandexOperation := response.JSON200
+	if !yandexOperation.Done {
+		return &Operation{}, nil
+	}
+
+	serverResponse := make(map[string]any)
+	if err := json.Unmarshal(response.Body, &serverResponse); err != nil {
+		return nil, err
+	}
+	operation := &Operation{
+		Done:           true,
+		ServerResponse: serverResponse,
+	}
+	if yandexOperation.Response == nil || len(yandexOperation.Response.Alternatives) == 0 {
+		operation.Error = "YandexGPT returned no alternatives"
+		return operation, nil
+	}
+	operation.Text = yandexOperation.Response.Alternatives[0].Message.Text
+	return operation, nil
+}
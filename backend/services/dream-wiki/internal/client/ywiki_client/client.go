@@ -10,6 +10,8 @@ import (
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ywiki_client_gen"
 )
 
+const descendantsPageSize = 50
+
 type (
 	yWikiClientImpl struct {
 		client              *ywiki_client_gen.ClientWithResponses
@@ -19,6 +21,9 @@ type (
 
 	YWikiClient interface {
 		GetPage(ctx context.Context, pageSlug string) (*ywiki_client_gen.V1PageResponse, error)
+		GetPageDescendants(ctx context.Context, pageSlug string, cursor *string) (*ywiki_client_gen.V1PageDescendantsResponse, error)
+		CreatePage(ctx context.Context, pageSlug string, title string, content string) (*ywiki_client_gen.V1PageResponse, error)
+		UpdatePage(ctx context.Context, pageID int64, title string, content string) (*ywiki_client_gen.V1PageResponse, error)
 	}
 )
 
@@ -59,3 +64,82 @@ func (c *yWikiClientImpl) GetPage(ctx context.Context, pageSlug string) (*ywiki_
 
 	return response.JSON200, nil
 }
+
+func (c *yWikiClientImpl) GetPageDescendants(ctx context.Context, pageSlug string, cursor *string) (*ywiki_client_gen.V1PageDescendantsResponse, error) {
+	pageSize := descendantsPageSize
+	response, err := c.client.GetPageDescendantsWithResponse(ctx, &ywiki_client_gen.GetPageDescendantsParams{
+		Slug:          pageSlug,
+		Cursor:        cursor,
+		PageSize:      &pageSize,
+		Authorization: c.authorizationHeader,
+		XCloudOrgId:   c.yandexCloudOrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.HTTPResponse.StatusCode {
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("YWiki GetPageDescendants: %w", models.ErrNotFound)
+	case http.StatusOK:
+		if response.JSON200 == nil {
+			return nil, fmt.Errorf("200 response is nil")
+		}
+	default:
+		return nil, fmt.Errorf("unexpected code: %d", response.HTTPResponse.StatusCode)
+	}
+
+	return response.JSON200, nil
+}
+
+func (c *yWikiClientImpl) CreatePage(ctx context.Context, pageSlug string, title string, content string) (*ywiki_client_gen.V1PageResponse, error) {
+	response, err := c.client.CreatePageWithResponse(ctx, &ywiki_client_gen.CreatePageParams{
+		Authorization: c.authorizationHeader,
+		XCloudOrgId:   c.yandexCloudOrgID,
+	}, ywiki_client_gen.V1PageCreateRequest{
+		PageType: ywiki_client_gen.V1PageCreateRequestPageTypePage,
+		Slug:     pageSlug,
+		Title:    title,
+		Content:  &content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.HTTPResponse.StatusCode {
+	case http.StatusOK:
+		if response.JSON200 == nil {
+			return nil, fmt.Errorf("200 response is nil")
+		}
+	default:
+		return nil, fmt.Errorf("unexpected code: %d", response.HTTPResponse.StatusCode)
+	}
+
+	return response.JSON200, nil
+}
+
+func (c *yWikiClientImpl) UpdatePage(ctx context.Context, pageID int64, title string, content string) (*ywiki_client_gen.V1PageResponse, error) {
+	response, err := c.client.UpdatePageWithResponse(ctx, pageID, &ywiki_client_gen.UpdatePageParams{
+		Authorization: c.authorizationHeader,
+		XCloudOrgId:   c.yandexCloudOrgID,
+	}, ywiki_client_gen.V1PageUpdateRequest{
+		Title:   &title,
+		Content: &content,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch response.HTTPResponse.StatusCode {
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("YWiki UpdatePage: %w", models.ErrNotFound)
+	case http.StatusOK:
+		if response.JSON200 == nil {
+			return nil, fmt.Errorf("200 response is nil")
+		}
+	default:
+		return nil, fmt.Errorf("unexpected code: %d", response.HTTPResponse.StatusCode)
+	}
+
+	return response.JSON200, nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"strconv"
 
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/component"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
@@ -25,11 +24,7 @@ func NewDreamWikiTaskActionResultsTopicReader(d *deps.Deps) *DreamWikiTaskAction
 		taskActionID, _ := strconv.ParseInt(string(message), 10, 64)
 		d.Logger.Info("processing task action result", "action_id", taskActionID)
 
-		repoDeps := &deps.RepositoryDeps{
-			TX:   d.YDBDriver.NewTransaction(context.Background(), db_adapter.SerializableReadWrite),
-			Deps: d,
-		}
-		repo := repository.NewAppRepository(context.Background(), repoDeps)
+		repo := d.Storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
 		defer repo.Rollback()
 
 		_, taskActionAdditionalInfo, err := repo.GetTaskActionByID(internals.TaskActionID(taskActionID))
@@ -44,6 +39,11 @@ func NewDreamWikiTaskActionResultsTopicReader(d *deps.Deps) *DreamWikiTaskAction
 			return
 		}
 
+		if task_common.IsTerminalTaskStatus(taskDigest.Status) {
+			d.Logger.Info("skipping task action result because task is already in terminal status. action_id=", taskActionID, " task_status=", taskDigest.Status)
+			return
+		}
+
 		taskLogicCreator := task_factory.CreateTaskLogicCreator()
 		task := task_common.NewTask(
 			context.Background(),
@@ -71,7 +71,7 @@ func NewDreamWikiTaskActionResultsTopicReader(d *deps.Deps) *DreamWikiTaskAction
 		d.Logger.Info("successfully processed task action result", "action_id", taskActionID)
 	}
 
-	reader := d.YDBDriver.NewTopicReader(taskActionResultsTopicName, processTaskActionResultMessage)
+	reader := d.Storage.NewTopicReader(taskActionResultsTopicName, processTaskActionResultMessage)
 
 	return &DreamWikiTaskActionResultsTopicReader{
 		deps:   d,
@@ -33,7 +33,7 @@ func NewDreamWikiTaskActionsTopicReader(deps *deps.Deps) *DreamWikiTaskActionsTo
 		}
 	}
 
-	reader := deps.YDBDriver.NewTopicReader(taskActionsTopicName, processTaskActionMessage)
+	reader := deps.Storage.NewTopicReader(taskActionsTopicName, processTaskActionMessage)
 
 	return &DreamWikiTaskActionsTopicReader{
 		deps:   deps,
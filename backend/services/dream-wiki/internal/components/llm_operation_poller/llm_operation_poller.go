@@ -0,0 +1,73 @@
+package llmoperationpoller
+
+import (
+	"context"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/component"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_actions_usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// LLMOperationPoller polls asynchronous LLM requests of submitted ask_llm
+// actions and emits action results when they are done. Pending operations
+// are stored, so requests survive restarts.
+type LLMOperationPoller struct {
+	deps *deps.Deps
+}
+
+func NewLLMOperationPoller(deps *deps.Deps) *LLMOperationPoller {
+	return &LLMOperationPoller{
+		deps: deps,
+	}
+}
+
+var _ component.Component = &LLMOperationPoller{}
+
+func (p *LLMOperationPoller) Name() string {
+	return "LLMOperationPoller"
+}
+
+func (p *LLMOperationPoller) Run(ctx context.Context) error {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := p.poll(ctx); err != nil {
+				p.deps.Logger.Error("failed to poll LLM operations", err)
+			}
+		}
+	}
+}
+
+func (p *LLMOperationPoller) poll(ctx context.Context) error {
+	operations, err := p.getPendingOperations(ctx)
+	if err != nil {
+		return err
+	}
+
+	taskActionUsecase := task_actions_usecase.NewTaskActionUsecase(ctx, p.deps)
+	for _, operation := range operations {
+		err := taskActionUsecase.PollLLMOperation(operation)
+		if err != nil {
+			p.deps.Logger.Error("failed to poll LLM operation",
+				"action_id", operation.TaskActionId,
+				"operation_id", operation.OperationId,
+				"error", err)
+		}
+	}
+	return nil
+}
+
+func (p *LLMOperationPoller) getPendingOperations(ctx context.Context) ([]internals.PendingLLMOperation, error) {
+	repo := p.deps.Storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	return repo.GetPendingLLMOperations()
+}
@@ -0,0 +1,113 @@
+package pagerevisionsreindexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/component"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+)
+
+const (
+	pageRevisionAppendedTopicName = "PageRevisionAppended"
+
+	// maxDelayFactor bounds how long continuous writes may postpone reindexation,
+	// in debounce delays.
+	maxDelayFactor = 6
+	checkInterval  = time.Second
+	// maxPendingWriteAttempts is how many times write of a pending page is tried,
+	// as it may conflict with scheduling of due pages.
+	maxPendingWriteAttempts = 3
+)
+
+// PageRevisionsReindexer reads the outbox of page writes and creates
+// reindexation tasks for written pages, one task for all pages due at once. A
+// page is due once it has not been written for debounce delay, or once
+// maxDelayFactor delays have passed since its first pending write, so that a
+// page edited all the time is still reindexated. Every path appending a
+// PageRevision feeds the outbox, so index follows content without explicit
+// reindexation calls.
+//
+// Topic messages are committed on receipt, so pending pages are stored in
+// PendingPageReindexation and survive restart.
+type PageRevisionsReindexer struct {
+	deps     *deps.Deps
+	reader   db_adapter.TopicReader
+	delay    time.Duration
+	maxDelay time.Duration
+}
+
+func NewPageRevisionsReindexer(d *deps.Deps) *PageRevisionsReindexer {
+	delay := time.Duration(d.Config.ReindexationDebounceSeconds) * time.Second
+	reindexer := &PageRevisionsReindexer{
+		deps:     d,
+		delay:    delay,
+		maxDelay: maxDelayFactor * delay,
+	}
+
+	processPageRevisionMessage := func(message []byte) {
+		pageID, err := uuid.Parse(string(message))
+		if err != nil {
+			d.Logger.Error("failed to parse page id of appended revision", "message", string(message), "error", err)
+			return
+		}
+		d.Logger.Debug("page revision appended, page_id=", pageID)
+
+		for attempt := 1; attempt <= maxPendingWriteAttempts; attempt++ {
+			err = usecase.NewAppUsecaseImpl(context.Background(), d).DeferPageReindexation(pageID, time.Now())
+			if err == nil {
+				return
+			}
+		}
+		d.Logger.Error("failed to remember written page, it is not reindexated", "page_id", pageID, "error", err)
+	}
+
+	reindexer.reader = d.Storage.NewTopicReader(pageRevisionAppendedTopicName, processPageRevisionMessage)
+	return reindexer
+}
+
+var _ component.Component = &PageRevisionsReindexer{}
+
+func (p *PageRevisionsReindexer) Name() string {
+	return "PageRevisionsReindexer"
+}
+
+func (p *PageRevisionsReindexer) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- p.reader.ReadMessages(ctx)
+	}()
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.deps.Logger.Info("page revisions reindexer is shutting down")
+			return nil
+		case err := <-errCh:
+			if err != nil && ctx.Err() == nil {
+				p.deps.Logger.Error("page revisions topic reader error", "error", err)
+				return err
+			}
+			return nil
+		case <-ticker.C:
+			p.scheduleDuePages(ctx)
+		}
+	}
+}
+
+// scheduleDuePages creates reindexation task for due pages. Pages stay pending
+// if it fails, so they are scheduled on the next check.
+func (p *PageRevisionsReindexer) scheduleDuePages(ctx context.Context) {
+	now := time.Now()
+	_, err := usecase.NewAppUsecaseImpl(ctx, p.deps).ScheduleDuePagesReindexation(now.Add(-p.delay), now.Add(-p.maxDelay))
+	if err != nil {
+		p.deps.Logger.Error("failed to create reindexation task, will retry", "error", err)
+	}
+}
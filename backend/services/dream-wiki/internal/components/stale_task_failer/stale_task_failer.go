@@ -4,7 +4,6 @@ import (
 	"context"
 	"time"
 
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/component"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
@@ -44,15 +43,10 @@ func (s *StaleTaskFailer) Run(ctx context.Context) error {
 }
 
 func (s *StaleTaskFailer) failStaleTasks(ctx context.Context) error {
-	readOnlyTx := s.deps.YDBDriver.NewTransaction(ctx, db_adapter.SnapshotReadOnly)
-	defer readOnlyTx.Rollback()
+	readOnlyRepo := s.deps.Storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer readOnlyRepo.Rollback()
 
-	repo := repository.NewAppRepository(ctx, &deps.RepositoryDeps{
-		Deps: s.deps,
-		TX:   readOnlyTx,
-	})
-
-	taskIDs, err := repo.GetStaleTaskIDs()
+	taskIDs, err := readOnlyRepo.GetStaleTaskIDs()
 	if err != nil {
 		return err
 	}
@@ -61,13 +55,8 @@ func (s *StaleTaskFailer) failStaleTasks(ctx context.Context) error {
 		return nil
 	}
 
-	tx := s.deps.YDBDriver.NewTransaction(ctx, db_adapter.SerializableReadWrite)
-	defer tx.Rollback()
-
-	repo = repository.NewAppRepository(ctx, &deps.RepositoryDeps{
-		Deps: s.deps,
-		TX:   tx,
-	})
+	repo := s.deps.Storage.NewRepository(ctx, db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
 
 	for _, taskID := range taskIDs {
 		if err := repo.SetTaskStatus(taskID, api.FailedByTimeout); err != nil {
@@ -75,7 +64,7 @@ func (s *StaleTaskFailer) failStaleTasks(ctx context.Context) error {
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := repo.Commit(); err != nil {
 		return err
 	}
 
@@ -0,0 +1,78 @@
+package vectorindexbuilder
+
+import (
+	"context"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/components/component"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/vectorindex"
+)
+
+// VectorIndexBuilder periodically rebuilds vector index of paragraph
+// embeddings from the storage. Reindexations committed in between are applied
+// to the index by repositories, so the rebuild only restores clustering
+// quality and picks up pages reindexated by other processes.
+type VectorIndexBuilder struct {
+	deps *deps.Deps
+}
+
+func NewVectorIndexBuilder(deps *deps.Deps) *VectorIndexBuilder {
+	return &VectorIndexBuilder{
+		deps: deps,
+	}
+}
+
+var _ component.Component = &VectorIndexBuilder{}
+
+func (b *VectorIndexBuilder) Name() string {
+	return "VectorIndexBuilder"
+}
+
+func (b *VectorIndexBuilder) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Duration(b.deps.Config.VectorIndexRebuildIntervalSeconds) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := b.rebuild(ctx); err != nil {
+			b.deps.Logger.Error("failed to rebuild vector index", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (b *VectorIndexBuilder) rebuild(ctx context.Context) error {
+	updatesSequence := b.deps.VectorIndex.UpdatesSequence()
+	repo := b.deps.Storage.NewRepository(ctx, db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	embeddings, err := repo.GetParagraphEmbeddings()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]vectorindex.Entry, 0, len(embeddings))
+	for _, embedding := range embeddings {
+		entries = append(entries, vectorindex.Entry{
+			Key: vectorindex.Key{
+				PageID:         embedding.PageId,
+				ParagraphIndex: embedding.ParagraphIndex,
+			},
+			Embedding: embedding.Embedding,
+		})
+	}
+	b.deps.VectorIndex.Rebuild(entries, updatesSequence)
+
+	diagnostics := b.deps.VectorIndex.Diagnostics()
+	b.deps.Logger.Info("vector index rebuilt",
+		"ready", diagnostics.Ready,
+		"entries", len(entries),
+		"build_duration", diagnostics.BuildDuration)
+	return nil
+}
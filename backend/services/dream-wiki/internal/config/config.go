@@ -3,11 +3,22 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
 	"go.uber.org/zap"
 )
 
+const (
+	StorageBackendYDB    = "ydb"
+	StorageBackendMemory = "memory"
+
+	LLMProviderYandexGPT = "yandexgpt"
+	LLMProviderOpenAI    = "openai"
+	LLMProviderMock      = "mock"
+)
+
 type Config struct {
 	LogMode          string
 	ServerPort       string
@@ -18,6 +29,44 @@ type Config struct {
 	YandexCloudOrgID string
 	GitHubToken      string
 	YandexCloudToken string
+
+	// Search ranking parameters, see usecase.Search.
+	SearchTermsWeight     float64
+	SearchEmbeddingWeight float64
+	SearchRRFK            float64
+
+	// Paragraph chunking before embedding, see indexing.ChunkingPolicy.
+	ChunkMaxTokens     int
+	ChunkMinTokens     int
+	ChunkOverlapTokens int
+
+	// Number of pages indexated concurrently by one reindexation task.
+	ReindexationConcurrency int
+	// Pause in page writes after which the page is reindexated, see
+	// PageRevisionsReindexer.
+	ReindexationDebounceSeconds int
+
+	// Embedding search vector index, see vectorindex.Index. Search scans all
+	// paragraphs while there are fewer than VectorIndexMinParagraphs of them.
+	VectorIndexMinParagraphs          int
+	VectorIndexProbes                 int
+	VectorIndexRebuildIntervalSeconds int
+
+	// LLMProvider is LLMProviderYandexGPT, LLMProviderOpenAI or
+	// LLMProviderMock, see llm_client.LLMClient.
+	LLMProvider string
+	// OpenAI-compatible chat completions server, e.g. http://localhost:11434/v1.
+	OpenAIAPIURL string
+	OpenAIAPIKey string
+	OpenAIModel  string
+	// JSON list of llm_client.ScriptedResponse answered by mock provider.
+	LLMMockScriptPath string
+
+	// StorageBackend is StorageBackendYDB or StorageBackendMemory, see
+	// repository.Storage. Memory storage loses data on restart.
+	StorageBackend string
+	// Accounts of memory storage, login to bcrypt password hash.
+	MemoryStorageUsers map[string]string
 }
 
 func checkEnv(envVars []string) error {
@@ -38,14 +87,12 @@ func checkEnv(envVars []string) error {
 func validateEnv() error {
 	err := checkEnv([]string{
 		"LOG_MODE",
-		"YDB_DSN",
 		"SERVER_PORT",
 		"INFERENCE_API_URL",
 		"JWT_SECRET_KEY",
 		"YWIKI_TOKEN",
 		"YANDEX_CLOUD_ORG_ID",
 		"GITHUB_TOKEN",
-		"YANDEX_CLOUD_TOKEN",
 	})
 	if err != nil {
 		return err
@@ -61,22 +108,212 @@ func getEnv(key string) string {
 	return result
 }
 
+// getEnvFloat returns value of optional numeric env var, or defaultValue if it is not set.
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value of %s: %w", key, err)
+	}
+	return result, nil
+}
+
+// getEnvInt returns value of optional integer env var, or defaultValue if it is not set.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value, exists := os.LookupEnv(key)
+	if !exists || value == "" {
+		return defaultValue, nil
+	}
+	result, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value of %s: %w", key, err)
+	}
+	return result, nil
+}
+
+// parseMemoryStorageUsers parses "login:hash,login:hash" list. Bcrypt hashes
+// contain neither ':' nor ','.
+func parseMemoryStorageUsers(value string) (map[string]string, error) {
+	users := make(map[string]string)
+	for _, user := range strings.Split(value, ",") {
+		if strings.TrimSpace(user) == "" {
+			continue
+		}
+		login, passwordHash, found := strings.Cut(strings.TrimSpace(user), ":")
+		if !found || login == "" || passwordHash == "" {
+			return nil, fmt.Errorf("invalid value of MEMORY_STORAGE_USERS: expected login:bcrypt_hash pairs separated by commas")
+		}
+		users[login] = passwordHash
+	}
+	return users, nil
+}
+
 func LoadConfig() (*Config, error) {
 	err := validateEnv()
 	if err != nil {
 		return nil, fmt.Errorf("LoadConfig: %w", err)
 	}
 
+	storageBackend := os.Getenv("STORAGE_BACKEND")
+	if storageBackend == "" {
+		storageBackend = StorageBackendYDB
+	}
+	switch storageBackend {
+	case StorageBackendYDB:
+		err = checkEnv([]string{"YDB_DSN"})
+		if err != nil {
+			return nil, fmt.Errorf("LoadConfig: %w", err)
+		}
+	case StorageBackendMemory:
+	default:
+		return nil, fmt.Errorf("LoadConfig: STORAGE_BACKEND must be %q or %q", StorageBackendYDB, StorageBackendMemory)
+	}
+
+	llmProvider := os.Getenv("LLM_PROVIDER")
+	if llmProvider == "" {
+		llmProvider = LLMProviderYandexGPT
+	}
+	switch llmProvider {
+	case LLMProviderYandexGPT:
+		err = checkEnv([]string{"YANDEX_CLOUD_TOKEN"})
+		if err != nil {
+			return nil, fmt.Errorf("LoadConfig: %w", err)
+		}
+	case LLMProviderOpenAI:
+		err = checkEnv([]string{"OPENAI_API_URL", "OPENAI_MODEL"})
+		if err != nil {
+			return nil, fmt.Errorf("LoadConfig: %w", err)
+		}
+	case LLMProviderMock:
+	default:
+		return nil, fmt.Errorf("LoadConfig: LLM_PROVIDER must be %q, %q or %q", LLMProviderYandexGPT, LLMProviderOpenAI, LLMProviderMock)
+	}
+
+	memoryStorageUsers, err := parseMemoryStorageUsers(os.Getenv("MEMORY_STORAGE_USERS"))
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+
+	searchTermsWeight, err := getEnvFloat("SEARCH_TERMS_WEIGHT", 1)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	searchEmbeddingWeight, err := getEnvFloat("SEARCH_EMBEDDING_WEIGHT", 1)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	searchRRFK, err := getEnvFloat("SEARCH_RRF_K", 60)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	chunkMaxTokens, err := getEnvInt("CHUNK_MAX_TOKENS", 256)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	chunkMinTokens, err := getEnvInt("CHUNK_MIN_TOKENS", 32)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	chunkOverlapTokens, err := getEnvInt("CHUNK_OVERLAP_TOKENS", 32)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	if chunkMaxTokens < 1 || chunkMinTokens < 0 || chunkMinTokens > chunkMaxTokens || chunkOverlapTokens < 0 || chunkOverlapTokens >= chunkMaxTokens {
+		return nil, fmt.Errorf("LoadConfig: chunk sizes must satisfy 0 <= CHUNK_MIN_TOKENS <= CHUNK_MAX_TOKENS and 0 <= CHUNK_OVERLAP_TOKENS < CHUNK_MAX_TOKENS")
+	}
+	reindexationConcurrency, err := getEnvInt("REINDEXATION_CONCURRENCY", 4)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	if reindexationConcurrency < 1 {
+		return nil, fmt.Errorf("LoadConfig: REINDEXATION_CONCURRENCY must be positive")
+	}
+	reindexationDebounceSeconds, err := getEnvInt("REINDEXATION_DEBOUNCE_SECONDS", 10)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	if reindexationDebounceSeconds < 0 {
+		return nil, fmt.Errorf("LoadConfig: REINDEXATION_DEBOUNCE_SECONDS must not be negative")
+	}
+	vectorIndexMinParagraphs, err := getEnvInt("VECTOR_INDEX_MIN_PARAGRAPHS", 1000)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	if vectorIndexMinParagraphs < 1 {
+		return nil, fmt.Errorf("LoadConfig: VECTOR_INDEX_MIN_PARAGRAPHS must be positive")
+	}
+	vectorIndexProbes, err := getEnvInt("VECTOR_INDEX_PROBES", 8)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	if vectorIndexProbes < 1 {
+		return nil, fmt.Errorf("LoadConfig: VECTOR_INDEX_PROBES must be positive")
+	}
+	vectorIndexRebuildIntervalSeconds, err := getEnvInt("VECTOR_INDEX_REBUILD_INTERVAL_SECONDS", 300)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfig: %w", err)
+	}
+	if vectorIndexRebuildIntervalSeconds < 1 {
+		return nil, fmt.Errorf("LoadConfig: VECTOR_INDEX_REBUILD_INTERVAL_SECONDS must be positive")
+	}
+
 	return &Config{
 		LogMode:          getEnv("LOG_MODE"),
 		ServerPort:       getEnv("SERVER_PORT"),
-		YDBDSN:           getEnv("YDB_DSN"),
+		YDBDSN:           os.Getenv("YDB_DSN"),
 		InferenceAPIURL:  getEnv("INFERENCE_API_URL"),
 		JWTSecretKey:     getEnv("JWT_SECRET_KEY"),
 		YWikiToken:       getEnv("YWIKI_TOKEN"),
 		YandexCloudOrgID: getEnv("YANDEX_CLOUD_ORG_ID"),
 		GitHubToken:      getEnv("GITHUB_TOKEN"),
-		YandexCloudToken: getEnv("YANDEX_CLOUD_TOKEN"),
+		YandexCloudToken: os.Getenv("YANDEX_CLOUD_TOKEN"),
+
+		SearchTermsWeight:     searchTermsWeight,
+		SearchEmbeddingWeight: searchEmbeddingWeight,
+		SearchRRFK:            searchRRFK,
+
+		ChunkMaxTokens:     chunkMaxTokens,
+		ChunkMinTokens:     chunkMinTokens,
+		ChunkOverlapTokens: chunkOverlapTokens,
+
+		ReindexationConcurrency:     reindexationConcurrency,
+		ReindexationDebounceSeconds: reindexationDebounceSeconds,
+
+		VectorIndexMinParagraphs:          vectorIndexMinParagraphs,
+		VectorIndexProbes:                 vectorIndexProbes,
+		VectorIndexRebuildIntervalSeconds: vectorIndexRebuildIntervalSeconds,
+
+		LLMProvider:       llmProvider,
+		OpenAIAPIURL:      os.Getenv("OPENAI_API_URL"),
+		OpenAIAPIKey:      os.Getenv("OPENAI_API_KEY"),
+		OpenAIModel:       os.Getenv("OPENAI_MODEL"),
+		LLMMockScriptPath: os.Getenv("LLM_MOCK_SCRIPT_PATH"),
+
+		StorageBackend:     storageBackend,
+		MemoryStorageUsers: memoryStorageUsers,
+	}, nil
+}
+
+// LoadMigrateConfig loads only settings needed by migrate command, so schema
+// can be migrated without configuration of the service itself.
+func LoadMigrateConfig() (*Config, error) {
+	err := checkEnv([]string{"YDB_DSN"})
+	if err != nil {
+		return nil, fmt.Errorf("LoadMigrateConfig: %w", err)
+	}
+
+	logMode := os.Getenv("LOG_MODE")
+	if logMode == "" {
+		logMode = "prod"
+	}
+
+	return &Config{
+		LogMode:        logMode,
+		YDBDSN:         getEnv("YDB_DSN"),
+		StorageBackend: StorageBackendYDB,
 	}, nil
 }
 
@@ -88,6 +325,22 @@ func LogConfig(config *Config, log logger.Logger) {
 		"SERVER_PORT",
 		"INFERENCE_API_URL",
 		"YANDEX_CLOUD_ORG_ID",
+		"SEARCH_TERMS_WEIGHT",
+		"SEARCH_EMBEDDING_WEIGHT",
+		"SEARCH_RRF_K",
+		"CHUNK_MAX_TOKENS",
+		"CHUNK_MIN_TOKENS",
+		"CHUNK_OVERLAP_TOKENS",
+		"REINDEXATION_CONCURRENCY",
+		"REINDEXATION_DEBOUNCE_SECONDS",
+		"VECTOR_INDEX_MIN_PARAGRAPHS",
+		"VECTOR_INDEX_PROBES",
+		"VECTOR_INDEX_REBUILD_INTERVAL_SECONDS",
+		"LLM_PROVIDER",
+		"OPENAI_API_URL",
+		"OPENAI_MODEL",
+		"LLM_MOCK_SCRIPT_PATH",
+		"STORAGE_BACKEND",
 	}
 	fields := make([]any, 0, len(loggedFields)+1)
 	fields = append(fields, "config loaded")
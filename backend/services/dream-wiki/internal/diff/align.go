@@ -0,0 +1,77 @@
+package diff
+
+import "slices"
+
+// AlignHunks widens hunks, so that every hunk starts and ends at one of the given
+// boundaries in both a and b. Hunks which touch after widening are merged.
+// Boundaries are line positions in [0, len]; 0 and len are always boundaries.
+func AlignHunks(hunks []Hunk, aBoundaries []int, aLen int, bBoundaries []int, bLen int) []Hunk {
+	aBoundaries = normalizeBoundaries(aBoundaries, aLen)
+	bBoundaries = normalizeBoundaries(bBoundaries, bLen)
+
+	aligned := make([]Hunk, 0, len(hunks))
+	for i, hunk := range hunks {
+		// Lines between hunks are equal in a and b, so hunk may be widened there
+		// by the same amount of lines on both sides.
+		nextAStart, nextBStart := aLen, bLen
+		if i+1 < len(hunks) {
+			nextAStart, nextBStart = hunks[i+1].AStart, hunks[i+1].BStart
+		}
+
+		current := hunk
+		for {
+			if len(aligned) > 0 && aligned[len(aligned)-1].AEnd >= current.AStart {
+				previous := aligned[len(aligned)-1]
+				aligned = aligned[:len(aligned)-1]
+				current.AStart, current.BStart = previous.AStart, previous.BStart
+			}
+
+			prevAEnd, prevBEnd := 0, 0
+			if len(aligned) > 0 {
+				prevAEnd, prevBEnd = aligned[len(aligned)-1].AEnd, aligned[len(aligned)-1].BEnd
+			}
+			maxBackward := min(current.AStart-prevAEnd, current.BStart-prevBEnd)
+			maxForward := min(nextAStart-current.AEnd, nextBStart-current.BEnd)
+
+			backward := min(max(current.AStart-floorBoundary(aBoundaries, current.AStart), current.BStart-floorBoundary(bBoundaries, current.BStart)), maxBackward)
+			forward := min(max(ceilBoundary(aBoundaries, current.AEnd)-current.AEnd, ceilBoundary(bBoundaries, current.BEnd)-current.BEnd), maxForward)
+
+			merge := len(aligned) > 0 && backward == maxBackward && backward > 0
+			if backward == 0 && forward == 0 && !merge {
+				break
+			}
+
+			current.AStart -= backward
+			current.BStart -= backward
+			current.AEnd += forward
+			current.BEnd += forward
+			if merge {
+				// Widened hunk reached previous one, they are merged on next iteration.
+				continue
+			}
+		}
+
+		aligned = append(aligned, current)
+	}
+
+	return aligned
+}
+
+func normalizeBoundaries(boundaries []int, length int) []int {
+	normalized := append([]int{0, length}, boundaries...)
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+func floorBoundary(boundaries []int, position int) int {
+	index, found := slices.BinarySearch(boundaries, position)
+	if found {
+		return position
+	}
+	return boundaries[index-1]
+}
+
+func ceilBoundary(boundaries []int, position int) int {
+	index, _ := slices.BinarySearch(boundaries, position)
+	return boundaries[index]
+}
@@ -0,0 +1,194 @@
+package diff
+
+import "strings"
+
+type (
+	// Hunk tells that lines a[AStart:AEnd] are replaced with lines b[BStart:BEnd].
+	// Pure insertion has AStart == AEnd, pure deletion has BStart == BEnd.
+	Hunk struct {
+		AStart int
+		AEnd   int
+		BStart int
+		BEnd   int
+	}
+)
+
+// SplitLines splits text to lines without line terminators. JoinLines(SplitLines(s)) == s.
+func SplitLines(text string) []string {
+	return strings.Split(text, "\n")
+}
+
+func JoinLines(lines []string) string {
+	return strings.Join(lines, "\n")
+}
+
+// Lines returns hunks which turn a into b. Hunks are ordered and do not overlap.
+func Lines(a, b []string) []Hunk {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	hunks := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for i := range hunks {
+		hunks[i].AStart += prefix
+		hunks[i].AEnd += prefix
+		hunks[i].BStart += prefix
+		hunks[i].BEnd += prefix
+	}
+	return hunks
+}
+
+type (
+	// myersState holds lines being compared and the edit script found so far.
+	// Frontiers of the forward and backward searches are reused by every
+	// subproblem, so memory stays linear in the size of input.
+	myersState struct {
+		a        []string
+		b        []string
+		deleted  []bool
+		inserted []bool
+		forward  []int
+		backward []int
+	}
+)
+
+// myers finds the shortest edit script with linear space variation of Myers'
+// O((N+M)D) algorithm and groups adjacent edits to hunks.
+func myers(a, b []string) []Hunk {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return []Hunk{}
+	}
+
+	frontierSize := n + m + 5
+	s := &myersState{
+		a:        a,
+		b:        b,
+		deleted:  make([]bool, n),
+		inserted: make([]bool, m),
+		forward:  make([]int, frontierSize),
+		backward: make([]int, frontierSize),
+	}
+	s.compare(0, n, 0, m)
+	deleted, inserted := s.deleted, s.inserted
+
+	hunks := make([]Hunk, 0)
+	i, j := 0, 0
+	for i < n || j < m {
+		if i < n && j < m && !deleted[i] && !inserted[j] {
+			i++
+			j++
+			continue
+		}
+		hunk := Hunk{AStart: i, BStart: j}
+		for {
+			if i < n && (deleted[i] || j >= m) {
+				i++
+			} else if j < m && (inserted[j] || i >= n) {
+				j++
+			} else {
+				break
+			}
+		}
+		hunk.AEnd, hunk.BEnd = i, j
+		hunks = append(hunks, hunk)
+	}
+	return hunks
+}
+
+// compare marks edits which turn a[aLo:aHi] into b[bLo:bHi]. The middle snake
+// of the shortest edit script splits the problem into two with fewer edits.
+func (s *myersState) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && s.a[aLo] == s.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && s.a[aHi-1] == s.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			s.inserted[j] = true
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			s.deleted[i] = true
+		}
+	default:
+		// Both ranges are non-empty and differ at both ends, so there are at
+		// least two edits and each half gets fewer of them.
+		x, y, u, v := s.middleSnake(aLo, aHi, bLo, bHi)
+		s.compare(aLo, x, bLo, y)
+		s.compare(u, aHi, v, bHi)
+	}
+}
+
+// middleSnake searches for the shortest edit script from both ends at once
+// and returns the snake (x, y) -> (u, v) where the searches meet.
+func (s *myersState) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+
+	// forward[offset+k] is the furthest x on diagonal k = x - y reached from
+	// (0, 0), backward[offset+k] is the same for reversed ranges.
+	forward, backward := s.forward, s.backward
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && s.a[aLo+x] == s.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+
+			backwardK := delta - k
+			if odd && backwardK >= -(d-1) && backwardK <= d-1 && x+backward[offset+backwardK] >= n {
+				return aLo + startX, bLo + startY, aLo + x, bLo + y
+			}
+		}
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			startX, startY := x, y
+			for x < n && y < m && s.a[aHi-1-x] == s.b[bHi-1-y] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+
+			forwardK := delta - k
+			if !odd && forwardK >= -d && forwardK <= d && x+forward[offset+forwardK] >= n {
+				return aHi - x, bHi - y, aHi - startX, bHi - startY
+			}
+		}
+	}
+
+	panic("diff: middle snake not found")
+}
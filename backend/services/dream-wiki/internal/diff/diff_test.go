@@ -0,0 +1,348 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func applyAll(a, b []string, hunks []Hunk) []string {
+	return applyHunks(a, b, hunks, 0, len(a))
+}
+
+func TestLines(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected []Hunk
+	}{
+		{
+			name:     "equal",
+			a:        "a\nb\nc",
+			b:        "a\nb\nc",
+			expected: []Hunk{},
+		},
+		{
+			name:     "replace middle line",
+			a:        "a\nb\nc",
+			b:        "a\nB\nc",
+			expected: []Hunk{{AStart: 1, AEnd: 2, BStart: 1, BEnd: 2}},
+		},
+		{
+			name:     "insert and delete",
+			a:        "a\nb\nc\nd",
+			b:        "x\na\nc\nd\ny",
+			expected: []Hunk{{AStart: 0, AEnd: 0, BStart: 0, BEnd: 1}, {AStart: 1, AEnd: 2, BStart: 2, BEnd: 2}, {AStart: 4, AEnd: 4, BStart: 4, BEnd: 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hunks := Lines(SplitLines(tt.a), SplitLines(tt.b))
+			require.Equal(t, tt.expected, hunks)
+		})
+	}
+}
+
+func longestCommonSubsequence(a, b []string) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	return lengths[0][0]
+}
+
+func editsCount(hunks []Hunk) int {
+	count := 0
+	for _, hunk := range hunks {
+		count += hunk.AEnd - hunk.AStart + hunk.BEnd - hunk.BStart
+	}
+	return count
+}
+
+func TestLinesUnrelatedTexts(t *testing.T) {
+	t.Parallel()
+
+	a := make([]string, 4000)
+	b := make([]string, 4000)
+	for i := range a {
+		a[i] = fmt.Sprintf("a%d", i)
+		b[i] = fmt.Sprintf("b%d", i)
+	}
+	// Shared line in the middle keeps the edit script from being trivial.
+	a[2000], b[1000] = "common", "common"
+
+	hunks := Lines(a, b)
+	require.Equal(t, b, applyAll(a, b, hunks))
+	require.Equal(t, len(a)+len(b)-2, editsCount(hunks))
+}
+
+func TestLinesRandom(t *testing.T) {
+	t.Parallel()
+
+	rnd := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rnd.Intn(30))
+		for i := range lines {
+			lines[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return lines
+	}
+
+	for range 200 {
+		a, b := randomLines(), randomLines()
+		hunks := Lines(a, b)
+		require.Equal(t, b, applyAll(a, b, hunks), "a=%v b=%v", a, b)
+		require.Equal(t, len(a)+len(b)-2*longestCommonSubsequence(a, b), editsCount(hunks), "a=%v b=%v", a, b)
+
+		// Merge with unchanged side gives the changed one.
+		require.Equal(t, MergeResult{Lines: b, Conflicts: []Conflict{}}, Merge3(a, b, a), "a=%v b=%v", a, b)
+		require.Equal(t, MergeResult{Lines: b, Conflicts: []Conflict{}}, Merge3(a, a, b), "a=%v b=%v", a, b)
+	}
+}
+
+func TestMerge3(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		base              string
+		ours              string
+		theirs            string
+		expected          string
+		expectedConflicts int
+	}{
+		{
+			name:     "changes in different places",
+			base:     "a\nb\nc\nd\ne",
+			ours:     "a\nB\nc\nd\ne",
+			theirs:   "a\nb\nc\nD\ne",
+			expected: "a\nB\nc\nD\ne",
+		},
+		{
+			name:     "same change on both sides",
+			base:     "a\nb\nc",
+			ours:     "a\nB\nc",
+			theirs:   "a\nB\nc",
+			expected: "a\nB\nc",
+		},
+		{
+			name:     "only theirs changed",
+			base:     "a\nb",
+			ours:     "a\nb",
+			theirs:   "a\nb\nc",
+			expected: "a\nb\nc",
+		},
+		{
+			name:              "conflicting change",
+			base:              "a\nb\nc",
+			ours:              "a\nours\nc",
+			theirs:            "a\ntheirs\nc",
+			expected:          strings.Join([]string{"a", conflictStartMarker, "ours", conflictSeparatorMarker, "theirs", conflictEndMarker, "c"}, "\n"),
+			expectedConflicts: 1,
+		},
+		{
+			name:              "insertions at the same place",
+			base:              "a\nb",
+			ours:              "a\nx\nb",
+			theirs:            "a\ny\nb",
+			expected:          strings.Join([]string{"a", conflictStartMarker, "x", conflictSeparatorMarker, "y", conflictEndMarker, "b"}, "\n"),
+			expectedConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := Merge3(SplitLines(tt.base), SplitLines(tt.ours), SplitLines(tt.theirs))
+			require.Equal(t, tt.expected, JoinLines(result.Lines))
+			require.Len(t, result.Conflicts, tt.expectedConflicts)
+		})
+	}
+
+	t.Run("conflict hunk", func(t *testing.T) {
+		t.Parallel()
+
+		result := Merge3(SplitLines("a\nb\nc"), SplitLines("a\nours\nc"), SplitLines("a\ntheirs\nc"))
+		require.Equal(t, []Conflict{{
+			MergedLineIndex: 1,
+			BaseLines:       []string{"b"},
+			OursLines:       []string{"ours"},
+			TheirsLines:     []string{"theirs"},
+		}}, result.Conflicts)
+	})
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		text     string
+		expected bool
+	}{
+		{name: "no markers", text: "Title\n=========\ntext", expected: false},
+		{name: "whole conflict", text: "a\n<<<<<<< draft\nours\n=======\ntheirs\n>>>>>>> page\nb", expected: true},
+		{name: "start marker left", text: "a\n<<<<<<< draft\nours", expected: true},
+		{name: "separator left", text: "ours\n=======\ntheirs", expected: true},
+		{name: "end marker left", text: "theirs\n>>>>>>> page", expected: true},
+		{name: "base marker left", text: "ours\n||||||| base\nbase", expected: true},
+		{name: "marker without label", text: "ours\n>>>>>>>", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, HasConflictMarkers(SplitLines(tt.text)))
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		a            string
+		b            string
+		contextLines int
+		expected     string
+	}{
+		{
+			name:         "equal",
+			a:            "a\nb",
+			b:            "a\nb",
+			contextLines: 3,
+			expected:     "",
+		},
+		{
+			name:         "replace with context",
+			a:            "a\nb\nc\nd\ne",
+			b:            "a\nb\nC\nd\ne",
+			contextLines: 1,
+			expected:     "--- old\n+++ new\n@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n",
+		},
+		{
+			name:         "distant changes are split",
+			a:            "a\nb\nc\nd\ne\nf",
+			b:            "A\nb\nc\nd\ne\nF",
+			contextLines: 1,
+			expected:     "--- old\n+++ new\n@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -5,2 +5,2 @@\n e\n-f\n+F\n",
+		},
+		{
+			name:         "insertion into empty context",
+			a:            "a",
+			b:            "a\nb",
+			contextLines: 0,
+			expected:     "--- old\n+++ new\n@@ -1,0 +2 @@\n+b\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, Unified("old", "new", SplitLines(tt.a), SplitLines(tt.b), tt.contextLines))
+		})
+	}
+}
+
+func TestWords(t *testing.T) {
+	t.Parallel()
+
+	chunks := Words("Hello, big world!", "Hello, small world!")
+	require.Equal(t, []WordChunk{
+		{Kind: ChunkEqual, Text: "Hello, "},
+		{Kind: ChunkDeleted, Text: "big"},
+		{Kind: ChunkInserted, Text: "small"},
+		{Kind: ChunkEqual, Text: " world!"},
+	}, chunks)
+
+	a, b := "", ""
+	for _, chunk := range Words("один два три", "один три четыре") {
+		if chunk.Kind != ChunkInserted {
+			a += chunk.Text
+		}
+		if chunk.Kind != ChunkDeleted {
+			b += chunk.Text
+		}
+	}
+	require.Equal(t, "один два три", a)
+	require.Equal(t, "один три четыре", b)
+}
+
+func TestAlignHunks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		a           string
+		b           string
+		aBoundaries []int
+		bBoundaries []int
+		expected    []Hunk
+	}{
+		{
+			name:        "hunk is widened to block",
+			a:           "a\nb\nc\n\nd",
+			b:           "a\nB\nc\n\nd",
+			aBoundaries: []int{0, 3, 4, 5},
+			bBoundaries: []int{0, 3, 4, 5},
+			expected:    []Hunk{{AStart: 0, AEnd: 3, BStart: 0, BEnd: 3}},
+		},
+		{
+			name:        "hunks of the same block are merged",
+			a:           "a\nb\nc\n\nd",
+			b:           "A\nb\nC\n\nd",
+			aBoundaries: []int{0, 3, 4, 5},
+			bBoundaries: []int{0, 3, 4, 5},
+			expected:    []Hunk{{AStart: 0, AEnd: 3, BStart: 0, BEnd: 3}},
+		},
+		{
+			name:        "hunks of different blocks stay separate",
+			a:           "a\n\nb\n\nc",
+			b:           "A\n\nb\n\nC",
+			aBoundaries: []int{0, 1, 2, 3, 4, 5},
+			bBoundaries: []int{0, 1, 2, 3, 4, 5},
+			expected:    []Hunk{{AStart: 0, AEnd: 1, BStart: 0, BEnd: 1}, {AStart: 4, AEnd: 5, BStart: 4, BEnd: 5}},
+		},
+		{
+			name:        "inserted block is not widened",
+			a:           "a\n\nb",
+			b:           "a\n\nx\n\nb",
+			aBoundaries: []int{0, 1, 2, 3},
+			bBoundaries: []int{0, 1, 2, 3, 4, 5},
+			expected:    []Hunk{{AStart: 2, AEnd: 2, BStart: 2, BEnd: 4}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := SplitLines(tt.a), SplitLines(tt.b)
+			hunks := AlignHunks(Lines(a, b), tt.aBoundaries, len(a), tt.bBoundaries, len(b))
+			require.Equal(t, tt.expected, hunks)
+			require.Equal(t, b, applyAll(a, b, hunks))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	conflictStartMarker     = "<<<<<<< draft"
+	conflictBaseMarker      = "|||||||"
+	conflictSeparatorMarker = "======="
+	conflictEndMarker       = ">>>>>>> page"
+)
+
+type (
+	// Conflict describes lines changed differently by both sides of the merge.
+	// MergedLineIndex points to the start marker of the conflict in the merged text.
+	Conflict struct {
+		MergedLineIndex int
+		BaseLines       []string
+		OursLines       []string
+		TheirsLines     []string
+	}
+
+	MergeResult struct {
+		Lines     []string
+		Conflicts []Conflict
+	}
+)
+
+// Merge3 applies changes made from base to ours and from base to theirs. Changes
+// which touch the same base lines and differ are conflicts. They are written to the
+// result between git-like markers, ours goes first.
+func Merge3(base, ours, theirs []string) MergeResult {
+	oursHunks := Lines(base, ours)
+	theirsHunks := Lines(base, theirs)
+
+	result := MergeResult{Lines: make([]string, 0, len(base)), Conflicts: make([]Conflict, 0)}
+	basePos := 0
+	i, j := 0, 0
+	for i < len(oursHunks) || j < len(theirsHunks) {
+		groupStart := -1
+		if i < len(oursHunks) {
+			groupStart = oursHunks[i].AStart
+		}
+		if j < len(theirsHunks) && (groupStart < 0 || theirsHunks[j].AStart < groupStart) {
+			groupStart = theirsHunks[j].AStart
+		}
+
+		// Collect hunks of both sides which overlap with the group. Insertions at the
+		// same position overlap too, because their order is unknown.
+		groupEnd := groupStart
+		oursFrom, theirsFrom := i, j
+		for {
+			if i < len(oursHunks) && overlaps(oursHunks[i], groupStart, groupEnd) {
+				groupEnd = max(groupEnd, oursHunks[i].AEnd)
+				i++
+			} else if j < len(theirsHunks) && overlaps(theirsHunks[j], groupStart, groupEnd) {
+				groupEnd = max(groupEnd, theirsHunks[j].AEnd)
+				j++
+			} else {
+				break
+			}
+		}
+
+		result.Lines = append(result.Lines, base[basePos:groupStart]...)
+
+		oursLines := applyHunks(base, ours, oursHunks[oursFrom:i], groupStart, groupEnd)
+		theirsLines := applyHunks(base, theirs, theirsHunks[theirsFrom:j], groupStart, groupEnd)
+		switch {
+		case oursFrom == i:
+			result.Lines = append(result.Lines, theirsLines...)
+		case theirsFrom == j || slices.Equal(oursLines, theirsLines):
+			result.Lines = append(result.Lines, oursLines...)
+		default:
+			result.Conflicts = append(result.Conflicts, Conflict{
+				MergedLineIndex: len(result.Lines),
+				BaseLines:       slices.Clone(base[groupStart:groupEnd]),
+				OursLines:       oursLines,
+				TheirsLines:     theirsLines,
+			})
+			result.Lines = append(result.Lines, conflictStartMarker)
+			result.Lines = append(result.Lines, oursLines...)
+			result.Lines = append(result.Lines, conflictSeparatorMarker)
+			result.Lines = append(result.Lines, theirsLines...)
+			result.Lines = append(result.Lines, conflictEndMarker)
+		}
+
+		basePos = groupEnd
+	}
+	result.Lines = append(result.Lines, base[basePos:]...)
+
+	return result
+}
+
+// HasConflictMarkers tells whether lines still contain any marker line of a
+// conflict, so a partially resolved conflict is not taken for resolution.
+func HasConflictMarkers(lines []string) bool {
+	return slices.ContainsFunc(lines, isConflictMarker)
+}
+
+// isConflictMarker matches marker lines with or without labels after them. Base
+// marker is not written by Merge3, but appears when conflict is resolved in an
+// editor showing conflicts in diff3 style.
+func isConflictMarker(line string) bool {
+	if line == conflictSeparatorMarker {
+		return true
+	}
+	for _, marker := range []string{conflictStartMarker, conflictBaseMarker, conflictEndMarker} {
+		marker, _, _ = strings.Cut(marker, " ")
+		if line == marker || strings.HasPrefix(line, marker+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(hunk Hunk, groupStart int, groupEnd int) bool {
+	return hunk.AStart < groupEnd || hunk.AStart == groupStart
+}
+
+// applyHunks returns lines base[start:end] with hunks applied.
+func applyHunks(base []string, other []string, hunks []Hunk, start int, end int) []string {
+	lines := make([]string, 0)
+	pos := start
+	for _, hunk := range hunks {
+		lines = append(lines, base[pos:hunk.AStart]...)
+		lines = append(lines, other[hunk.BStart:hunk.BEnd]...)
+		pos = hunk.AEnd
+	}
+	return append(lines, base[pos:end]...)
+}
@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified formats difference between a and b in unified diff format with given
+// number of context lines around every change. Equal inputs give empty string.
+func Unified(aName, bName string, a, b []string, contextLines int) string {
+	hunks := Lines(a, b)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", aName, bName)
+
+	// Hunks closer than 2*contextLines share context, so they are printed together.
+	for groupStart := 0; groupStart < len(hunks); {
+		groupEnd := groupStart + 1
+		for groupEnd < len(hunks) && hunks[groupEnd].AStart-hunks[groupEnd-1].AEnd <= 2*contextLines {
+			groupEnd++
+		}
+		writeUnifiedGroup(&builder, a, b, hunks[groupStart:groupEnd], contextLines)
+		groupStart = groupEnd
+	}
+
+	return builder.String()
+}
+
+func writeUnifiedGroup(builder *strings.Builder, a, b []string, hunks []Hunk, contextLines int) {
+	first, last := hunks[0], hunks[len(hunks)-1]
+	aStart := max(first.AStart-contextLines, 0)
+	bStart := first.BStart - (first.AStart - aStart)
+	aEnd := min(last.AEnd+contextLines, len(a))
+	bEnd := last.BEnd + (aEnd - last.AEnd)
+
+	fmt.Fprintf(builder, "@@ -%s +%s @@\n", unifiedRange(aStart, aEnd), unifiedRange(bStart, bEnd))
+
+	i := aStart
+	for _, hunk := range hunks {
+		for ; i < hunk.AStart; i++ {
+			builder.WriteString(" " + a[i] + "\n")
+		}
+		for _, line := range a[hunk.AStart:hunk.AEnd] {
+			builder.WriteString("-" + line + "\n")
+		}
+		for _, line := range b[hunk.BStart:hunk.BEnd] {
+			builder.WriteString("+" + line + "\n")
+		}
+		i = hunk.AEnd
+	}
+	for ; i < aEnd; i++ {
+		builder.WriteString(" " + a[i] + "\n")
+	}
+}
+
+// unifiedRange formats 1-based line range. Empty range points to the line before it.
+func unifiedRange(start, end int) string {
+	if end-start == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	if end == start {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, end-start)
+}
@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+type (
+	ChunkKind int
+
+	// WordChunk is a piece of text which is equal in both texts, or only present in
+	// one of them.
+	WordChunk struct {
+		Kind ChunkKind
+		Text string
+	}
+)
+
+const (
+	ChunkEqual ChunkKind = iota
+	ChunkDeleted
+	ChunkInserted
+)
+
+// Words returns word-level difference between a and b. Concatenation of equal and
+// deleted chunks gives a, concatenation of equal and inserted chunks gives b.
+func Words(a, b string) []WordChunk {
+	aTokens := splitWords(a)
+	bTokens := splitWords(b)
+
+	chunks := make([]WordChunk, 0)
+	appendChunk := func(kind ChunkKind, tokens []string) {
+		if len(tokens) == 0 {
+			return
+		}
+		text := strings.Join(tokens, "")
+		if len(chunks) > 0 && chunks[len(chunks)-1].Kind == kind {
+			chunks[len(chunks)-1].Text += text
+			return
+		}
+		chunks = append(chunks, WordChunk{Kind: kind, Text: text})
+	}
+
+	i := 0
+	for _, hunk := range Lines(aTokens, bTokens) {
+		appendChunk(ChunkEqual, aTokens[i:hunk.AStart])
+		appendChunk(ChunkDeleted, aTokens[hunk.AStart:hunk.AEnd])
+		appendChunk(ChunkInserted, bTokens[hunk.BStart:hunk.BEnd])
+		i = hunk.AEnd
+	}
+	appendChunk(ChunkEqual, aTokens[i:])
+
+	return chunks
+}
+
+// splitWords splits text to words, whitespace runs and single punctuation runes,
+// so that joining tokens gives the text back.
+func splitWords(text string) []string {
+	tokens := make([]string, 0)
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		switch {
+		case isWordRune(runes[start]):
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+		case unicode.IsSpace(runes[start]):
+			for end < len(runes) && unicode.IsSpace(runes[end]) {
+				end++
+			}
+		}
+		tokens = append(tokens, string(runes[start:end]))
+		start = end
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
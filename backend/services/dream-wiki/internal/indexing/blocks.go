@@ -0,0 +1,39 @@
+package indexing
+
+import "github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+
+type (
+	// ParagraphBlock is a range of page lines [StartLine, EndLine) which
+	// SplitPageToChunks turns into paragraphs with ParagraphIndexes. A paragraph
+	// of merged blocks belongs to all of them. Lines between blocks are blank and
+	// belong to no block.
+	ParagraphBlock struct {
+		StartLine        int
+		EndLine          int
+		ParagraphIndexes []int
+	}
+)
+
+func SplitPageToBlocks(page string, policy ChunkingPolicy) []ParagraphBlock {
+	blocks := make([]ParagraphBlock, 0)
+	chunks := SplitPageToChunks(api.PageID{}, page, policy)
+
+	// Both line numbers of chunks do not decrease.
+	firstChunk := 0
+	for _, markdownBlock := range parseMarkdownBlocks(page) {
+		block := ParagraphBlock{
+			StartLine:        markdownBlock.startLine,
+			EndLine:          markdownBlock.endLine,
+			ParagraphIndexes: []int{},
+		}
+		for firstChunk < len(chunks) && chunks[firstChunk].EndLineNumber < block.StartLine {
+			firstChunk++
+		}
+		for i := firstChunk; !markdownBlock.skip && i < len(chunks) && chunks[i].LineNumber < block.EndLine; i++ {
+			block.ParagraphIndexes = append(block.ParagraphIndexes, chunks[i].ParagraphIndex)
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
@@ -0,0 +1,209 @@
+package indexing
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// charsPerToken is a rough size of a token. Tokenizer of the embedding model is
+// not available here, so sizes are estimated by characters.
+const charsPerToken = 4
+
+var sentenceEndRegex = regexp.MustCompile(`[.!?…]+["»”')\]]*\s+`)
+
+type (
+	// ChunkingPolicy controls sizes of paragraphs sent to the embedding model.
+	// All sizes are in estimated tokens, see EstimateTokens.
+	ChunkingPolicy struct {
+		// MaxTokens is the size above which paragraph is split.
+		MaxTokens int
+		// MinTokens is the size below which paragraph is merged with its
+		// neighbours of the same section.
+		MinTokens int
+		// OverlapTokens is how much of the previous piece of a split paragraph is
+		// repeated at the start of the next one.
+		OverlapTokens int
+	}
+
+	// chunkUnit is a sentence or a line of paragraph content, [start, end) in bytes.
+	chunkUnit struct {
+		start  int
+		end    int
+		tokens int
+	}
+)
+
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// SplitPageToChunks splits page into paragraphs the way it is indexed.
+func SplitPageToChunks(pageID api.PageID, page string, policy ChunkingPolicy) []internals.ParagraphWithEmbedding {
+	return ChunkParagraphs(SplitPageToParagraphs(pageID, page), policy)
+}
+
+// ChunkParagraphs splits oversized paragraphs at sentence boundaries (at line
+// boundaries for code, tables and lists) and then merges tiny adjacent ones
+// with the same headers. Header paragraphs are kept as is. Chunks are numbered
+// anew, and their line ranges point to the page lines they were taken from.
+func ChunkParagraphs(paragraphs []internals.ParagraphWithEmbedding, policy ChunkingPolicy) []internals.ParagraphWithEmbedding {
+	split := make([]internals.ParagraphWithEmbedding, 0, len(paragraphs))
+	for _, paragraph := range paragraphs {
+		if paragraph.IsHeader || EstimateTokens(paragraph.Content) <= policy.MaxTokens {
+			split = append(split, paragraph)
+			continue
+		}
+		split = append(split, splitParagraph(paragraph, policy)...)
+	}
+
+	chunks := make([]internals.ParagraphWithEmbedding, 0, len(split))
+	for _, paragraph := range split {
+		if len(chunks) > 0 && canMerge(chunks[len(chunks)-1], paragraph, policy) {
+			chunks[len(chunks)-1] = mergeParagraphs(chunks[len(chunks)-1], paragraph)
+			continue
+		}
+		chunks = append(chunks, paragraph)
+	}
+
+	for i := range chunks {
+		chunks[i].ParagraphIndex = i
+	}
+	return chunks
+}
+
+func canMerge(previous internals.ParagraphWithEmbedding, next internals.ParagraphWithEmbedding, policy ChunkingPolicy) bool {
+	if previous.IsHeader || next.IsHeader || !slices.Equal(previous.Headers, next.Headers) {
+		return false
+	}
+	previousTokens := EstimateTokens(previous.Content)
+	nextTokens := EstimateTokens(next.Content)
+	if previousTokens >= policy.MinTokens && nextTokens >= policy.MinTokens {
+		return false
+	}
+	return previousTokens+nextTokens <= policy.MaxTokens
+}
+
+func mergeParagraphs(previous internals.ParagraphWithEmbedding, next internals.ParagraphWithEmbedding) internals.ParagraphWithEmbedding {
+	merged := previous
+	merged.Content = previous.Content + "\n\n" + next.Content
+	merged.EndLineNumber = next.EndLineNumber
+	if previous.BlockType != next.BlockType {
+		merged.BlockType = internals.Text
+	}
+	return merged
+}
+
+func splitParagraph(paragraph internals.ParagraphWithEmbedding, policy ChunkingPolicy) []internals.ParagraphWithEmbedding {
+	content := paragraph.Content
+	bySentences := paragraph.BlockType == internals.Text || paragraph.BlockType == internals.Quote
+	var units []chunkUnit
+	if bySentences {
+		units = sentenceUnits(content)
+	} else {
+		units = lineUnits(content)
+	}
+	units = splitLongUnits(content, units, policy.MaxTokens)
+
+	pieces := make([]internals.ParagraphWithEmbedding, 0)
+	first := 0
+	for first < len(units) {
+		// Piece starts with the tail of the previous one, which fits into overlap
+		// and leaves room for the first new unit.
+		start := first
+		tokens := 0
+		for start > 0 && tokens+units[start-1].tokens <= policy.OverlapTokens && tokens+units[start-1].tokens+units[first].tokens <= policy.MaxTokens {
+			start--
+			tokens += units[start].tokens
+		}
+
+		last := first
+		tokens += units[first].tokens
+		for last+1 < len(units) && tokens+units[last+1].tokens <= policy.MaxTokens {
+			last++
+			tokens += units[last].tokens
+		}
+
+		piece := paragraph
+		// Line units keep indentation, which matters in code and nested lists.
+		piece.Content = strings.TrimRight(content[units[start].start:units[last].end], " \t\n")
+		if bySentences {
+			piece.Content = strings.TrimSpace(piece.Content)
+		}
+		piece.LineNumber = paragraph.LineNumber + strings.Count(content[:units[start].start], "\n")
+		piece.EndLineNumber = paragraph.LineNumber + strings.Count(strings.TrimRight(content[:units[last].end], " \t\n"), "\n")
+		pieces = append(pieces, piece)
+
+		first = last + 1
+	}
+	return pieces
+}
+
+func sentenceUnits(content string) []chunkUnit {
+	units := make([]chunkUnit, 0)
+	start := 0
+	for _, match := range sentenceEndRegex.FindAllStringIndex(content, -1) {
+		units = append(units, chunkUnit{start: start, end: match[1], tokens: EstimateTokens(content[start:match[1]])})
+		start = match[1]
+	}
+	if start < len(content) {
+		units = append(units, chunkUnit{start: start, end: len(content), tokens: EstimateTokens(content[start:])})
+	}
+	return units
+}
+
+func lineUnits(content string) []chunkUnit {
+	units := make([]chunkUnit, 0)
+	start := 0
+	for start < len(content) {
+		end := strings.IndexByte(content[start:], '\n')
+		if end < 0 {
+			end = len(content)
+		} else {
+			end += start + 1
+		}
+		units = append(units, chunkUnit{start: start, end: end, tokens: EstimateTokens(content[start:end])})
+		start = end
+	}
+	return units
+}
+
+// splitLongUnits cuts units bigger than maxTokens at whitespace, or anywhere if
+// there is no whitespace.
+func splitLongUnits(content string, units []chunkUnit, maxTokens int) []chunkUnit {
+	result := make([]chunkUnit, 0, len(units))
+	for _, unit := range units {
+		for unit.tokens > maxTokens {
+			end := cutPosition(content[unit.start:unit.end], maxTokens*charsPerToken) + unit.start
+			result = append(result, chunkUnit{start: unit.start, end: end, tokens: EstimateTokens(content[unit.start:end])})
+			unit = chunkUnit{start: end, end: unit.end, tokens: EstimateTokens(content[end:unit.end])}
+		}
+		result = append(result, unit)
+	}
+	return result
+}
+
+// cutPosition returns byte position after the last whitespace within the first
+// maxRunes runes of text.
+func cutPosition(text string, maxRunes int) int {
+	limit := len(text)
+	runes := 0
+	for position := range text {
+		if runes == maxRunes {
+			limit = position
+			break
+		}
+		runes++
+	}
+
+	if space := strings.LastIndexFunc(text[:limit], unicode.IsSpace); space > 0 {
+		_, size := utf8.DecodeRuneInString(text[space:])
+		return space + size
+	}
+	return limit
+}
@@ -0,0 +1,103 @@
+package indexing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+func TestChunkParagraphs(t *testing.T) {
+	t.Parallel()
+
+	pageID := api.PageID{1}
+
+	t.Run("long text is split at sentences with overlap", func(t *testing.T) {
+		t.Parallel()
+
+		// Sentences with trailing spaces are 21 characters, that is 6 tokens.
+		content := "# Title\n\nSentence number one. Sentence number two.\nSentence number 333. Sentence number 444."
+		paragraphs := SplitPageToParagraphs(pageID, content)
+
+		chunks := ChunkParagraphs(paragraphs, ChunkingPolicy{MaxTokens: 12, MinTokens: 0, OverlapTokens: 6})
+		require.Equal(t, 4, len(chunks))
+		require.True(t, chunks[0].IsHeader)
+
+		require.Equal(t, "Sentence number one. Sentence number two.", chunks[1].Content)
+		require.Equal(t, 2, chunks[1].LineNumber)
+		require.Equal(t, 2, chunks[1].EndLineNumber)
+
+		require.Equal(t, "Sentence number two.\nSentence number 333.", chunks[2].Content)
+		require.Equal(t, 2, chunks[2].LineNumber)
+		require.Equal(t, 3, chunks[2].EndLineNumber)
+
+		require.Equal(t, "Sentence number 333. Sentence number 444.", chunks[3].Content)
+		require.Equal(t, 3, chunks[3].LineNumber)
+		require.Equal(t, 3, chunks[3].EndLineNumber)
+
+		for i, chunk := range chunks {
+			require.Equal(t, i, chunk.ParagraphIndex)
+			require.Equal(t, []string{"Title"}, chunk.Headers)
+		}
+	})
+
+	t.Run("code is split at lines and keeps indentation", func(t *testing.T) {
+		t.Parallel()
+
+		content := "```\nfunc main() {\n    fmt.Println()\n}\n```"
+		paragraphs := SplitPageToParagraphs(pageID, content)
+
+		chunks := ChunkParagraphs(paragraphs, ChunkingPolicy{MaxTokens: 7, MinTokens: 0, OverlapTokens: 0})
+		require.Equal(t, []string{"```\nfunc main() {", "    fmt.Println()\n}\n```"}, []string{chunks[0].Content, chunks[1].Content})
+		require.Equal(t, 0, chunks[0].LineNumber)
+		require.Equal(t, 1, chunks[0].EndLineNumber)
+		require.Equal(t, 2, chunks[1].LineNumber)
+		require.Equal(t, 4, chunks[1].EndLineNumber)
+		require.Equal(t, internals.Code, chunks[1].BlockType)
+	})
+
+	t.Run("sentence without boundaries is cut at spaces", func(t *testing.T) {
+		t.Parallel()
+
+		content := strings.Repeat("word ", 10)
+		paragraphs := SplitPageToParagraphs(pageID, content)
+
+		chunks := ChunkParagraphs(paragraphs, ChunkingPolicy{MaxTokens: 3, MinTokens: 0, OverlapTokens: 0})
+		require.Equal(t, 5, len(chunks))
+		for _, chunk := range chunks {
+			require.Equal(t, "word word", chunk.Content)
+		}
+	})
+
+	t.Run("tiny paragraphs of the same section are merged", func(t *testing.T) {
+		t.Parallel()
+
+		content := `# A
+
+Short.
+
+- item
+
+## B
+
+Another short.
+
+A paragraph which is long enough to stay alone.`
+		paragraphs := SplitPageToParagraphs(pageID, content)
+
+		chunks := ChunkParagraphs(paragraphs, ChunkingPolicy{MaxTokens: 100, MinTokens: 5, OverlapTokens: 0})
+		require.Equal(t, 4, len(chunks))
+
+		require.Equal(t, "Short.\n\n- item", chunks[1].Content)
+		require.Equal(t, internals.Text, chunks[1].BlockType)
+		require.Equal(t, 2, chunks[1].LineNumber)
+		require.Equal(t, 4, chunks[1].EndLineNumber)
+
+		// Paragraph under another header is not merged into previous section
+		require.True(t, chunks[2].IsHeader)
+		require.Equal(t, "Another short.\n\nA paragraph which is long enough to stay alone.", chunks[3].Content)
+		require.Equal(t, 10, chunks[3].EndLineNumber)
+	})
+}
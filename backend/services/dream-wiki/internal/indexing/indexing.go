@@ -1,9 +1,9 @@
 package indexing
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
 	"regexp"
-	"slices"
 	"strings"
 	"unicode"
 
@@ -12,97 +12,46 @@ import (
 	"golang.org/x/text/unicode/norm"
 )
 
-var (
-	headerRegex = regexp.MustCompile(`^(#{1,})\s+(.+)$`)
-)
-
+// SplitPageToParagraphs turns every top-level markdown block of the page into a
+// paragraph, so that code blocks, tables and lists are never cut. Headers of a
+// paragraph are the enclosing sections from the outermost one.
 func SplitPageToParagraphs(pageID api.PageID, page string) []internals.ParagraphWithEmbedding {
-	if page == "" {
-		return []internals.ParagraphWithEmbedding{}
-	}
-
-	paragraphTexts := strings.Split(page, "\n\n")
-	var paragraphs []internals.ParagraphWithEmbedding
-
-	var headers []string
-	paragraphIndex := 0
-
-	currentPos := 0
-
-	for i, paragraphText := range paragraphTexts {
-		paragraphStartLine := strings.Count(page[:currentPos], "\n")
-
-		lines := strings.Split(paragraphText, "\n")
-		var headerLines []int
-		var nonHeaderLines []string
+	paragraphs := make([]internals.ParagraphWithEmbedding, 0)
 
-		for lineIndex, line := range lines {
-			trimmedLine := strings.TrimSpace(line)
-			if matches := headerRegex.FindStringSubmatch(trimmedLine); len(matches) > 1 {
-				headerLines = append(headerLines, lineIndex)
-			}
+	headers := make([]sectionHeader, 0)
+	for _, block := range parseMarkdownBlocks(page) {
+		if block.skip {
+			continue
 		}
 
-		for _, lineIndex := range headerLines {
-			line := lines[lineIndex]
-			trimmedLine := strings.TrimSpace(line)
-			if matches := headerRegex.FindStringSubmatch(trimmedLine); len(matches) > 1 {
-				headerText := strings.TrimSpace(matches[2])
-				headers = append(headers, headerText)
-
-				lineNumber := paragraphStartLine + lineIndex
-
-				var anchorSlug *string
-				slug := headerToSlug(headerText)
-				anchorSlug = &slug
-
-				paragraphs = append(paragraphs, internals.ParagraphWithEmbedding{
-					PageId:         pageID,
-					LineNumber:     lineNumber,
-					Content:        trimmedLine,
-					AnchorSlug:     anchorSlug,
-					Headers:        append([]string(nil), headers...),
-					ParagraphIndex: paragraphIndex,
-					IsHeader:       true,
-				})
-				paragraphIndex++
+		if block.blockType == internals.Heading {
+			for len(headers) > 0 && headers[len(headers)-1].level >= block.headingLevel {
+				headers = headers[:len(headers)-1]
 			}
+			headers = append(headers, sectionHeader{level: block.headingLevel, text: block.headingText, anchorSlug: block.headingAnchor})
 		}
 
-		for lineIndex, line := range lines {
-			isHeader := slices.Contains(headerLines, lineIndex)
-			if !isHeader {
-				nonHeaderLines = append(nonHeaderLines, line)
-			}
+		var anchorSlug *string
+		if len(headers) > 0 {
+			slug := headers[len(headers)-1].anchorSlug
+			anchorSlug = &slug
 		}
-
-		if len(nonHeaderLines) > 0 {
-			content := strings.TrimSpace(strings.Join(nonHeaderLines, "\n"))
-			if content != "" {
-				var anchorSlug *string
-				if len(headers) > 0 {
-					lastHeader := headers[len(headers)-1]
-					slug := headerToSlug(lastHeader)
-					anchorSlug = &slug
-				}
-
-				paragraphs = append(paragraphs, internals.ParagraphWithEmbedding{
-					PageId:         pageID,
-					LineNumber:     paragraphStartLine,
-					Content:        content,
-					AnchorSlug:     anchorSlug,
-					Headers:        append([]string(nil), headers...),
-					ParagraphIndex: paragraphIndex,
-					IsHeader:       false,
-				})
-				paragraphIndex++
-			}
+		headerTexts := make([]string, 0, len(headers))
+		for _, header := range headers {
+			headerTexts = append(headerTexts, header.text)
 		}
 
-		currentPos += len(paragraphText)
-		if i < len(paragraphTexts)-1 {
-			currentPos += 2
-		}
+		paragraphs = append(paragraphs, internals.ParagraphWithEmbedding{
+			PageId:         pageID,
+			LineNumber:     block.startLine,
+			EndLineNumber:  block.endLine - 1,
+			Content:        block.content,
+			AnchorSlug:     anchorSlug,
+			Headers:        headerTexts,
+			ParagraphIndex: len(paragraphs),
+			IsHeader:       block.blockType == internals.Heading,
+			BlockType:      block.blockType,
+		})
 	}
 
 	return paragraphs
@@ -180,6 +129,12 @@ func headerToSlug(title string) string {
 	dash := regexp.MustCompile(`-+`)
 	title = dash.ReplaceAllString(title, "-")
 
-	fmt.Println(title)
 	return title
 }
+
+// ContentHash identifies paragraph content, so that embedding and terms of an
+// unchanged paragraph can be reused on reindexation.
+func ContentHash(content string) string {
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:])
+}
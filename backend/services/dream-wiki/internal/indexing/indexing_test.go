@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
 
 func TestSplitPageToParagraphs(t *testing.T) {
@@ -49,13 +50,11 @@ This is the conclusion paragraph. It should be contentful as well.`
 
 		// Check anchor slug
 		require.NotNil(t, paragraphs[0].AnchorSlug)
-		require.Equal(t, "#introduction", *paragraphs[0].AnchorSlug)
+		require.Equal(t, "introduction", *paragraphs[0].AnchorSlug)
 
-		// Check that later paragraphs have accumulated headers
-		require.Equal(t, 3, len(paragraphs[7].Headers))
-		require.Equal(t, "Introduction", paragraphs[7].Headers[0])
-		require.Equal(t, "Main Content", paragraphs[7].Headers[1])
-		require.Equal(t, "Conclusion", paragraphs[7].Headers[2])
+		// Header of the same level closes the previous section
+		require.Equal(t, []string{"Conclusion"}, paragraphs[7].Headers)
+		require.Equal(t, "conclusion", *paragraphs[7].AnchorSlug)
 	})
 
 	t.Run("list items in paragraph", func(t *testing.T) {
@@ -71,30 +70,30 @@ This paragraph should be contentful.`
 
 		paragraphs := SplitPageToParagraphs(pageID, content)
 
-		// Should have 3 paragraphs (no longer filtering empty paragraphs)
-		require.Equal(t, 3, len(paragraphs))
+		// List interrupts the paragraph, so it is a separate block
+		require.Equal(t, 4, len(paragraphs))
 
-		// Check first paragraph (the list)
 		require.Equal(t, 0, paragraphs[0].LineNumber)
 		require.Equal(t, "# Flowers", paragraphs[0].Content)
 		require.True(t, paragraphs[0].IsHeader)
 
-		// Check second paragraph
-		require.Equal(t, 6, paragraphs[2].LineNumber)
-		require.Equal(t, "This paragraph should be contentful.", paragraphs[2].Content)
-		require.False(t, paragraphs[2].IsHeader)
+		require.Equal(t, 2, paragraphs[1].LineNumber)
+		require.Equal(t, "Ромашки:", paragraphs[1].Content)
+		require.Equal(t, internals.Text, paragraphs[1].BlockType)
 
-		// Check headers for both paragraphs
-		require.Equal(t, 1, len(paragraphs[0].Headers))
-		require.Equal(t, "Flowers", paragraphs[0].Headers[0])
-		require.Equal(t, 1, len(paragraphs[1].Headers))
-		require.Equal(t, "Flowers", paragraphs[1].Headers[0])
-
-		// Check anchor slugs
-		require.NotNil(t, paragraphs[1].AnchorSlug)
-		require.Equal(t, "#flowers", *paragraphs[1].AnchorSlug)
-		require.NotNil(t, paragraphs[2].AnchorSlug)
-		require.Equal(t, "#flowers", *paragraphs[2].AnchorSlug)
+		require.Equal(t, 3, paragraphs[2].LineNumber)
+		require.Equal(t, "* Белые\n* Черные", paragraphs[2].Content)
+		require.Equal(t, internals.List, paragraphs[2].BlockType)
+
+		require.Equal(t, 6, paragraphs[3].LineNumber)
+		require.Equal(t, "This paragraph should be contentful.", paragraphs[3].Content)
+		require.False(t, paragraphs[3].IsHeader)
+
+		for _, paragraph := range paragraphs {
+			require.Equal(t, []string{"Flowers"}, paragraph.Headers)
+			require.NotNil(t, paragraph.AnchorSlug)
+			require.Equal(t, "flowers", *paragraph.AnchorSlug)
+		}
 	})
 
 	t.Run("non contentful paragraphs", func(t *testing.T) {
@@ -154,10 +153,6 @@ Also too few.`
 
 	})
 
-	t.Run("special characters in headers", func(t *testing.T) {
-		t.Parallel()
-	})
-
 	t.Run("special characters in headers", func(t *testing.T) {
 		t.Parallel()
 
@@ -172,7 +167,7 @@ This is a contentful paragraph.`
 
 		// Check anchor slug generation
 		require.NotNil(t, paragraphs[0].AnchorSlug)
-		require.Equal(t, "#header-with-characters-symbols", *paragraphs[0].AnchorSlug)
+		require.Equal(t, "header-with-specialnye-characters-i-symbols", *paragraphs[0].AnchorSlug)
 	})
 	t.Run("headers with multiple levels", func(t *testing.T) {
 		content := `# Main Header
@@ -214,6 +209,7 @@ Back to main content.`
 		require.Equal(t, "Main Header", paragraphs[4].Headers[0])
 		require.Equal(t, "Sub Header", paragraphs[4].Headers[1])
 		require.Equal(t, "Deep Header", paragraphs[4].Headers[2])
+		require.Equal(t, []string{"Main Header", "Sub Header", "Deep Header"}, paragraphs[6].Headers)
 	})
 
 	t.Run("headers without content paragraphs", func(t *testing.T) {
@@ -263,10 +259,16 @@ Final content.`
 		require.True(t, paragraphs[2].IsHeader)
 		require.Equal(t, 6, paragraphs[3].LineNumber) // - Item 1
 		require.False(t, paragraphs[3].IsHeader)
+		require.Equal(t, internals.List, paragraphs[3].BlockType)
+		require.Equal(t, "- Item 1\n- Item 2", paragraphs[3].Content)
 		require.Equal(t, 9, paragraphs[4].LineNumber) // ## Installation
 		require.True(t, paragraphs[4].IsHeader)
-		require.Equal(t, 11, paragraphs[5].LineNumber) // Final content.
+		require.Equal(t, 11, paragraphs[5].LineNumber) // To install, run:
 		require.False(t, paragraphs[5].IsHeader)
+		require.Equal(t, 13, paragraphs[6].LineNumber) // code example
+		require.Equal(t, internals.Code, paragraphs[6].BlockType)
+		require.Equal(t, "    code example", paragraphs[6].Content)
+		require.Equal(t, 15, paragraphs[7].LineNumber) // Final content.
 	})
 
 	t.Run("header with special symbols", func(t *testing.T) {
@@ -282,11 +284,136 @@ Content here.`
 		// Check that the content paragraph has IsHeader = false
 		require.False(t, paragraphs[1].IsHeader)
 
-		require.Equal(t, "#header-with-symbols-and-punctuation", *paragraphs[0].AnchorSlug)
+		require.Equal(t, "header-with-symbols-and-punctuation", *paragraphs[0].AnchorSlug)
+	})
+
+	t.Run("fenced code block is kept whole", func(t *testing.T) {
+		t.Parallel()
+
+		content := "# Setup\n\nRun:\n```sh\n# not a header\n\nmake build\n```\n\nDone."
+
+		paragraphs := SplitPageToParagraphs(pageID, content)
+		require.Equal(t, 4, len(paragraphs))
+
+		code := paragraphs[2]
+		require.Equal(t, internals.Code, code.BlockType)
+		require.False(t, code.IsHeader)
+		require.Equal(t, 3, code.LineNumber)
+		require.Equal(t, "```sh\n# not a header\n\nmake build\n```", code.Content)
+		require.Equal(t, []string{"Setup"}, code.Headers)
+
+		require.Equal(t, 9, paragraphs[3].LineNumber)
+		require.Equal(t, []string{"Setup"}, paragraphs[3].Headers)
+	})
+
+	t.Run("table and quote", func(t *testing.T) {
+		t.Parallel()
+
+		content := `| Тариф | Цена |
+|-------|------|
+| Базовый | 100 |
+
+| Премиум | 200 |
+
+> Цены указаны
+> без НДС`
+
+		paragraphs := SplitPageToParagraphs(pageID, content)
+		require.Equal(t, 3, len(paragraphs))
+
+		require.Equal(t, internals.Table, paragraphs[0].BlockType)
+		require.Equal(t, "| Тариф | Цена |\n|-------|------|\n| Базовый | 100 |", paragraphs[0].Content)
+		require.Nil(t, paragraphs[0].AnchorSlug)
+
+		// Without delimiter row it is not a table
+		require.Equal(t, internals.Text, paragraphs[1].BlockType)
+		require.Equal(t, 4, paragraphs[1].LineNumber)
+
+		require.Equal(t, internals.Quote, paragraphs[2].BlockType)
+		require.Equal(t, 6, paragraphs[2].LineNumber)
+	})
+
+	t.Run("loose list with nested items is one block", func(t *testing.T) {
+		t.Parallel()
+
+		content := `1. First
+
+   Details of first.
+   - nested
+2. Second
+
+After list.`
+
+		paragraphs := SplitPageToParagraphs(pageID, content)
+		require.Equal(t, 2, len(paragraphs))
+		require.Equal(t, internals.List, paragraphs[0].BlockType)
+		require.Equal(t, 0, paragraphs[0].LineNumber)
+		require.Equal(t, 6, paragraphs[1].LineNumber)
+	})
+
+	t.Run("nested fenced code blocks end with their fences", func(t *testing.T) {
+		t.Parallel()
+
+		paragraphs := SplitPageToParagraphs(pageID, "- item\n  ```\n  code\n  ```\n\nNext para")
+		require.Equal(t, 2, len(paragraphs))
+		require.Equal(t, internals.List, paragraphs[0].BlockType)
+		require.Equal(t, "- item\n  ```\n  code\n  ```", paragraphs[0].Content)
+		require.Equal(t, 3, paragraphs[0].EndLineNumber)
+		require.Equal(t, "Next para", paragraphs[1].Content)
+		require.Equal(t, 5, paragraphs[1].LineNumber)
+
+		paragraphs = SplitPageToParagraphs(pageID, "> Quote\n> ```sh\n> ```\n\n- ```\n  ```\n- last\n\nEnd")
+		require.Equal(t, 3, len(paragraphs))
+		require.Equal(t, internals.Quote, paragraphs[0].BlockType)
+		require.Equal(t, "> Quote\n> ```sh\n> ```", paragraphs[0].Content)
+		require.Equal(t, internals.List, paragraphs[1].BlockType)
+		require.Equal(t, "- ```\n  ```\n- last", paragraphs[1].Content)
+		require.Equal(t, "End", paragraphs[2].Content)
+		require.Equal(t, 8, paragraphs[2].LineNumber)
+	})
+
+	t.Run("yfm anchors, setext headers and directives", func(t *testing.T) {
+		t.Parallel()
+
+		content := `Prices
+======
+
+## Delivery {#delivery-cost}
+
+{% note info %}
+
+Free delivery.
+
+{% endnote %}
+
+---
+
+Footer.`
+
+		paragraphs := SplitPageToParagraphs(pageID, content)
+		require.Equal(t, 4, len(paragraphs))
+
+		require.True(t, paragraphs[0].IsHeader)
+		require.Equal(t, "Prices\n======", paragraphs[0].Content)
+		require.Equal(t, "prices", *paragraphs[0].AnchorSlug)
+
+		require.Equal(t, []string{"Prices", "Delivery"}, paragraphs[1].Headers)
+		require.Equal(t, "delivery-cost", *paragraphs[1].AnchorSlug)
+
+		require.Equal(t, "Free delivery.", paragraphs[2].Content)
+		require.Equal(t, 7, paragraphs[2].LineNumber)
+		require.Equal(t, "delivery-cost", *paragraphs[2].AnchorSlug)
+
+		require.Equal(t, "Footer.", paragraphs[3].Content)
+		require.Equal(t, 13, paragraphs[3].LineNumber)
+
+		for i, paragraph := range paragraphs {
+			require.Equal(t, i, paragraph.ParagraphIndex)
+		}
 	})
 }
 
-func TestGenerateAnchorSlug(t *testing.T) {
+func TestHeaderToSlug(t *testing.T) {
 	t.Parallel()
 
 	tests := []struct {
@@ -297,27 +424,27 @@ func TestGenerateAnchorSlug(t *testing.T) {
 		{
 			name:       "simple header",
 			headerText: "Introduction",
-			expected:   "#introduction",
+			expected:   "introduction",
 		},
 		{
 			name:       "header with spaces",
 			headerText: "Getting Started",
-			expected:   "#getting-started",
+			expected:   "getting-started",
 		},
 		{
 			name:       "header with special characters",
 			headerText: "FAQ & Help",
-			expected:   "#faq-help",
+			expected:   "faq-help",
 		},
 		{
 			name:       "header with numbers",
 			headerText: "Chapter 1: Basics",
-			expected:   "#chapter-1-basics",
+			expected:   "chapter-1-basics",
 		},
 		{
 			name:       "header with russian text",
 			headerText: "Приветствие и консультация",
-			expected:   "#",
+			expected:   "privetstvie-i-konsultaciya",
 		},
 	}
 
@@ -325,8 +452,38 @@ func TestGenerateAnchorSlug(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			result := generateAnchorSlug(tt.headerText)
+			result := headerToSlug(tt.headerText)
 			require.Equal(t, tt.expected, result)
 		})
 	}
 }
+
+func TestSplitPageToBlocks(t *testing.T) {
+	t.Parallel()
+
+	content := "# Title\nText\n\n```\na\n\nb\n```\n\n---\n\nEnd"
+
+	blocks := SplitPageToBlocks(content, ChunkingPolicy{MaxTokens: 256})
+	require.Equal(t, []ParagraphBlock{
+		{StartLine: 0, EndLine: 1, ParagraphIndexes: []int{0}},
+		{StartLine: 1, EndLine: 2, ParagraphIndexes: []int{1}},
+		{StartLine: 3, EndLine: 8, ParagraphIndexes: []int{2}},
+		{StartLine: 9, EndLine: 10, ParagraphIndexes: []int{}},
+		{StartLine: 11, EndLine: 12, ParagraphIndexes: []int{3}},
+	}, blocks)
+
+	paragraphs := SplitPageToParagraphs(api.PageID{}, content)
+	require.Equal(t, 4, len(paragraphs))
+	require.Equal(t, 11, paragraphs[3].LineNumber)
+
+	// Indexes are those of chunks: the long paragraph is split in two, and the
+	// second piece is merged with the tiny paragraph after it.
+	content = "# T\n\nLong sentence one. Long sentence two.\n\nTiny\n\nTiny2"
+	blocks = SplitPageToBlocks(content, ChunkingPolicy{MaxTokens: 6, MinTokens: 2})
+	require.Equal(t, []ParagraphBlock{
+		{StartLine: 0, EndLine: 1, ParagraphIndexes: []int{0}},
+		{StartLine: 2, EndLine: 3, ParagraphIndexes: []int{1, 2}},
+		{StartLine: 4, EndLine: 5, ParagraphIndexes: []int{2}},
+		{StartLine: 6, EndLine: 7, ParagraphIndexes: []int{3}},
+	}, blocks)
+}
@@ -0,0 +1,224 @@
+package indexing
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var (
+	markdownParser = goldmark.New(goldmark.WithExtensions(extension.Table)).Parser()
+
+	// Closing fence may be nested in lists and quotes.
+	closingFenceRegex     = regexp.MustCompile("^[ \t>]*(`{3,}|~{3,})\\s*$")
+	setextUnderlineRegex  = regexp.MustCompile(`^ {0,3}(=+|-+)\s*$`)
+	atxHeadingRegex       = regexp.MustCompile(`^ {0,3}#`)
+	yfmHeadingAnchorRegex = regexp.MustCompile(`\s*\{#([^}\s]+)\}\s*$`)
+	// YFM directives like {% note info %} or {% endcut %} are markup only.
+	yfmDirectiveRegex = regexp.MustCompile(`^\s*\{%.*%\}\s*$`)
+)
+
+type (
+	// markdownBlock is a top-level block of a page, lines [startLine, endLine).
+	markdownBlock struct {
+		startLine int
+		endLine   int
+		blockType internals.ParagraphBlockType
+		content   string
+		// skip is set for blocks without text, e.g. thematic breaks.
+		skip bool
+
+		headingLevel  int
+		headingText   string
+		headingAnchor string
+	}
+
+	sectionHeader struct {
+		level      int
+		text       string
+		anchorSlug string
+	}
+
+	pageLines struct {
+		source     []byte
+		lines      []string
+		lineStarts []int
+	}
+)
+
+func newPageLines(page string) *pageLines {
+	lines := strings.Split(page, "\n")
+	lineStarts := make([]int, 0, len(lines))
+	offset := 0
+	for _, line := range lines {
+		lineStarts = append(lineStarts, offset)
+		offset += len(line) + 1
+	}
+	return &pageLines{source: []byte(page), lines: lines, lineStarts: lineStarts}
+}
+
+func (p *pageLines) lineOf(offset int) int {
+	return sort.Search(len(p.lineStarts), func(i int) bool { return p.lineStarts[i] > offset }) - 1
+}
+
+func (p *pageLines) isBlank(line int) bool {
+	return strings.TrimSpace(p.lines[line]) == ""
+}
+
+// lastSegmentLine returns the last line which node or its descendants occupy,
+// node starting at startLine. Parser does not record fences, so closing fences
+// of code blocks are looked up after their content, also inside lists and
+// quotes. Setext underlines are accounted by callers.
+func (p *pageLines) lastSegmentLine(node ast.Node, startLine int) int {
+	lastLine := startLine - 1
+	accountSegment := func(segment text.Segment) {
+		if segment.Stop > segment.Start {
+			lastLine = max(lastLine, p.lineOf(segment.Stop-1))
+		} else {
+			lastLine = max(lastLine, p.lineOf(segment.Start))
+		}
+	}
+
+	_ = ast.Walk(node, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || child.Type() != ast.TypeBlock {
+			return ast.WalkContinue, nil
+		}
+		if codeBlock, ok := child.(*ast.FencedCodeBlock); ok {
+			lastLine = p.closingFenceLine(codeBlock, lastLine)
+			return ast.WalkSkipChildren, nil
+		}
+		lines := child.Lines()
+		for i := range lines.Len() {
+			accountSegment(lines.At(i))
+		}
+		if htmlBlock, ok := child.(*ast.HTMLBlock); ok && htmlBlock.HasClosure() {
+			accountSegment(htmlBlock.ClosureLine)
+		}
+		return ast.WalkContinue, nil
+	})
+	return lastLine
+}
+
+// closingFenceLine returns the last line of fenced code block which follows
+// lastLine. The opening fence is the line before content, the line of info
+// string, or else the first non-blank line after lastLine.
+func (p *pageLines) closingFenceLine(codeBlock *ast.FencedCodeBlock, lastLine int) int {
+	var fenceLine int
+	switch {
+	case codeBlock.Lines().Len() > 0:
+		lines := codeBlock.Lines()
+		fenceLine = p.lineOf(lines.At(lines.Len()-1).Stop-1) + 1
+	case codeBlock.Info != nil:
+		fenceLine = p.lineOf(codeBlock.Info.Segment.Start) + 1
+	default:
+		fenceLine = lastLine + 1
+		for fenceLine < len(p.lines)-1 && p.isBlank(fenceLine) {
+			fenceLine++
+		}
+		fenceLine++
+	}
+
+	// Unclosed block ends at its last line.
+	if fenceLine >= len(p.lines) || !closingFenceRegex.MatchString(p.lines[fenceLine]) {
+		fenceLine--
+	}
+	return max(lastLine, min(fenceLine, len(p.lines)-1))
+}
+func (p *pageLines) segmentsText(node ast.Node) string {
+	parts := make([]string, 0, node.Lines().Len())
+	for i := range node.Lines().Len() {
+		segment := node.Lines().At(i)
+		parts = append(parts, strings.TrimSpace(string(segment.Value(p.source))))
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseMarkdownBlocks walks top-level nodes of markdown AST. Parser keeps only
+// text positions, so a block is assumed to start at the first non-blank line
+// after the previous block and to end at its last text line, extended by a
+// closing fence or setext underline. Link reference definitions, which parser
+// drops, become part of the next block.
+func parseMarkdownBlocks(page string) []markdownBlock {
+	blocks := make([]markdownBlock, 0)
+	if strings.TrimSpace(page) == "" {
+		return blocks
+	}
+
+	p := newPageLines(page)
+	document := markdownParser.Parse(text.NewReader(p.source))
+
+	nextLine := 0
+	for node := document.FirstChild(); node != nil; node = node.NextSibling() {
+		startLine := nextLine
+		for startLine < len(p.lines)-1 && p.isBlank(startLine) {
+			startLine++
+		}
+		lastLine := max(startLine, p.lastSegmentLine(node, startLine))
+
+		block := markdownBlock{startLine: startLine}
+		switch node := node.(type) {
+		case *ast.Heading:
+			isSetext := !atxHeadingRegex.MatchString(p.lines[startLine])
+			if isSetext && lastLine+1 < len(p.lines) && setextUnderlineRegex.MatchString(p.lines[lastLine+1]) {
+				lastLine++
+			}
+			block.blockType = internals.Heading
+			block.headingLevel = node.Level
+			block.headingText = p.segmentsText(node)
+			if matches := yfmHeadingAnchorRegex.FindStringSubmatch(block.headingText); matches != nil {
+				block.headingText = strings.TrimSpace(strings.TrimSuffix(block.headingText, matches[0]))
+				block.headingAnchor = matches[1]
+			} else {
+				block.headingAnchor = headerToSlug(block.headingText)
+			}
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			block.blockType = internals.Code
+		case *extast.Table:
+			block.blockType = internals.Table
+		case *ast.List:
+			block.blockType = internals.List
+		case *ast.Blockquote:
+			block.blockType = internals.Quote
+		case *ast.HTMLBlock:
+			block.blockType = internals.Html
+		case *ast.Paragraph:
+			block.blockType = internals.Text
+		default:
+			block.blockType = internals.Text
+			block.skip = true
+		}
+
+		block.endLine = lastLine + 1
+		nextLine = block.endLine
+
+		blockLines := p.lines[block.startLine:block.endLine]
+		if block.blockType == internals.Code {
+			block.content = strings.TrimRight(strings.Join(blockLines, "\n"), " \t\n")
+		} else {
+			block.content = strings.TrimSpace(strings.Join(blockLines, "\n"))
+		}
+		if block.blockType == internals.Text && isYFMDirectivesOnly(blockLines) {
+			block.skip = true
+		}
+
+		blocks = append(blocks, block)
+	}
+
+	return blocks
+}
+
+func isYFMDirectivesOnly(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" && !yfmDirectiveRegex.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
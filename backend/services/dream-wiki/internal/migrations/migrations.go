@@ -0,0 +1,146 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+)
+
+// Migration files are named NNNN_description.yql and applied in order of
+// their numbers. Applied migration must never be edited, schema changes go
+// into a new file. DDL is not transactional in YDB, so statements should
+// be written to be safe for rerun after partial failure where possible
+// (IF NOT EXISTS and so on). YDB does not mix scheme and data statements in
+// one query, so data backfill goes into a file of its own.
+//
+//go:embed yql/*.yql
+var migrationFiles embed.FS
+
+var migrationFileNameRegexp = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.yql$`)
+
+type (
+	Migration struct {
+		Version     int64
+		Description string
+		YQL         string
+	}
+
+	// Store keeps schema and applied versions.
+	Store interface {
+		// AppliedVersions returns versions recorded in SchemaVersion table.
+		// Table absence means that nothing is applied.
+		AppliedVersions(ctx context.Context) ([]int64, error)
+		// Apply executes migration and records its version.
+		Apply(ctx context.Context, migration Migration) error
+		// Record records migration version without executing it.
+		Record(ctx context.Context, migration Migration) error
+	}
+)
+
+// LoadMigrations returns embedded migrations ordered by version.
+func LoadMigrations() ([]Migration, error) {
+	return loadMigrations(migrationFiles, "yql")
+}
+
+func loadMigrations(files fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		match := migrationFileNameRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q, expected NNNN_description.yql", entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad version of migration %q: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migrations = append(migrations, Migration{
+			Version:     version,
+			Description: match[2],
+			YQL:         string(content),
+		})
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return int(a.Version - b.Version)
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Pending returns migrations which are not applied yet.
+func Pending(ctx context.Context, store Store, migrations []Migration) ([]Migration, error) {
+	applied, err := store.AppliedVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied schema versions: %w", err)
+	}
+
+	pending := make([]Migration, 0)
+	for _, migration := range migrations {
+		if !slices.Contains(applied, migration.Version) {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies pending migrations in order and returns them. Applying
+// stops at the first failure, so later migrations never run on top of a
+// failed one.
+func Migrate(ctx context.Context, store Store, migrations []Migration) ([]Migration, error) {
+	pending, err := Pending(ctx, store, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range pending {
+		err = store.Apply(ctx, migration)
+		if err != nil {
+			return pending[:i], fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Description, err)
+		}
+	}
+	return pending, nil
+}
+
+// Baseline records migrations up to version as applied without executing
+// them. It is used once for databases created before migrations were
+// introduced, whose schema already matches those migrations.
+func Baseline(ctx context.Context, store Store, migrations []Migration, version int64) ([]Migration, error) {
+	pending, err := Pending(ctx, store, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]Migration, 0)
+	for _, migration := range pending {
+		if migration.Version > version {
+			break
+		}
+		err = store.Record(ctx, migration)
+		if err != nil {
+			return recorded, fmt.Errorf("failed to record migration %04d_%s: %w", migration.Version, migration.Description, err)
+		}
+		recorded = append(recorded, migration)
+	}
+	return recorded, nil
+}
@@ -0,0 +1,128 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps applied versions in memory and fails on migration with
+// failingVersion.
+type fakeStore struct {
+	applied        []int64
+	executed       []int64
+	failingVersion int64
+}
+
+func (s *fakeStore) AppliedVersions(context.Context) ([]int64, error) {
+	return s.applied, nil
+}
+
+func (s *fakeStore) Apply(ctx context.Context, migration Migration) error {
+	if migration.Version == s.failingVersion {
+		return errors.New("syntax error")
+	}
+	s.executed = append(s.executed, migration.Version)
+	return s.Record(ctx, migration)
+}
+
+func (s *fakeStore) Record(_ context.Context, migration Migration) error {
+	s.applied = append(s.applied, migration.Version)
+	return nil
+}
+
+func versions(migrations []Migration) []int64 {
+	result := make([]int64, 0, len(migrations))
+	for _, migration := range migrations {
+		result = append(result, migration.Version)
+	}
+	return result
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"yql/0010_add_index.yql": {Data: []byte("ALTER TABLE Page ...;")},
+		"yql/0002_add_table.yql": {Data: []byte("CREATE TABLE B ...;")},
+		"yql/0001_initial.yql":   {Data: []byte("CREATE TABLE A ...;")},
+	}
+	migrations, err := loadMigrations(files, "yql")
+	require.NoError(t, err)
+	require.Equal(t, []Migration{
+		{Version: 1, Description: "initial", YQL: "CREATE TABLE A ...;"},
+		{Version: 2, Description: "add_table", YQL: "CREATE TABLE B ...;"},
+		{Version: 10, Description: "add_index", YQL: "ALTER TABLE Page ...;"},
+	}, migrations)
+}
+
+func TestLoadMigrationsRejectsBadFiles(t *testing.T) {
+	t.Parallel()
+
+	_, err := loadMigrations(fstest.MapFS{"yql/initial.yql": {}}, "yql")
+	require.Error(t, err)
+
+	_, err = loadMigrations(fstest.MapFS{"yql/0001_a.yql": {}, "yql/01_b.yql": {}}, "yql")
+	require.ErrorContains(t, err, "duplicate migration version 1")
+}
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := LoadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	require.Equal(t, int64(1), migrations[0].Version)
+}
+
+func TestMigrateAppliesOnlyPending(t *testing.T) {
+	t.Parallel()
+
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	store := &fakeStore{applied: []int64{1}}
+
+	applied, err := Migrate(context.Background(), store, migrations)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 3}, versions(applied))
+
+	// Second run does nothing.
+	applied, err = Migrate(context.Background(), store, migrations)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+	require.Equal(t, []int64{2, 3}, store.executed)
+}
+
+func TestMigrateStopsAtFailure(t *testing.T) {
+	t.Parallel()
+
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	store := &fakeStore{failingVersion: 2}
+
+	applied, err := Migrate(context.Background(), store, migrations)
+	require.ErrorContains(t, err, "syntax error")
+	require.Equal(t, []int64{1}, versions(applied))
+	require.Equal(t, []int64{1}, store.applied)
+
+	pending, err := Pending(context.Background(), store, migrations)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 3}, versions(pending))
+}
+
+func TestBaselineRecordsWithoutExecuting(t *testing.T) {
+	t.Parallel()
+
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+	store := &fakeStore{}
+
+	recorded, err := Baseline(context.Background(), store, migrations, 2)
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, versions(recorded))
+	require.Empty(t, store.executed)
+
+	applied, err := Migrate(context.Background(), store, migrations)
+	require.NoError(t, err)
+	require.Equal(t, []int64{3}, versions(applied))
+}
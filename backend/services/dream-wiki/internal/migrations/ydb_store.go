@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/ydb-platform/ydb-go-sdk/v3"
+	"github.com/ydb-platform/ydb-go-sdk/v3/query"
+	"github.com/ydb-platform/ydb-go-sdk/v3/sugar"
+)
+
+const schemaVersionTableName = "SchemaVersion"
+
+type ydbStore struct {
+	driver *ydb.Driver
+}
+
+var _ Store = (*ydbStore)(nil)
+
+func NewYDBStore(driver *ydb.Driver) Store {
+	return &ydbStore{driver: driver}
+}
+
+func (s *ydbStore) AppliedVersions(ctx context.Context) ([]int64, error) {
+	exists, err := sugar.IsTableExists(ctx, s.driver.Scheme(), path.Join(s.driver.Name(), schemaVersionTableName))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return []int64{}, nil
+	}
+
+	resultSet, err := s.driver.Query().QueryResultSet(ctx, `SELECT version FROM SchemaVersion ORDER BY version;`)
+	if err != nil {
+		return nil, err
+	}
+	defer resultSet.Close(ctx)
+
+	versions := make([]int64, 0)
+	for row, err := range resultSet.Rows(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		var version int64
+		err = row.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+func (s *ydbStore) Apply(ctx context.Context, migration Migration) error {
+	// Schema statements can not be executed in transaction.
+	err := s.driver.Query().Exec(ctx, migration.YQL, query.WithTxControl(query.NoTx()))
+	if err != nil {
+		return err
+	}
+
+	return s.Record(ctx, migration)
+}
+
+func (s *ydbStore) Record(ctx context.Context, migration Migration) error {
+	err := s.driver.Query().Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS SchemaVersion (
+			version     Int64     NOT NULL,
+			description Text      NOT NULL,
+			applied_at  Timestamp NOT NULL,
+			PRIMARY KEY (version)
+		);`, query.WithTxControl(query.NoTx()))
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", schemaVersionTableName, err)
+	}
+
+	return s.driver.Query().Exec(ctx, `
+		UPSERT INTO SchemaVersion (version, description, applied_at)
+		VALUES ($version, $description, CurrentUtcTimestamp());`,
+		query.WithParameters(ydb.ParamsBuilder().
+			Param("$version").Int64(migration.Version).
+			Param("$description").Text(migration.Description).
+			Build()),
+	)
+}
@@ -0,0 +1,101 @@
+package prompts
+
+import (
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// Names of templates used by github_account_pr task.
+const (
+	GitHubPRProductChanges    = "github_pr_product_changes"
+	GitHubPRSearchQueries     = "github_pr_search_queries"
+	GitHubPRRephraseParagraph = "github_pr_rephrase_paragraph"
+	// LLMOutputRepair is sent after LLM response which does not match the
+	// requested format. Only its user prompt is used: it continues the
+	// conversation of the failed request with model options of that request.
+	LLMOutputRepair = "llm_output_repair"
+)
+
+// builtinTemplates are used until overridden over API. Version of a template
+// must be increased when it is changed, so the new text replaces overrides
+// made for the old one.
+var builtinTemplates = []Template{
+	{
+		Name:        GitHubPRProductChanges,
+		Description: "Находит продуктовые изменения в GitHub PR",
+		Version:     2,
+		Variables:   []string{"pr_description", "pr_patch"},
+		UserPrompt: `Проанализируй этот GitHub PR и определи продуктовые изменения.
+Сфокусируйся на продуктовых изменениях, а не на деталях реализации.
+Обозначь максимум 2 изменения. Если значимых продуктовых изменений
+не было, верни пустой список изменений.
+Не учитывай исправление опечаток или что-то схожее.
+Постарайся описать детали. Например, если изменилась цена или изменилось какое-то бизнес-правило, скажи это прямо.
+Если это важно для бизнеса, назови детали. К примеру, если изменилась цена на какой-то товар, скажи,
+на какой товар и какая цена изменилась
+
+Вот информация:
+
+Описание PR: {{.pr_description}}
+
+PR Patch: {{.pr_patch}}
+
+Ответь только JSON-объектом, без пояснений и разметки:
+{"changes": ["описание изменения"]}`,
+		Model:       internals.Yandexgpt5Lite,
+		Temperature: 0.1,
+		MaxTokens:   2000,
+	},
+	{
+		Name:        GitHubPRSearchQueries,
+		Description: "Предлагает поисковые запросы по базе знаний для продуктовых изменений",
+		Version:     2,
+		Variables:   []string{"product_changes"},
+		UserPrompt: `Я перечислю изменения в продукте. На основе их предложи поисковые запросы.
+У компании есть база знаний, по которой есть семантический поиск. Надо сформировать поисковые запросы, которые могут
+найти такие фрагменты базы знаний, которые теоретически надо изменить при внесении изменений в продукт.
+Сформулируй как можно меньше запросов. Максимум - 3 запроса.
+
+Запросы должны искать ту сущность, которая изменилась. Например, если изменилась цена
+на какой-то товар, надо искать что-то типа "стоимость товара".
+
+Вот продуктовые изменения:
+{{.product_changes}}
+Ответь только JSON-объектом, без пояснений и разметки:
+{"queries": ["поисковый запрос"]}`,
+		Model:       internals.Yandexgpt5Lite,
+		Temperature: 0.3,
+		MaxTokens:   500,
+	},
+	{
+		Name:        GitHubPRRephraseParagraph,
+		Description: "Переписывает параграф базы знаний с учётом изменений GitHub PR",
+		Version:     2,
+		Variables:   []string{"pr_description", "pr_patch", "paragraph"},
+		SystemPrompt: `Измени это с учётом изменений в коде, чтобы текст соответствовал действительности.
+Я тебе напишу какую-то информацию из корпоративной wiki. Верни её отредактированный текст целиком и коротко объясни, что и почему ты изменил.
+Постарайся внести только те изменения, которые действительно произошли.
+Строго нельзя вносить что-то от себя. Если ты не уверен в том, что вносишь, лучше не вноси вообще никаких изменений
+
+Вот информация:
+Описание PR: {{.pr_description}}
+PR Patch: {{.pr_patch}}
+
+Ответь только JSON-объектом, без пояснений и разметки:
+{"paragraph": "отредактированный текст", "rationale": "что изменено и почему"}`,
+		UserPrompt:  `{{.paragraph}}`,
+		Model:       internals.Yandexgpt5Lite,
+		Temperature: 0.1,
+		MaxTokens:   2000,
+	},
+	{
+		Name:        LLMOutputRepair,
+		Description: "Просит исправить ответ языковой модели, который не соответствует запрошенному формату",
+		Version:     1,
+		Variables:   []string{"error"},
+		UserPrompt: `Не удалось разобрать твой ответ: {{.error}}.
+Ответь ещё раз только JSON-объектом в формате из задания, без пояснений и разметки.`,
+		Model:       internals.Yandexgpt5Lite,
+		Temperature: 0.1,
+		MaxTokens:   2000,
+	},
+}
@@ -0,0 +1,189 @@
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+type (
+	// Template is a named LLM prompt with model options. SystemPrompt and
+	// UserPrompt are text/template sources which may refer only to
+	// Variables, e.g. {{.pr_description}}. Empty SystemPrompt is not sent.
+	Template struct {
+		Name        string
+		Description string
+		Version     int
+		// Overridden is false for templates built into the service.
+		Overridden bool
+		// CreatedAt is set for overridden templates only.
+		CreatedAt    *time.Time
+		Variables    []string
+		SystemPrompt string
+		UserPrompt   string
+		Model        internals.LLMModel
+		Temperature  float64
+		MaxTokens    int
+	}
+
+	// OverrideSource is the part of repository.AppRepository which stores
+	// templates overridden over API.
+	OverrideSource interface {
+		// GetLatestPromptTemplateOverride returns models.ErrNoRows if the
+		// template was never overridden.
+		GetLatestPromptTemplateOverride(name string) (*internals.PromptTemplateOverride, error)
+	}
+)
+
+// Builtin returns template built into the service.
+func Builtin(name string) (Template, bool) {
+	for _, builtin := range builtinTemplates {
+		if builtin.Name == name {
+			return builtin.clone(), true
+		}
+	}
+	return Template{}, false
+}
+
+// Builtins returns all templates built into the service ordered by name.
+func Builtins() []Template {
+	templates := make([]Template, 0, len(builtinTemplates))
+	for _, builtin := range builtinTemplates {
+		templates = append(templates, builtin.clone())
+	}
+	slices.SortFunc(templates, func(a, b Template) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return templates
+}
+
+// Active returns the version of the template which is used for new requests:
+// the latest override unless the built-in template has the same or greater
+// version, i.e. it was updated after the override was made.
+func Active(source OverrideSource, name string) (Template, error) {
+	builtin, ok := Builtin(name)
+	if !ok {
+		return Template{}, fmt.Errorf("%w: %s", models.ErrNoPromptTemplate, name)
+	}
+
+	override, err := source.GetLatestPromptTemplateOverride(name)
+	if errors.Is(err, models.ErrNoRows) {
+		return builtin, nil
+	}
+	if err != nil {
+		return Template{}, fmt.Errorf("failed to get prompt template override: %w", err)
+	}
+	if override.Version <= builtin.Version {
+		return builtin, nil
+	}
+	return builtin.WithOverride(*override), nil
+}
+
+// WithOverride returns the template with prompts and model options of the
+// override. Name, description, variables and model are kept.
+func (t Template) WithOverride(override internals.PromptTemplateOverride) Template {
+	overridden := t.clone()
+	overridden.Version = override.Version
+	overridden.Overridden = true
+	createdAt := override.CreatedAt
+	overridden.CreatedAt = &createdAt
+	overridden.SystemPrompt = override.SystemPrompt
+	overridden.UserPrompt = override.UserPrompt
+	overridden.Temperature = override.Temperature
+	overridden.MaxTokens = override.MaxTokens
+	return overridden
+}
+
+// Validate checks that prompts parse, refer only to declared variables and
+// that model options are in range. Errors wrap models.ErrBadPromptTemplate.
+func (t Template) Validate() error {
+	if strings.TrimSpace(t.UserPrompt) == "" {
+		return fmt.Errorf("%w: user prompt is empty", models.ErrBadPromptTemplate)
+	}
+	if t.Temperature < 0 || t.Temperature > 1 {
+		return fmt.Errorf("%w: temperature must be in [0, 1]", models.ErrBadPromptTemplate)
+	}
+	if t.MaxTokens <= 0 {
+		return fmt.Errorf("%w: max tokens must be positive", models.ErrBadPromptTemplate)
+	}
+
+	variables := make(map[string]string, len(t.Variables))
+	for _, variable := range t.Variables {
+		variables[variable] = ""
+	}
+	if _, _, err := t.Render(variables); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrBadPromptTemplate, err)
+	}
+	return nil
+}
+
+// Render substitutes variables into the prompts. Reference to a variable
+// missing in variables is an error.
+func (t Template) Render(variables map[string]string) (systemPrompt string, userPrompt string, err error) {
+	systemPrompt, err = render(t.Name+".system", t.SystemPrompt, variables)
+	if err != nil {
+		return "", "", err
+	}
+	userPrompt, err = render(t.Name+".user", t.UserPrompt, variables)
+	if err != nil {
+		return "", "", err
+	}
+	return systemPrompt, userPrompt, nil
+}
+
+// AskLLMAction renders the template into ask_llm action with model options of
+// the template. Rendered user prompt is the only message.
+func (t Template) AskLLMAction(variables map[string]string) (internals.TaskActionAskLLM, error) {
+	systemPrompt, userPrompt, err := t.Render(variables)
+	if err != nil {
+		return internals.TaskActionAskLLM{}, fmt.Errorf("failed to render prompt template %s: %w", t.Name, err)
+	}
+
+	temperature := t.Temperature
+	maxTokens := t.MaxTokens
+	action := internals.TaskActionAskLLM{
+		TaskActionType: internals.AskLlm,
+		Model:          t.Model,
+		Temperature:    &temperature,
+		MaxTokens:      &maxTokens,
+		Messages: []internals.LLMMessage{
+			{
+				Role:    llm_client.RoleUser,
+				Content: userPrompt,
+			},
+		},
+		PromptTemplate: &internals.PromptTemplateRef{
+			Name:       t.Name,
+			Version:    t.Version,
+			Overridden: t.Overridden,
+		},
+	}
+	if systemPrompt != "" {
+		action.SystemPrompt = &systemPrompt
+	}
+	return action, nil
+}
+
+func (t Template) clone() Template {
+	t.Variables = slices.Clone(t.Variables)
+	return t
+}
+
+func render(name string, source string, variables map[string]string) (string, error) {
+	parsed, err := template.New(name).Option("missingkey=error").Parse(source)
+	if err != nil {
+		return "", err
+	}
+	var rendered strings.Builder
+	if err := parsed.Execute(&rendered, variables); err != nil {
+		return "", err
+	}
+	return rendered.String(), nil
+}
@@ -0,0 +1,111 @@
+package prompts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+type fakeOverrideSource struct {
+	override *internals.PromptTemplateOverride
+}
+
+func (s fakeOverrideSource) GetLatestPromptTemplateOverride(string) (*internals.PromptTemplateOverride, error) {
+	if s.override == nil {
+		return nil, models.ErrNoRows
+	}
+	return s.override, nil
+}
+
+func TestBuiltinTemplatesAreValid(t *testing.T) {
+	t.Parallel()
+
+	for _, template := range Builtins() {
+		require.NoError(t, template.Validate(), template.Name)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	template := Template{
+		Name:        "test",
+		Variables:   []string{"name"},
+		UserPrompt:  "Привет, {{.name}}",
+		Temperature: 0.5,
+		MaxTokens:   10,
+	}
+	require.NoError(t, template.Validate())
+
+	undeclared := template
+	undeclared.UserPrompt = "{{.surname}}"
+	require.ErrorIs(t, undeclared.Validate(), models.ErrBadPromptTemplate)
+
+	unparsable := template
+	unparsable.SystemPrompt = "{{.name"
+	require.ErrorIs(t, unparsable.Validate(), models.ErrBadPromptTemplate)
+
+	hot := template
+	hot.Temperature = 1.5
+	require.ErrorIs(t, hot.Validate(), models.ErrBadPromptTemplate)
+}
+
+func TestAskLLMAction(t *testing.T) {
+	t.Parallel()
+
+	template, ok := Builtin(GitHubPRRephraseParagraph)
+	require.True(t, ok)
+
+	action, err := template.AskLLMAction(map[string]string{
+		"pr_description": "Поднимаем цену",
+		"pr_patch":       "+price = 200",
+		"paragraph":      "Цена 100",
+	})
+	require.NoError(t, err)
+	require.Contains(t, *action.SystemPrompt, "Описание PR: Поднимаем цену")
+	require.Contains(t, *action.SystemPrompt, "PR Patch: +price = 200")
+	require.Equal(t, []internals.LLMMessage{{Role: "user", Content: "Цена 100"}}, action.Messages)
+	require.Equal(t, template.Temperature, *action.Temperature)
+	require.Equal(t, template.MaxTokens, *action.MaxTokens)
+	require.Equal(t, &internals.PromptTemplateRef{Name: GitHubPRRephraseParagraph, Version: template.Version}, action.PromptTemplate)
+
+	_, err = template.AskLLMAction(map[string]string{"paragraph": "Цена 100"})
+	require.Error(t, err)
+}
+
+func TestActive(t *testing.T) {
+	t.Parallel()
+
+	_, err := Active(fakeOverrideSource{}, "unknown")
+	require.ErrorIs(t, err, models.ErrNoPromptTemplate)
+
+	builtin, _ := Builtin(GitHubPRSearchQueries)
+	template, err := Active(fakeOverrideSource{}, GitHubPRSearchQueries)
+	require.NoError(t, err)
+	require.Equal(t, builtin, template)
+
+	override := &internals.PromptTemplateOverride{
+		Name:        GitHubPRSearchQueries,
+		Version:     builtin.Version + 1,
+		UserPrompt:  "Запросы для {{.product_changes}}",
+		Temperature: 0.7,
+		MaxTokens:   100,
+		CreatedAt:   time.Now(),
+	}
+	template, err = Active(fakeOverrideSource{override: override}, GitHubPRSearchQueries)
+	require.NoError(t, err)
+	require.True(t, template.Overridden)
+	require.Equal(t, override.Version, template.Version)
+	require.Equal(t, override.UserPrompt, template.UserPrompt)
+	require.Equal(t, 0.7, template.Temperature)
+	require.Equal(t, builtin.Variables, template.Variables)
+
+	// Built-in template updated after the override replaces it.
+	override.Version = builtin.Version
+	template, err = Active(fakeOverrideSource{override: override}, GitHubPRSearchQueries)
+	require.NoError(t, err)
+	require.Equal(t, builtin, template)
+}
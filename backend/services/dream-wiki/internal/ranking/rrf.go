@@ -0,0 +1,58 @@
+package ranking
+
+import "slices"
+
+type (
+	// RankedList is an output of one retriever: item keys from the best to the worst.
+	RankedList struct {
+		Retriever string
+		Weight    float64
+		Keys      []string
+	}
+
+	FusedItem struct {
+		Key   string
+		Score float64
+		// Ranks maps retriever name to 1-based rank of the item in its list.
+		Ranks map[string]int
+	}
+)
+
+// ReciprocalRankFusion merges ranked lists with weighted RRF: item score is
+// sum of weight/(k+rank) over lists which contain the item. Bigger k flattens
+// difference between top and lower ranks. Items with equal scores keep order of
+// their first appearance.
+func ReciprocalRankFusion(k float64, lists ...RankedList) []FusedItem {
+	fused := make([]FusedItem, 0)
+	positions := make(map[string]int)
+
+	for _, list := range lists {
+		for i, key := range list.Keys {
+			position, exists := positions[key]
+			if !exists {
+				position = len(fused)
+				positions[key] = position
+				fused = append(fused, FusedItem{Key: key, Ranks: make(map[string]int)})
+			}
+			if _, ranked := fused[position].Ranks[list.Retriever]; ranked {
+				continue
+			}
+
+			rank := i + 1
+			fused[position].Ranks[list.Retriever] = rank
+			fused[position].Score += list.Weight / (k + float64(rank))
+		}
+	}
+
+	slices.SortStableFunc(fused, func(a, b FusedItem) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+
+	return fused
+}
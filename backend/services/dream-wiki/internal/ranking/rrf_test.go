@@ -0,0 +1,49 @@
+package ranking
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReciprocalRankFusion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("items found by both retrievers go first", func(t *testing.T) {
+		t.Parallel()
+
+		fused := ReciprocalRankFusion(60,
+			RankedList{Retriever: "terms", Weight: 1, Keys: []string{"a", "b", "c"}},
+			RankedList{Retriever: "embedding", Weight: 1, Keys: []string{"d", "c", "a"}},
+		)
+
+		keys := make([]string, 0, len(fused))
+		for _, item := range fused {
+			keys = append(keys, item.Key)
+		}
+		require.Equal(t, []string{"a", "c", "d", "b"}, keys)
+		require.Equal(t, map[string]int{"terms": 1, "embedding": 3}, fused[0].Ranks)
+		require.InDelta(t, 1.0/61+1.0/63, fused[0].Score, 1e-12)
+	})
+
+	t.Run("weights change the order", func(t *testing.T) {
+		t.Parallel()
+
+		fused := ReciprocalRankFusion(60,
+			RankedList{Retriever: "terms", Weight: 1, Keys: []string{"a"}},
+			RankedList{Retriever: "embedding", Weight: 2, Keys: []string{"b"}},
+		)
+
+		require.Equal(t, "b", fused[0].Key)
+		require.Equal(t, "a", fused[1].Key)
+	})
+
+	t.Run("duplicates inside one list are counted once", func(t *testing.T) {
+		t.Parallel()
+
+		fused := ReciprocalRankFusion(0, RankedList{Retriever: "terms", Weight: 1, Keys: []string{"a", "a"}})
+
+		require.Len(t, fused, 1)
+		require.InDelta(t, 1.0, fused[0].Score, 1e-12)
+	})
+}
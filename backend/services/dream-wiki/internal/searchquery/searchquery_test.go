@@ -0,0 +1,61 @@
+package searchquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"deploy", "deploy", 0},
+		{"deploy", "", 6},
+		{"deploy", "depoly", 1},
+		{"deploy", "deplyo", 1},
+		{"deploy", "deplo", 1},
+		{"deploy", "dxploy", 1},
+		{"kitten", "sitting", 3},
+		{"развёртывание", "развертывание", 1},
+		{"сервис", "сервси", 1},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, EditDistance(tt.a, tt.b), "%q -> %q", tt.a, tt.b)
+		require.Equal(t, tt.expected, EditDistance(tt.b, tt.a), "%q -> %q", tt.b, tt.a)
+	}
+}
+
+func TestCorrectTypo(t *testing.T) {
+	t.Parallel()
+
+	vocabulary := []string{"deploy", "deployment", "develop", "helm", "сервис", "сервер"}
+
+	require.Equal(t, []string{"deploy"}, CorrectTypo("depoly", vocabulary))
+	require.Equal(t, []string{"deployment"}, CorrectTypo("deplymnet", vocabulary))
+	require.Equal(t, []string{"сервис"}, CorrectTypo("сервси", vocabulary))
+	// Equally close terms are all returned.
+	require.Equal(t, []string{"сервер", "сервис"}, CorrectTypo("сервес", vocabulary))
+	// Short terms are not corrected.
+	require.Nil(t, CorrectTypo("hlm", vocabulary))
+	require.Nil(t, CorrectTypo("kubernetes", vocabulary))
+}
+
+func TestSynonymExpansions(t *testing.T) {
+	t.Parallel()
+
+	groups := [][]string{
+		{"PR", "pull request", "merge request"},
+		{"k8s", "Kubernetes"},
+	}
+
+	require.Equal(t, []string{"pull request", "merge request"}, SynonymExpansions("How to review a PR?", groups))
+	require.Equal(t, []string{"pr", "merge request"}, SynonymExpansions("Pull  Request template", groups))
+	require.Equal(t, []string{"pull request", "merge request", "kubernetes"}, SynonymExpansions("k8s PR", groups))
+	// Phrases match whole words only.
+	require.Empty(t, SynonymExpansions("PRoject pull", groups))
+}
@@ -0,0 +1,58 @@
+package searchquery
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Words splits text into lowercase words, punctuation separates words.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// NormalizePhrase turns phrase into the form in which it is matched against
+// queries.
+func NormalizePhrase(phrase string) string {
+	return strings.Join(Words(phrase), " ")
+}
+
+func containsWords(words []string, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(phrase)], phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// SynonymExpansions returns phrases which are synonymous to the query: if any
+// phrase of a group occurs in the query as a whole word sequence, other
+// phrases of the group are returned. groups are lists of equivalent phrases,
+// e.g. {"PR", "pull request"}.
+func SynonymExpansions(query string, groups [][]string) []string {
+	queryWords := Words(query)
+
+	expansions := make([]string, 0)
+	for _, group := range groups {
+		matched := slices.ContainsFunc(group, func(phrase string) bool {
+			return containsWords(queryWords, Words(phrase))
+		})
+		if !matched {
+			continue
+		}
+		for _, phrase := range group {
+			normalized := NormalizePhrase(phrase)
+			if normalized == "" || containsWords(queryWords, Words(phrase)) || slices.Contains(expansions, normalized) {
+				continue
+			}
+			expansions = append(expansions, normalized)
+		}
+	}
+	return expansions
+}
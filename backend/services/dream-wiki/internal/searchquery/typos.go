@@ -0,0 +1,92 @@
+package searchquery
+
+import (
+	"slices"
+	"unicode/utf8"
+)
+
+// maxCorrections bounds the number of indexed terms a misspelled term is
+// replaced with.
+const maxCorrections = 3
+
+// EditDistance is Damerau-Levenshtein distance (optimal string alignment) in
+// runes: swap of adjacent runes costs 1, like insertion, deletion and
+// substitution.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	// Three rows are enough: transposition looks two rows back.
+	prevPrev := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				current[j] = min(current[j], prevPrev[j-2]+1)
+			}
+		}
+		prevPrev, prev, current = prev, current, prevPrev
+	}
+	return prev[len(rb)]
+}
+
+// MaxTypoDistance is how many edits a term may be away from the indexed one.
+// Short terms are not corrected, because almost any short term is a few edits
+// away from some other term.
+func MaxTypoDistance(term string) int {
+	switch length := utf8.RuneCountInString(term); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// CorrectTypo returns the closest vocabulary terms within MaxTypoDistance of
+// term, at most maxCorrections of them. Nil is returned if there are none.
+func CorrectTypo(term string, vocabulary []string) []string {
+	maxDistance := MaxTypoDistance(term)
+	if maxDistance == 0 {
+		return nil
+	}
+
+	length := utf8.RuneCountInString(term)
+	bestDistance := maxDistance + 1
+	var corrections []string
+	for _, candidate := range vocabulary {
+		// Length difference is a lower bound of the distance.
+		if abs(utf8.RuneCountInString(candidate)-length) >= bestDistance {
+			continue
+		}
+		distance := EditDistance(term, candidate)
+		switch {
+		case distance < bestDistance:
+			bestDistance = distance
+			corrections = []string{candidate}
+		case distance == bestDistance:
+			corrections = append(corrections, candidate)
+		}
+	}
+
+	slices.Sort(corrections)
+	corrections = slices.Compact(corrections)
+	return corrections[:min(len(corrections), maxCorrections)]
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,259 @@
+package ask_question
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const (
+	sourcesCount = 5
+	// contextSize is number of neighbour paragraphs taken on each side of found one.
+	contextSize = 1
+
+	noAnswerMessage = "В базе знаний не нашлось информации для ответа на этот вопрос."
+)
+
+var sourceReferenceRegexp = regexp.MustCompile(`\[(\d+)\]`)
+
+type (
+	// askQuestionTask answers user question with LLM grounded on search results.
+	// Sources are retrieved on new_task, and the answer comes with ask_llm result.
+	askQuestionTask struct {
+		taskID api.TaskID
+		status api.TaskStatus
+		state  internals.TaskStateAskQuestion
+		ctx    context.Context
+		deps   *deps.Deps
+		repo   repository.AppRepository
+	}
+)
+
+var (
+	_ task_common.TaskLogic = (*askQuestionTask)(nil)
+)
+
+func NewAskQuestionTask(ctx context.Context, state internals.TaskStateAskQuestion, deps *task_common.TaskDeps) *askQuestionTask {
+	return &askQuestionTask{
+		state:  state,
+		status: deps.Digest.Status,
+		ctx:    ctx,
+		deps:   deps.Deps,
+		taskID: deps.Digest.TaskId,
+		repo:   deps.Repo,
+	}
+}
+
+func (t *askQuestionTask) CalculateSubtasks() ([]api.Subtask, error) {
+	retrieveStatus := api.Executing
+	answerStatus := api.Executing
+	if t.state.Sources != nil {
+		retrieveStatus = api.Done
+	}
+	if t.state.Answer != nil {
+		answerStatus = api.Done
+	}
+	if t.status != api.Executing && t.status != api.Done {
+		if retrieveStatus != api.Done {
+			retrieveStatus = t.status
+			answerStatus = api.Cancelled
+		} else if answerStatus != api.Done {
+			answerStatus = t.status
+		}
+	}
+
+	return []api.Subtask{
+		{
+			Description: "Search for relevant documentation",
+			Status:      retrieveStatus,
+			Subsubtasks: []api.SubSubtask{},
+		},
+		{
+			Description: "Answer the question with LLM",
+			Status:      answerStatus,
+			Subsubtasks: []api.SubSubtask{},
+		},
+	}, nil
+}
+
+func (t *askQuestionTask) updateState() error {
+	taskState := internals.TaskState{}
+	err := taskState.FromTaskStateAskQuestion(t.state)
+	if err != nil {
+		return err
+	}
+	return t.repo.SetTaskState(t.taskID, taskState)
+}
+
+func (t *askQuestionTask) retrieveSources() error {
+	embedding, err := t.deps.InferenceClient.GenerateEmbedding(t.ctx, t.state.Question)
+	if err != nil {
+		return fmt.Errorf("failed to generate embedding: %w", err)
+	}
+
+	found, err := t.repo.SearchByEmbedding(t.state.Question, internals.Embedding(embedding), t.state.Filters, sourcesCount)
+	if err != nil {
+		return fmt.Errorf("failed to search by embedding: %w", err)
+	}
+
+	sources := make([]internals.AskQuestionSource, 0, len(found))
+	for _, item := range found {
+		content := item.ParagraphContent
+		paragraphWithContext, err := t.repo.GetParagraphWithContext(item.PageId, item.ParagraphIndex, contextSize)
+		if err != nil {
+			t.deps.Logger.Warn("failed to get paragraph context, using paragraph only: ", err)
+		} else {
+			content = paragraphWithContext.Content
+		}
+
+		sources = append(sources, internals.AskQuestionSource{
+			PageId:         item.PageId,
+			PageSlug:       item.PageSlug,
+			PageTitle:      item.PageTitle,
+			AnchorSlug:     item.AnchorSlug,
+			LineIndex:      item.LineIndex,
+			ParagraphIndex: item.ParagraphIndex,
+			Headers:        item.Headers,
+			Content:        content,
+		})
+	}
+
+	t.state.Sources = &sources
+	return nil
+}
+
+// makeSystemPrompt lists retrieved sources, the question is sent as the user
+// message.
+func (t *askQuestionTask) makeSystemPrompt() string {
+	sourcesText := strings.Builder{}
+	for i, source := range *t.state.Sources {
+		fmt.Fprintf(&sourcesText, "[%d] Страница «%s»", i+1, source.PageTitle)
+		headers := slices.DeleteFunc(slices.Clone(source.Headers), func(header string) bool { return header == "" })
+		if len(headers) > 0 {
+			fmt.Fprintf(&sourcesText, ", раздел «%s»", strings.Join(headers, " / "))
+		}
+		fmt.Fprintf(&sourcesText, ":\n%s\n\n", source.Content)
+	}
+
+	return fmt.Sprintf(`Ты отвечаешь на вопросы сотрудников по корпоративной базе знаний.
+Отвечай только на основе приведённых ниже источников. Ничего не добавляй от себя.
+После каждого утверждения укажи номер источника в квадратных скобках, например [1] или [2][3].
+Если в источниках нет ответа на вопрос, так и напиши и не ссылайся на источники.
+Отвечай на языке вопроса, кратко, в формате Markdown.
+
+Источники:
+
+%s`, sourcesText.String())
+}
+
+func (t *askQuestionTask) createAskLLMAction() error {
+	systemPrompt := t.makeSystemPrompt()
+	taskAction := internals.TaskAction{}
+	err := taskAction.FromTaskActionAskLLM(internals.TaskActionAskLLM{
+		TaskActionType: internals.AskLlm,
+		Model:          internals.Yandexgpt5Lite,
+		SystemPrompt:   &systemPrompt,
+		Messages: []internals.LLMMessage{
+			{
+				Role:    llm_client.RoleUser,
+				Content: t.state.Question,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	taskActionID, err := t.repo.CreateTaskAction(t.taskID, taskAction)
+	if err != nil {
+		return err
+	}
+
+	return t.repo.EnqueueTaskAction(*taskActionID)
+}
+
+// citedSourceNumbers returns numbers of existing sources referenced as [n] in
+// the answer, in order of their first reference.
+func citedSourceNumbers(answer string, sourcesCount int) []int {
+	cited := make([]int, 0)
+	for _, match := range sourceReferenceRegexp.FindAllStringSubmatch(answer, -1) {
+		number, err := strconv.Atoi(match[1])
+		if err != nil || number < 1 || number > sourcesCount || slices.Contains(cited, number) {
+			continue
+		}
+		cited = append(cited, number)
+	}
+	return cited
+}
+
+func (t *askQuestionTask) finish(answer string) error {
+	cited := citedSourceNumbers(answer, len(*t.state.Sources))
+	t.state.Answer = &answer
+	t.state.CitedSourceNumbers = &cited
+	err := t.updateState()
+	if err != nil {
+		return err
+	}
+	return t.repo.SetTaskStatus(t.taskID, api.Done)
+}
+
+func (t *askQuestionTask) OnActionResult(result internals.TaskActionResult) error {
+	resultType, err := result.Discriminator()
+	if err != nil {
+		return err
+	}
+
+	switch internals.TaskActionType(resultType) {
+	case internals.NewTask:
+		err = t.retrieveSources()
+		if err != nil {
+			return err
+		}
+
+		if len(*t.state.Sources) == 0 {
+			err = t.finish(noAnswerMessage)
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		err = t.updateState()
+		if err != nil {
+			return err
+		}
+		err = t.createAskLLMAction()
+		if err != nil {
+			return err
+		}
+
+	case internals.AskLlm:
+		askLLMResult, err := result.AsTaskActionResultAskLLM()
+		if err != nil {
+			return err
+		}
+		if t.state.Sources == nil {
+			return fmt.Errorf("LLM answered before sources were retrieved")
+		}
+
+		err = t.finish(strings.TrimSpace(askLLMResult.ResponseMessage))
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unexpected task action result type: %s", resultType)
+	}
+
+	return t.repo.Commit()
+}
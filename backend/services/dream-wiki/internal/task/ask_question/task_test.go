@@ -0,0 +1,189 @@
+package ask_question
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/inference_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+type fakeInferenceClient struct {
+	inference_client.InferenceClient
+}
+
+func (c *fakeInferenceClient) GenerateEmbedding(context.Context, string) (internals.Embedding, error) {
+	return internals.Embedding{1, 0}, nil
+}
+
+// askTestEnv keeps the task and indexed pages in memory storage and accounts
+// action results in place of results topic reader.
+type askTestEnv struct {
+	storage repository.Storage
+	taskID  api.TaskID
+}
+
+func newAskTestEnv(t *testing.T) *askTestEnv {
+	t.Helper()
+	return &askTestEnv{storage: repository.NewMemoryStorage(logger.InitTestLogger(), nil, nil)}
+}
+
+// createPage stores page with given paragraphs, farther from the question the
+// later they come.
+func (e *askTestEnv) createPage(t *testing.T, title string, paragraphs ...internals.ParagraphWithEmbedding) api.PageID {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	pageID, err := repo.CreatePage(title, title, "")
+	require.NoError(t, err)
+	for i, paragraph := range paragraphs {
+		paragraph.PageId = *pageID
+		paragraph.Content = "context"
+		paragraph.Embedding = internals.Embedding{1, float32(i)}
+		require.NoError(t, repo.AddIndexedParagraph(paragraph))
+	}
+	require.NoError(t, repo.Commit())
+	return *pageID
+}
+
+// createTask stores task asking question about given pages.
+func (e *askTestEnv) createTask(t *testing.T, pageIDs []api.PageID) {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	var state internals.TaskState
+	require.NoError(t, state.FromTaskStateAskQuestion(internals.TaskStateAskQuestion{
+		TaskType: internals.AskQuestion,
+		Question: "Сколько стоит доставка?",
+		Filters:  internals.SearchFilters{PageIds: &pageIDs},
+	}))
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+	e.taskID = *taskID
+}
+
+func (e *askTestEnv) onResult(t *testing.T, result internals.TaskActionResult) {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	digest, state, err := repo.GetTaskByID(e.taskID)
+	require.NoError(t, err)
+	askState, err := state.AsTaskStateAskQuestion()
+	require.NoError(t, err)
+	task := NewAskQuestionTask(context.Background(), askState, &task_common.TaskDeps{
+		Deps:   &deps.Deps{Logger: logger.InitTestLogger(), InferenceClient: &fakeInferenceClient{}},
+		Digest: *digest,
+		State:  state,
+		Repo:   repo,
+	})
+	require.NoError(t, task.OnActionResult(result))
+}
+
+func (e *askTestEnv) task(t *testing.T) (api.TaskStatus, internals.TaskStateAskQuestion) {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	digest, state, err := repo.GetTaskByID(e.taskID)
+	require.NoError(t, err)
+	askState, err := state.AsTaskStateAskQuestion()
+	require.NoError(t, err)
+	return digest.Status, askState
+}
+
+// actions returns actions created by the task in creation order.
+func (e *askTestEnv) actions(t *testing.T) []internals.TaskAction {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	actionIDs, err := repo.GetTaskActionIDsByStatus(e.taskID, []internals.TaskActionStatus{internals.New})
+	require.NoError(t, err)
+	actions := make([]internals.TaskAction, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		action, _, err := repo.GetTaskActionByID(actionID)
+		require.NoError(t, err)
+		actions = append(actions, *action)
+	}
+	return actions
+}
+
+func newTaskResult(t *testing.T) internals.TaskActionResult {
+	t.Helper()
+	var result internals.TaskActionResult
+	require.NoError(t, result.FromTaskActionResultNewTask(internals.TaskActionResultNewTask{}))
+	return result
+}
+
+func TestAskQuestionTask(t *testing.T) {
+	t.Parallel()
+
+	t.Run("answer cites retrieved sources", func(t *testing.T) {
+		t.Parallel()
+
+		env := newAskTestEnv(t)
+		anchor := "dostavka"
+		pageID := env.createPage(t, "Доставка",
+			internals.ParagraphWithEmbedding{AnchorSlug: &anchor, Headers: []string{"Цены"}, ParagraphIndex: 3, LineNumber: 10},
+			internals.ParagraphWithEmbedding{ParagraphIndex: 7, LineNumber: 20},
+		)
+		// Page out of filters is not searched.
+		env.createPage(t, "Оплата", internals.ParagraphWithEmbedding{ParagraphIndex: 0})
+		env.createTask(t, []api.PageID{pageID})
+
+		env.onResult(t, newTaskResult(t))
+		actions := env.actions(t)
+		require.Len(t, actions, 1)
+		_, state := env.task(t)
+		require.Len(t, *state.Sources, 2)
+		for _, source := range *state.Sources {
+			require.Equal(t, pageID, source.PageId)
+		}
+		require.Equal(t, "context", (*state.Sources)[0].Content)
+
+		askLLM, err := actions[0].AsTaskActionAskLLM()
+		require.NoError(t, err)
+		require.NotNil(t, askLLM.SystemPrompt)
+		require.Contains(t, *askLLM.SystemPrompt, "[1] Страница «Доставка», раздел «Цены»:\ncontext")
+		require.Contains(t, *askLLM.SystemPrompt, "[2] Страница «Доставка»:\ncontext")
+		require.Len(t, askLLM.Messages, 1)
+		require.Equal(t, "Сколько стоит доставка?", askLLM.Messages[0].Content)
+
+		var llmResult internals.TaskActionResult
+		require.NoError(t, llmResult.FromTaskActionResultAskLLM(internals.TaskActionResultAskLLM{
+			TaskActionType:  internals.AskLlm,
+			ResponseMessage: " Доставка бесплатная [2], но не везде [2][1][9].\n",
+		}))
+		env.onResult(t, llmResult)
+
+		status, state := env.task(t)
+		require.Equal(t, api.Done, status)
+		require.Equal(t, "Доставка бесплатная [2], но не везде [2][1][9].", *state.Answer)
+		require.Equal(t, []int{2, 1}, *state.CitedSourceNumbers)
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		t.Parallel()
+
+		env := newAskTestEnv(t)
+		env.createTask(t, []api.PageID{{1}})
+		env.onResult(t, newTaskResult(t))
+
+		require.Empty(t, env.actions(t))
+		status, state := env.task(t)
+		require.Equal(t, api.Done, status)
+		require.Equal(t, noAnswerMessage, *state.Answer)
+		require.Empty(t, *state.CitedSourceNumbers)
+	})
+}
@@ -0,0 +1,124 @@
+package github_account_pr
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// paragraphRewrite replaces lines [startLine, endLine] of page content.
+// paragraphIndexes are indexes of the rewritten chunks as they are indexed.
+type paragraphRewrite struct {
+	startLine        int
+	endLine          int
+	paragraphIndexes []int
+	content          string
+}
+
+// locateHotParagraph returns chunks of the page, as split by
+// indexing.SplitPageToChunks with the indexing policy, which the hot paragraph
+// consists of. Hot paragraph is a run of consecutive chunks joined by
+// SearchByEmbeddingWithContext, and its ParagraphIndex is the index of one of
+// them. Hot paragraphs come from the search index, so ok is false if the page
+// has changed since it was indexed.
+func locateHotParagraph(pageChunks []internals.ParagraphWithEmbedding, hot internals.ParagraphWithContext) (chunks []internals.ParagraphWithEmbedding, ok bool) {
+	for first, chunk := range pageChunks {
+		if chunk.IsHeader || chunk.LineNumber != hot.StartLineNumber || chunk.ParagraphIndex > hot.ParagraphIndex {
+			continue
+		}
+		contents := make([]string, 0)
+		for last := first; last < len(pageChunks) && !pageChunks[last].IsHeader; last++ {
+			contents = append(contents, pageChunks[last].Content)
+			if pageChunks[last].ParagraphIndex >= hot.ParagraphIndex &&
+				pageChunks[last].EndLineNumber == hot.EndLineNumber &&
+				strings.Join(contents, "\n\n") == hot.Content {
+				return pageChunks[first : last+1], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// rewriteHotParagraph turns rephrased hot paragraph into rewrites of the page
+// lines its chunks were taken from. If the rephrased text keeps the number of
+// chunks and chunks do not share lines, every chunk is patched separately, so
+// lines between them stay intact and only changed chunks are recorded.
+// Otherwise the whole text of the hot paragraph is replaced.
+func rewriteHotParagraph(content string, chunks []internals.ParagraphWithEmbedding, rephrased string) []paragraphRewrite {
+	lines := strings.Split(content, "\n")
+	parts := strings.Split(rephrased, "\n\n")
+	if len(parts) != len(chunks) || chunksShareLines(chunks) {
+		return []paragraphRewrite{replaceChunksText(lines, chunks, rephrased)}
+	}
+
+	rewrites := make([]paragraphRewrite, 0)
+	for i, chunk := range chunks {
+		part := strings.TrimSpace(parts[i])
+		if part == chunk.Content {
+			continue
+		}
+		rewrites = append(rewrites, replaceChunksText(lines, chunks[i:i+1], part))
+	}
+	return rewrites
+}
+
+// chunksShareLines tells whether chunks of a paragraph split by sentences, or
+// overlapping ones, are taken from the same lines.
+func chunksShareLines(chunks []internals.ParagraphWithEmbedding) bool {
+	for i := 1; i < len(chunks); i++ {
+		if chunks[i].LineNumber <= chunks[i-1].EndLineNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceChunksText rewrites lines of the chunks replacing text from the start
+// of the first chunk to the end of the last one. Chunk of a paragraph split by
+// sentences may start or end in the middle of a line, the rest of the line is
+// kept.
+func replaceChunksText(lines []string, chunks []internals.ParagraphWithEmbedding, content string) paragraphRewrite {
+	first, last := chunks[0], chunks[len(chunks)-1]
+	rewrite := paragraphRewrite{
+		startLine: first.LineNumber,
+		endLine:   last.EndLineNumber,
+		content:   content,
+	}
+	for _, chunk := range chunks {
+		rewrite.paragraphIndexes = append(rewrite.paragraphIndexes, chunk.ParagraphIndex)
+	}
+
+	text := strings.Join(lines[first.LineNumber:last.EndLineNumber+1], "\n")
+	start := strings.Index(text, first.Content)
+	if start < 0 {
+		return rewrite
+	}
+	end := strings.Index(text[start:], last.Content)
+	if end < 0 {
+		return rewrite
+	}
+	end += start + len(last.Content)
+	rewrite.content = text[:start] + content + text[end:]
+	return rewrite
+}
+
+func (r paragraphRewrite) overlaps(other paragraphRewrite) bool {
+	return r.startLine <= other.endLine && other.startLine <= r.endLine
+}
+
+// patchPage applies rewrites to page content. Rewrites must not overlap.
+func patchPage(content string, rewrites []paragraphRewrite) string {
+	lines := strings.Split(content, "\n")
+	rewrites = slices.Clone(rewrites)
+	// Patching from the end keeps line numbers of remaining rewrites valid.
+	slices.SortFunc(rewrites, func(a, b paragraphRewrite) int {
+		return b.startLine - a.startLine
+	})
+	for _, rewrite := range rewrites {
+		patched := slices.Clone(lines[:rewrite.startLine])
+		patched = append(patched, strings.Split(rewrite.content, "\n")...)
+		lines = append(patched, lines[rewrite.endLine+1:]...)
+	}
+	return strings.Join(lines, "\n")
+}
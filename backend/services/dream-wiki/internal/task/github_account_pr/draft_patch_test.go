@@ -0,0 +1,179 @@
+package github_account_pr
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/indexing"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const draftPatchTestPage = `# Доставка
+
+Доставка стоит 100 рублей.
+
+---
+
+Курьер звонит за час.
+
+Доставка стоит 100 рублей.`
+
+// draftPatchTestPolicy keeps paragraphs of draftPatchTestPage as they are.
+var draftPatchTestPolicy = indexing.ChunkingPolicy{MaxTokens: 256}
+
+// oversizedParagraphTestPage has paragraph of four sentences of 6-7 tokens
+// each, which is split by indexing.
+const oversizedParagraphTestPage = `# Доставка
+
+Доставка стоит 100 рублей. Курьер звонит за час. Оплата картой курьеру. Самовывоз бесплатный.
+
+Заказ привозят в течение дня.`
+
+func TestLocateHotParagraph(t *testing.T) {
+	t.Parallel()
+
+	pageChunks := indexing.SplitPageToChunks(uuid.New(), draftPatchTestPage, draftPatchTestPolicy)
+
+	// Context of two chunks has index of the last one.
+	paragraphs, ok := locateHotParagraph(pageChunks, internals.ParagraphWithContext{
+		ParagraphIndex:  2,
+		StartLineNumber: 2,
+		EndLineNumber:   6,
+		Content:         "Доставка стоит 100 рублей.\n\nКурьер звонит за час.",
+	})
+	require.True(t, ok)
+	require.Len(t, paragraphs, 2)
+	require.Equal(t, 1, paragraphs[0].ParagraphIndex)
+	require.Equal(t, 2, paragraphs[1].ParagraphIndex)
+
+	// Page has changed since the paragraph was indexed.
+	_, ok = locateHotParagraph(pageChunks, internals.ParagraphWithContext{
+		ParagraphIndex:  1,
+		StartLineNumber: 2,
+		EndLineNumber:   2,
+		Content:         "Доставка стоит 50 рублей.",
+	})
+	require.False(t, ok)
+}
+
+func TestRewriteHotParagraph(t *testing.T) {
+	t.Parallel()
+
+	pageChunks := indexing.SplitPageToChunks(uuid.New(), draftPatchTestPage, draftPatchTestPolicy)
+	paragraphs := pageChunks[1:3]
+
+	// Same number of paragraphs: only the changed one is patched, so the
+	// thematic break between paragraphs stays.
+	rewrites := rewriteHotParagraph(draftPatchTestPage, paragraphs, "Доставка стоит 200 рублей.\n\nКурьер звонит за час.")
+	require.Equal(t, []paragraphRewrite{
+		{startLine: 2, endLine: 2, paragraphIndexes: []int{1}, content: "Доставка стоит 200 рублей."},
+	}, rewrites)
+	require.Equal(t, `# Доставка
+
+Доставка стоит 200 рублей.
+
+---
+
+Курьер звонит за час.
+
+Доставка стоит 100 рублей.`, patchPage(draftPatchTestPage, rewrites))
+
+	rewrites = rewriteHotParagraph(draftPatchTestPage, paragraphs, "Доставка стоит 200 рублей, курьер звонит за час.")
+	require.Equal(t, []paragraphRewrite{
+		{startLine: 2, endLine: 6, paragraphIndexes: []int{1, 2}, content: "Доставка стоит 200 рублей, курьер звонит за час."},
+	}, rewrites)
+	require.Equal(t, `# Доставка
+
+Доставка стоит 200 рублей, курьер звонит за час.
+
+Доставка стоит 100 рублей.`, patchPage(draftPatchTestPage, rewrites))
+}
+
+func TestRewriteOversizedParagraph(t *testing.T) {
+	t.Parallel()
+
+	t.Run("chunk in the middle of the line", func(t *testing.T) {
+		t.Parallel()
+
+		pageChunks := indexing.SplitPageToChunks(uuid.New(), oversizedParagraphTestPage, indexing.ChunkingPolicy{MaxTokens: 8})
+		require.Equal(t, "Курьер звонит за час.", pageChunks[2].Content)
+
+		// Paragraphs as split before chunking do not contain the hot one.
+		hot := internals.ParagraphWithContext{
+			ParagraphIndex:  2,
+			StartLineNumber: 2,
+			EndLineNumber:   2,
+			Content:         "Курьер звонит за час.",
+		}
+		_, ok := locateHotParagraph(indexing.SplitPageToParagraphs(uuid.New(), oversizedParagraphTestPage), hot)
+		require.False(t, ok)
+
+		chunks, ok := locateHotParagraph(pageChunks, hot)
+		require.True(t, ok)
+		require.Equal(t, pageChunks[2:3], chunks)
+
+		rewrites := rewriteHotParagraph(oversizedParagraphTestPage, chunks, "Курьер звонит за два часа.")
+		require.Equal(t, []paragraphRewrite{{
+			startLine:        2,
+			endLine:          2,
+			paragraphIndexes: []int{2},
+			content:          "Доставка стоит 100 рублей. Курьер звонит за два часа. Оплата картой курьеру. Самовывоз бесплатный.",
+		}}, rewrites)
+		require.Equal(t, `# Доставка
+
+Доставка стоит 100 рублей. Курьер звонит за два часа. Оплата картой курьеру. Самовывоз бесплатный.
+
+Заказ привозят в течение дня.`, patchPage(oversizedParagraphTestPage, rewrites))
+	})
+
+	t.Run("overlapping chunks are replaced as a whole", func(t *testing.T) {
+		t.Parallel()
+
+		pageChunks := indexing.SplitPageToChunks(uuid.New(), oversizedParagraphTestPage, indexing.ChunkingPolicy{MaxTokens: 14, OverlapTokens: 7})
+		require.Equal(t, "Курьер звонит за час. Оплата картой курьеру.", pageChunks[2].Content)
+		require.Equal(t, "Оплата картой курьеру. Самовывоз бесплатный.", pageChunks[3].Content)
+
+		chunks, ok := locateHotParagraph(pageChunks, internals.ParagraphWithContext{
+			ParagraphIndex:  3,
+			StartLineNumber: 2,
+			EndLineNumber:   2,
+			Content:         pageChunks[2].Content + "\n\n" + pageChunks[3].Content,
+		})
+		require.True(t, ok)
+		require.Equal(t, pageChunks[2:4], chunks)
+
+		// Rephrased text keeps the number of chunks, but they share the line.
+		rewrites := rewriteHotParagraph(oversizedParagraphTestPage, chunks, "Курьер звонит за два часа.\n\nОплата наличными.")
+		require.Equal(t, []paragraphRewrite{{
+			startLine:        2,
+			endLine:          2,
+			paragraphIndexes: []int{2, 3},
+			content:          "Доставка стоит 100 рублей. Курьер звонит за два часа.\n\nОплата наличными.",
+		}}, rewrites)
+	})
+}
+
+func TestPatchPage(t *testing.T) {
+	t.Parallel()
+
+	// The same text in other paragraphs is not touched, line numbers of the
+	// upper rewrite stay valid after the lower one changes number of lines.
+	patched := patchPage(draftPatchTestPage, []paragraphRewrite{
+		{startLine: 2, endLine: 2, content: "Доставка стоит 200 рублей."},
+		{startLine: 8, endLine: 8, content: "Доставка за город\nстоит 300 рублей."},
+	})
+	require.Equal(t, `# Доставка
+
+Доставка стоит 200 рублей.
+
+---
+
+Курьер звонит за час.
+
+Доставка за город
+стоит 300 рублей.`, patched)
+
+	require.True(t, paragraphRewrite{startLine: 2, endLine: 4}.overlaps(paragraphRewrite{startLine: 4, endLine: 6}))
+	require.False(t, paragraphRewrite{startLine: 2, endLine: 4}.overlaps(paragraphRewrite{startLine: 5, endLine: 6}))
+}
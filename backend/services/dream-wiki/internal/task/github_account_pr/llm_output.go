@@ -0,0 +1,102 @@
+package github_account_pr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Limits of LLM output, prompts ask for the same.
+const (
+	maxProductChanges = 2
+	maxSearchQueries  = 3
+)
+
+// errMalformedLLMOutput is returned for LLM response which does not match
+// schema of the stage output.
+var errMalformedLLMOutput = errors.New("malformed LLM output")
+
+type (
+	llmOutput interface {
+		validate() error
+	}
+
+	productChangesOutput struct {
+		Changes []string `json:"changes"`
+	}
+
+	searchQueriesOutput struct {
+		Queries []string `json:"queries"`
+	}
+
+	rephrasedParagraphOutput struct {
+		Paragraph *string `json:"paragraph"`
+		Rationale *string `json:"rationale"`
+	}
+)
+
+// parseLLMOutput decodes response which must be a single JSON object with
+// fields of output only. Markdown code fence around the object is allowed,
+// as models often add it despite the prompt.
+func parseLLMOutput(response string, output llmOutput) error {
+	decoder := json.NewDecoder(strings.NewReader(stripCodeFence(response)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(output); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedLLMOutput, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return fmt.Errorf("%w: unexpected text after JSON object", errMalformedLLMOutput)
+	}
+	if err := output.validate(); err != nil {
+		return fmt.Errorf("%w: %v", errMalformedLLMOutput, err)
+	}
+	return nil
+}
+
+func stripCodeFence(response string) string {
+	response = strings.TrimSpace(response)
+	if !strings.HasPrefix(response, "```") || !strings.HasSuffix(response, "```") {
+		return response
+	}
+	response = strings.TrimSuffix(response, "```")
+	_, body, _ := strings.Cut(response, "\n")
+	return body
+}
+
+// validateItems trims items in place and checks there are from minCount to
+// maxCount non-empty ones.
+func validateItems(field string, items []string, minCount int, maxCount int) error {
+	if items == nil {
+		return fmt.Errorf("field %q is required", field)
+	}
+	if len(items) < minCount || len(items) > maxCount {
+		return fmt.Errorf("field %q must have from %d to %d items, got %d", field, minCount, maxCount, len(items))
+	}
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+		if items[i] == "" {
+			return fmt.Errorf("item %d of field %q is empty", i, field)
+		}
+	}
+	return nil
+}
+
+func (o *productChangesOutput) validate() error {
+	return validateItems("changes", o.Changes, 0, maxProductChanges)
+}
+
+func (o *searchQueriesOutput) validate() error {
+	return validateItems("queries", o.Queries, 1, maxSearchQueries)
+}
+
+func (o *rephrasedParagraphOutput) validate() error {
+	if o.Paragraph == nil || strings.TrimSpace(*o.Paragraph) == "" {
+		return fmt.Errorf("field %q is required", "paragraph")
+	}
+	if o.Rationale == nil {
+		return fmt.Errorf("field %q is required", "rationale")
+	}
+	return nil
+}
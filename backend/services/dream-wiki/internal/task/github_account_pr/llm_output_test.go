@@ -0,0 +1,51 @@
+package github_account_pr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLLMOutput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		response string
+		valid    bool
+		queries  []string
+	}{
+		{name: "plain", response: `{"queries": ["цена"]}`, valid: true, queries: []string{"цена"}},
+		{name: "code fence", response: "```json\n{\"queries\": [\" цена \"]}\n```", valid: true, queries: []string{"цена"}},
+		{name: "lines", response: "- цена\n- стоимость"},
+		{name: "text after object", response: `{"queries": ["цена"]} Готово!`},
+		{name: "unknown field", response: `{"queries": ["цена"], "comment": "ok"}`},
+		{name: "missing field", response: `{}`},
+		{name: "empty item", response: `{"queries": ["цена", " "]}`},
+		{name: "no items", response: `{"queries": []}`},
+		{name: "too many items", response: `{"queries": ["a", "b", "c", "d"]}`},
+	}
+	for _, tt := range tests {
+		var output searchQueriesOutput
+		err := parseLLMOutput(tt.response, &output)
+		if !tt.valid {
+			require.ErrorIs(t, err, errMalformedLLMOutput, tt.name)
+			continue
+		}
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.queries, output.Queries, tt.name)
+	}
+}
+
+func TestParseRephrasedParagraph(t *testing.T) {
+	t.Parallel()
+
+	var output rephrasedParagraphOutput
+	require.NoError(t, parseLLMOutput(`{"paragraph": "Цена 200", "rationale": ""}`, &output))
+	require.Equal(t, "Цена 200", *output.Paragraph)
+
+	output = rephrasedParagraphOutput{}
+	require.ErrorIs(t, parseLLMOutput(`{"paragraph": "Цена 200"}`, &output), errMalformedLLMOutput)
+	output = rephrasedParagraphOutput{}
+	require.ErrorIs(t, parseLLMOutput(`{"paragraph": "", "rationale": "нет изменений"}`, &output), errMalformedLLMOutput)
+}
@@ -2,6 +2,7 @@ package github_account_pr
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,14 +12,26 @@ import (
 	"strings"
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/indexing"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/prompts"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ycloud_client_gen"
 )
 
+// maxLLMOutputRepairAttempts is how many times LLM is asked to fix malformed
+// output of a stage before the task fails.
+const maxLLMOutputRepairAttempts = 2
+
 type (
+	// llmOutputRepair is malformed LLM response and why it was rejected.
+	llmOutputRepair struct {
+		response string
+		err      error
+	}
+
 	gitHubAccountPRTask struct {
 		taskID api.TaskID
 		status api.TaskStatus
@@ -136,6 +149,18 @@ func (t *gitHubAccountPRTask) CalculateSubtasks() ([]api.Subtask, error) {
 		}
 	}
 
+	if t.state.FailureReason != nil {
+		for i := range subtasks {
+			if subtasks[i].Status == api.FailedByError {
+				subtasks[i].Subsubtasks = append(subtasks[i].Subsubtasks, api.SubSubtask{
+					Description: *t.state.FailureReason,
+					Status:      api.FailedByError,
+				})
+				break
+			}
+		}
+	}
+
 	return subtasks, nil
 }
 
@@ -163,7 +188,10 @@ func (t *gitHubAccountPRTask) saveChanges() error {
 	return t.repo.Commit()
 }
 
-func (t *gitHubAccountPRTask) accountResult(result internals.TaskActionResult) error {
+// accountResult stores LLM output of the stage the result was requested at.
+// Output which does not match schema of the stage is not stored, the error
+// wraps errMalformedLLMOutput then.
+func (t *gitHubAccountPRTask) accountResult(stage internals.CurrentAccountStage, result internals.TaskActionResult) error {
 	discriminator, err := result.Discriminator()
 	if err != nil {
 		return err
@@ -176,20 +204,38 @@ func (t *gitHubAccountPRTask) accountResult(result internals.TaskActionResult) e
 	if err != nil {
 		return err
 	}
-	if t.state.LlmDetectedProductChanges == nil {
-		res := strings.Split(resCast.ResponseMessage, "\n")
-		t.state.LlmDetectedProductChanges = &res
-		return nil
-	}
-	if t.state.LlmSuggestedSearchQueries == nil {
-		res := strings.Split(resCast.ResponseMessage, "\n")
-		t.state.LlmSuggestedSearchQueries = &res
-		return nil
-	}
 
-	rephrased := t.state.LlmRephrasedParagraphContents
-	*rephrased = append(*rephrased, resCast.ResponseMessage)
+	switch stage {
+	case internals.DetectProductChanges:
+		var output productChangesOutput
+		if err := parseLLMOutput(resCast.ResponseMessage, &output); err != nil {
+			return err
+		}
+		t.state.LlmDetectedProductChanges = &output.Changes
+	case internals.GenerateSearchQueries:
+		var output searchQueriesOutput
+		if err := parseLLMOutput(resCast.ResponseMessage, &output); err != nil {
+			return err
+		}
+		t.state.LlmSuggestedSearchQueries = &output.Queries
+	case internals.RephraseDocumentation:
+		var output rephrasedParagraphOutput
+		if err := parseLLMOutput(resCast.ResponseMessage, &output); err != nil {
+			return err
+		}
+		rephrased := append(*t.state.LlmRephrasedParagraphContents, *output.Paragraph)
+		t.state.LlmRephrasedParagraphContents = &rephrased
+		rationales := []string{}
+		if t.state.LlmRephraseRationales != nil {
+			rationales = *t.state.LlmRephraseRationales
+		}
+		rationales = append(rationales, *output.Rationale)
+		t.state.LlmRephraseRationales = &rationales
+	default:
+		return fmt.Errorf("unexpected LLM result at stage %s", stage)
+	}
 
+	t.state.LlmOutputRepairAttempts = nil
 	return nil
 }
 
@@ -222,7 +268,17 @@ func (t *gitHubAccountPRTask) GetCurrentAccountStage() internals.CurrentAccountS
 }
 
 func (t *gitHubAccountPRTask) OnActionResult(result internals.TaskActionResult) error {
-	t.accountResult(result)
+	resultStage := t.GetCurrentAccountStage()
+	err := t.accountResult(resultStage, result)
+	if errors.Is(err, errMalformedLLMOutput) {
+		if err := t.repairLLMOutput(resultStage, result, err); err != nil {
+			return fmt.Errorf("failed to repair LLM output: %w", err)
+		}
+		return t.saveChanges()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to account action result: %w", err)
+	}
 
 	currentStage := t.GetCurrentAccountStage()
 	fmt.Printf("Current stage is %s\n", currentStage)
@@ -232,11 +288,20 @@ func (t *gitHubAccountPRTask) OnActionResult(result internals.TaskActionResult)
 		if err := t.fetchPRData(); err != nil {
 			return fmt.Errorf("failed to fetch PR data: %w", err)
 		}
-		if err := t.askLLMForProductChanges(); err != nil {
+		if err := t.askLLMForProductChanges(nil); err != nil {
 			return fmt.Errorf("failed to ask LLM for product changes: %w", err)
 		}
 	case internals.GenerateSearchQueries:
-		if err := t.askLLMForSearchQueries(); err != nil {
+		if len(*t.state.LlmDetectedProductChanges) == 0 {
+			// Nothing in documentation can be outdated by the PR.
+			createdDraftIDs := make([]api.DraftID, 0)
+			t.state.CreatedDraftIds = &createdDraftIDs
+			if err := t.repo.SetTaskStatus(t.taskID, api.Done); err != nil {
+				return err
+			}
+			break
+		}
+		if err := t.askLLMForSearchQueries(nil); err != nil {
 			return fmt.Errorf("failed to ask LLM for search queries: %w", err)
 		}
 	case internals.SearchDocumentation:
@@ -245,11 +310,11 @@ func (t *gitHubAccountPRTask) OnActionResult(result internals.TaskActionResult)
 			return fmt.Errorf("failed to search hot paragraphs: %w", err)
 		}
 
-		if err := t.startLLMSearchAndRephrase(); err != nil {
+		if err := t.startLLMSearchAndRephrase(nil); err != nil {
 			return fmt.Errorf("failed to perform search and rephrase: %w", err)
 		}
 	case internals.RephraseDocumentation:
-		if err := t.startLLMSearchAndRephrase(); err != nil {
+		if err := t.startLLMSearchAndRephrase(nil); err != nil {
 			return fmt.Errorf("failed to perform search and rephrase: %w", err)
 		}
 	case internals.CreateDrafts:
@@ -262,6 +327,41 @@ func (t *gitHubAccountPRTask) OnActionResult(result internals.TaskActionResult)
 	return t.saveChanges()
 }
 
+// repairLLMOutput asks the LLM to fix malformed output of the stage. After
+// maxLLMOutputRepairAttempts the task fails with the reason in its state.
+func (t *gitHubAccountPRTask) repairLLMOutput(stage internals.CurrentAccountStage, result internals.TaskActionResult, outputErr error) error {
+	attempts := 0
+	if t.state.LlmOutputRepairAttempts != nil {
+		attempts = *t.state.LlmOutputRepairAttempts
+	}
+	if attempts >= maxLLMOutputRepairAttempts {
+		reason := fmt.Sprintf("stage %s failed: %v (after %d repair attempts)", stage, outputErr, attempts)
+		t.state.FailureReason = &reason
+		t.deps.Logger.Warn(reason, ", task_id=", t.taskID)
+		return t.repo.SetTaskStatus(t.taskID, api.FailedByError)
+	}
+
+	askLLMResult, err := result.AsTaskActionResultAskLLM()
+	if err != nil {
+		return err
+	}
+	attempts++
+	t.state.LlmOutputRepairAttempts = &attempts
+	t.deps.Logger.Info("repairing LLM output of stage ", stage, ", attempt ", attempts, ": ", outputErr)
+
+	repair := &llmOutputRepair{response: askLLMResult.ResponseMessage, err: outputErr}
+	switch stage {
+	case internals.DetectProductChanges:
+		return t.askLLMForProductChanges(repair)
+	case internals.GenerateSearchQueries:
+		return t.askLLMForSearchQueries(repair)
+	case internals.RephraseDocumentation:
+		return t.startLLMSearchAndRephrase(repair)
+	default:
+		return fmt.Errorf("unexpected LLM output at stage %s", stage)
+	}
+}
+
 func (t *gitHubAccountPRTask) fetchPRData() error {
 	parsedURL, err := url.Parse(t.state.PrUrl)
 	if err != nil {
@@ -306,34 +406,14 @@ func (t *gitHubAccountPRTask) fetchPRData() error {
 	return nil
 }
 
-func (t *gitHubAccountPRTask) askLLMForProductChanges() error {
-	prompt := fmt.Sprintf(`Проанализируй этот GitHub PR и определи продуктовые изменения.
-Сфокусируйся на продуктовых изменениях, а не на деталях реализации.
-Обозначь максимум 2 изменения. Если значимых продуктовых изменений
-не было, просто напиши NO_CHANGES и ничего больше не пиши.
-Не учитывай исправление опечаток или что-то схожее.
-Постарайся описать детали. Например, если изменилась цена или изменилось какое-то бизнес-правило, скажи это прямо.
-Если это важно для бизнеса, назови детали. К примеру, если изменилась цена на какой-то товар, скажи,
-на какой товар и какая цена изменилась
-
-Вот информация:
-
-Описание PR: %s
-
-PR Patch: %s`,
-		*t.state.PrDescription, *t.state.PrPatch)
-
-	messages := []internals.LLMMessage{
-		{
-			Role:    "user",
-			Content: prompt,
-		},
-	}
-
-	return t.createAskLLMTaskAction(messages)
+func (t *gitHubAccountPRTask) askLLMForProductChanges(repair *llmOutputRepair) error {
+	return t.createAskLLMTaskAction(prompts.GitHubPRProductChanges, map[string]string{
+		"pr_description": *t.state.PrDescription,
+		"pr_patch":       *t.state.PrPatch,
+	}, repair)
 }
 
-func (t *gitHubAccountPRTask) askLLMForSearchQueries() error {
+func (t *gitHubAccountPRTask) askLLMForSearchQueries(repair *llmOutputRepair) error {
 	productChanges := ""
 	if t.state.LlmDetectedProductChanges != nil {
 		for _, change := range *t.state.LlmDetectedProductChanges {
@@ -341,26 +421,9 @@ func (t *gitHubAccountPRTask) askLLMForSearchQueries() error {
 		}
 	}
 
-	prompt := fmt.Sprintf(`Я перечислю изменения в продукте. На основе их предложи поисковые запросы.
-У компании есть база знаний, по которой есть семантический поиск. Надо сформировать поисковые запросы, которые могут
-найти такие фрагменты базы знаний, которые теоретически надо изменить при внесении изменений в продукт.
-Сформулируй как можно меньше запросов. Максимум - 3 запроса.
-
-Запросы должны искать ту сущность, которая изменилась. Например, если изменилась цена
-на какой-то товар, надо искать что-то типа "стоимость товара".
-
-Вот продуктовые изменения:
-%s
-`, productChanges)
-
-	messages := []internals.LLMMessage{
-		{
-			Role:    string(ycloud_client_gen.User),
-			Content: prompt,
-		},
-	}
-
-	return t.createAskLLMTaskAction(messages)
+	return t.createAskLLMTaskAction(prompts.GitHubPRSearchQueries, map[string]string{
+		"product_changes": productChanges,
+	}, repair)
 }
 
 func (t *gitHubAccountPRTask) searchHotParagraphs() error {
@@ -392,39 +455,26 @@ func (t *gitHubAccountPRTask) searchHotParagraphs() error {
 	t.state.HotParagraphs = &hp
 	temp := make([]string, 0)
 	t.state.LlmRephrasedParagraphContents = &temp
+	rationales := make([]string, 0)
+	t.state.LlmRephraseRationales = &rationales
 	return nil
 }
 
-func (t *gitHubAccountPRTask) startLLMSearchAndRephrase() error {
+func (t *gitHubAccountPRTask) startLLMSearchAndRephrase(repair *llmOutputRepair) error {
 	if len(*t.state.HotParagraphs) == 0 {
 		return fmt.Errorf("empty hot paragraphs")
 	}
 	currentParagraphContent := (*t.state.HotParagraphs)[len(*t.state.LlmRephrasedParagraphContents)].Content
 
-	prompt := fmt.Sprintf(`Измени это с учётом изменений в коде, чтобы текст соответствовал действительности.
-Я тебе напишу какую-то информацию из корпоративной wiki. Твой ответ должен включать только отредактированную информацию.
-Постарайся внести только те изменения, которые действительно произошли.
-Строго нельзя вносить что-то от себя. Если ты не уверен в том, что вносишь, лучше не вноси вообще никаких изменений
-
-Вот информация:
-Описание PR: %s
-PR Patch: %s`,
-		*t.state.PrDescription, *t.state.PrPatch)
-
-	messages := []internals.LLMMessage{
-		{
-			Role:    string(ycloud_client_gen.System),
-			Content: prompt,
-		},
-		{
-			Role:    "user",
-			Content: currentParagraphContent,
-		},
-	}
-
-	return t.createAskLLMTaskAction(messages)
+	return t.createAskLLMTaskAction(prompts.GitHubPRRephraseParagraph, map[string]string{
+		"pr_description": *t.state.PrDescription,
+		"pr_patch":       *t.state.PrPatch,
+		"paragraph":      currentParagraphContent,
+	}, repair)
 }
 
+// createDraftsWithRephrasedParagraphs replaces hot paragraphs with their
+// rephrased versions by line ranges and creates one draft per page.
 func (t *gitHubAccountPRTask) createDraftsWithRephrasedParagraphs() error {
 	if t.state.HotParagraphs == nil || t.state.LlmRephrasedParagraphContents == nil {
 		return nil
@@ -433,26 +483,76 @@ func (t *gitHubAccountPRTask) createDraftsWithRephrasedParagraphs() error {
 	hotParagraphs := *t.state.HotParagraphs
 	rephrasedParagraphs := *t.state.LlmRephrasedParagraphContents
 
-	createdDraftIDs := make([]api.DraftID, 0)
+	type pageRewrites struct {
+		page     *api.Page
+		chunks   []internals.ParagraphWithEmbedding
+		rewrites []paragraphRewrite
+	}
+	pages := make(map[api.PageID]*pageRewrites)
+	pageIDs := make([]api.PageID, 0)
 
-	for i, paragraph := range hotParagraphs {
+	for i, hot := range hotParagraphs {
 		if i >= len(rephrasedParagraphs) {
 			break
 		}
+		rephrased := strings.TrimSpace(rephrasedParagraphs[i])
+		if rephrased == hot.Content {
+			continue
+		}
 
-		page, _, err := t.repo.GetPageByID(paragraph.PageId)
-		if err != nil {
-			t.deps.Logger.Warn("failed to get page by ID: %v", err)
+		rewrites, ok := pages[hot.PageId]
+		if !ok {
+			page, _, err := t.repo.GetPageByID(hot.PageId)
+			if err != nil {
+				t.deps.Logger.Warn("failed to get page by ID: %v", err)
+				continue
+			}
+			rewrites = &pageRewrites{
+				page: page,
+				// Hot paragraphs consist of indexed chunks, so the page is split
+				// the way indexate_page splits it.
+				chunks: indexing.SplitPageToChunks(page.PageId, page.Content, indexing.ChunkingPolicy{
+					MaxTokens:     t.deps.Config.ChunkMaxTokens,
+					MinTokens:     t.deps.Config.ChunkMinTokens,
+					OverlapTokens: t.deps.Config.ChunkOverlapTokens,
+				}),
+			}
+			pages[hot.PageId] = rewrites
+			pageIDs = append(pageIDs, hot.PageId)
+		}
+
+		chunks, ok := locateHotParagraph(rewrites.chunks, hot)
+		if !ok {
+			t.deps.Logger.Warn("hot paragraph not found in page, page changed since indexing: page_id=", hot.PageId, ", line=", hot.StartLineNumber)
+			continue
+		}
+		hotRewrites := rewriteHotParagraph(rewrites.page.Content, chunks, rephrased)
+		overlapping := slices.ContainsFunc(hotRewrites, func(rewrite paragraphRewrite) bool {
+			return slices.ContainsFunc(rewrites.rewrites, rewrite.overlaps)
+		})
+		if overlapping {
+			t.deps.Logger.Warn("hot paragraph overlaps already rephrased one: page_id=", hot.PageId, ", line=", hot.StartLineNumber)
 			continue
 		}
+		rewrites.rewrites = append(rewrites.rewrites, hotRewrites...)
+	}
 
-		newContent := strings.Replace(page.Content, paragraph.Content, rephrasedParagraphs[i], 1000)
+	createdDraftIDs := make([]api.DraftID, 0)
 
-		if newContent == paragraph.Content {
+	for _, pageID := range pageIDs {
+		rewrites := pages[pageID]
+		if len(rewrites.rewrites) == 0 {
 			continue
 		}
 
-		draftID, err := t.repo.CreateDraft(paragraph.PageId, page.Title, newContent)
+		paragraphIndexes := make([]int, 0)
+		for _, rewrite := range rewrites.rewrites {
+			paragraphIndexes = append(paragraphIndexes, rewrite.paragraphIndexes...)
+		}
+		slices.Sort(paragraphIndexes)
+
+		newContent := patchPage(rewrites.page.Content, rewrites.rewrites)
+		draftID, err := t.repo.CreateDraft(pageID, rewrites.page.Title, newContent, &t.taskID, paragraphIndexes)
 		if err != nil {
 			t.deps.Logger.Warn("failed to create draft: %v", err)
 			continue
@@ -471,13 +571,34 @@ func (t *gitHubAccountPRTask) createDraftsWithRephrasedParagraphs() error {
 	return nil
 }
 
-func (t *gitHubAccountPRTask) createAskLLMTaskAction(prompt []internals.LLMMessage) error {
+// createAskLLMTaskAction enqueues LLM request rendered from the active version
+// of the prompt template. When repair is set, the request continues with the
+// malformed response and the repair prompt.
+func (t *gitHubAccountPRTask) createAskLLMTaskAction(templateName string, variables map[string]string, repair *llmOutputRepair) error {
+	template, err := prompts.Active(t.repo, templateName)
+	if err != nil {
+		return err
+	}
+	askLLMAction, err := template.AskLLMAction(variables)
+	if err != nil {
+		return err
+	}
+	if repair != nil {
+		repairTemplate, err := prompts.Active(t.repo, prompts.LLMOutputRepair)
+		if err != nil {
+			return err
+		}
+		_, repairPrompt, err := repairTemplate.Render(map[string]string{"error": repair.err.Error()})
+		if err != nil {
+			return fmt.Errorf("failed to render prompt template %s: %w", repairTemplate.Name, err)
+		}
+		askLLMAction.Messages = append(askLLMAction.Messages,
+			internals.LLMMessage{Role: llm_client.RoleAssistant, Content: repair.response},
+			internals.LLMMessage{Role: llm_client.RoleUser, Content: repairPrompt},
+		)
+	}
 	taskAction := internals.TaskAction{}
-	taskAction.FromTaskActionAskLLM(internals.TaskActionAskLLM{
-		TaskActionType: internals.AskLlm,
-		Model:          internals.Yandexgpt5Lite,
-		Messages:       prompt,
-	})
+	taskAction.FromTaskActionAskLLM(askLLMAction)
 
 	taskActionID, err := t.repo.CreateTaskAction(t.taskID, taskAction)
 	if err != nil {
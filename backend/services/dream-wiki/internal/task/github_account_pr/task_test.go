@@ -0,0 +1,473 @@
+package github_account_pr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/github_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/inference_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/indexing"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/prompts"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_actions_usecase"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/github_client_gen"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+type fakeGitHubClient struct {
+	github_client.GitHubClient
+
+	patchURL string
+}
+
+func (c *fakeGitHubClient) GetPullRequest(context.Context, string, string, int) (*github_client_gen.PullRequestResponse, error) {
+	return &github_client_gen.PullRequestResponse{
+		Body:     "Поднимаем цену доставки",
+		PatchUrl: c.patchURL,
+	}, nil
+}
+
+// fakeInferenceClient embeds every text to the same vector, so search finds
+// every indexed paragraph.
+type fakeInferenceClient struct {
+	inference_client.InferenceClient
+}
+
+func (fakeInferenceClient) GenerateEmbedding(context.Context, string) (internals.Embedding, error) {
+	return internals.Embedding{1, 0}, nil
+}
+
+// prTestEnv runs task actions synchronously in place of topic readers and
+// LLMOperationPoller.
+type prTestEnv struct {
+	deps    *deps.Deps
+	handled map[internals.TaskActionID]bool
+}
+
+func newPRTestEnv(t *testing.T, llmClient llm_client.LLMClient) *prTestEnv {
+	t.Helper()
+
+	patchServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("-const deliveryPrice = 100\n+const deliveryPrice = 200\n"))
+	}))
+	t.Cleanup(patchServer.Close)
+
+	log := logger.InitTestLogger()
+	return &prTestEnv{
+		deps: &deps.Deps{
+			Config:          &config.Config{ChunkMaxTokens: 256},
+			Storage:         repository.NewMemoryStorage(log, nil, nil),
+			Logger:          log,
+			InferenceClient: fakeInferenceClient{},
+			GitHubClient:    &fakeGitHubClient{patchURL: patchServer.URL},
+			LLMClient:       llmClient,
+		},
+		handled: make(map[internals.TaskActionID]bool),
+	}
+}
+
+func (e *prTestEnv) newRepository(mode db_adapter.TransactionMode) repository.AppRepository {
+	return e.deps.Storage.NewRepository(context.Background(), mode)
+}
+
+// createIndexedPage indexes only chunks of the page with relevant indexes, or
+// all chunks if none given.
+func (e *prTestEnv) createIndexedPage(t *testing.T, title string, content string, relevant ...int) api.PageID {
+	t.Helper()
+	repo := e.newRepository(db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	pageID, err := repo.CreatePage(title, title, content)
+	require.NoError(t, err)
+	policy := indexing.ChunkingPolicy{
+		MaxTokens:     e.deps.Config.ChunkMaxTokens,
+		MinTokens:     e.deps.Config.ChunkMinTokens,
+		OverlapTokens: e.deps.Config.ChunkOverlapTokens,
+	}
+	for _, paragraph := range indexing.SplitPageToChunks(*pageID, content, policy) {
+		if len(relevant) > 0 && !slices.Contains(relevant, paragraph.ParagraphIndex) {
+			continue
+		}
+		paragraph.Embedding = internals.Embedding{1, 0}
+		paragraph.TermsCount = 1
+		require.NoError(t, repo.AddIndexedParagraph(paragraph))
+	}
+	require.NoError(t, repo.Commit())
+	return *pageID
+}
+
+func (e *prTestEnv) createTask(t *testing.T) api.TaskID {
+	t.Helper()
+	repo := e.newRepository(db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	var state internals.TaskState
+	require.NoError(t, state.FromTaskStateGitHubAccountPR(internals.TaskStateGitHubAccountPR{
+		PrUrl:    "https://github.com/owner/repo/pull/1",
+		TaskType: internals.GithubAccountPr,
+	}))
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+
+	var action internals.TaskAction
+	require.NoError(t, action.FromTaskActionNewTask(internals.TaskActionNewTask{TaskActionType: internals.NewTask}))
+	_, err = repo.CreateTaskAction(*taskID, action)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+	return *taskID
+}
+
+func (e *prTestEnv) actionIDs(t *testing.T, taskID api.TaskID, status internals.TaskActionStatus) []internals.TaskActionID {
+	t.Helper()
+	repo := e.newRepository(db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	actionIDs, err := repo.GetTaskActionIDsByStatus(taskID, []internals.TaskActionStatus{status})
+	require.NoError(t, err)
+	return actionIDs
+}
+
+func (e *prTestEnv) taskDigest(t *testing.T, taskID api.TaskID) (*api.TaskDigest, internals.TaskStateGitHubAccountPR) {
+	t.Helper()
+	repo := e.newRepository(db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	digest, state, err := repo.GetTaskByID(taskID)
+	require.NoError(t, err)
+	prState, err := state.AsTaskStateGitHubAccountPR()
+	require.NoError(t, err)
+	return digest, prState
+}
+
+func (e *prTestEnv) onActionResult(t *testing.T, actionID internals.TaskActionID) {
+	t.Helper()
+	repo := e.newRepository(db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	_, info, err := repo.GetTaskActionByID(actionID)
+	require.NoError(t, err)
+	digest, state, err := repo.GetTaskByID(info.TaskId)
+	require.NoError(t, err)
+	prState, err := state.AsTaskStateGitHubAccountPR()
+	require.NoError(t, err)
+	result, _, err := repo.GetTaskActionResultByID(actionID)
+	require.NoError(t, err)
+
+	task := NewGitHubAccountPRTask(context.Background(), prState, &task_common.TaskDeps{
+		Deps:   e.deps,
+		Digest: *digest,
+		State:  state,
+		Repo:   repo,
+	})
+	require.NoError(t, task.OnActionResult(*result))
+}
+
+// run executes actions of the task and accounts their results until the task
+// reaches terminal status.
+func (e *prTestEnv) run(t *testing.T, taskID api.TaskID) {
+	t.Helper()
+	taskActionUsecase := task_actions_usecase.NewTaskActionUsecase(context.Background(), e.deps)
+
+	for range 20 {
+		digest, _ := e.taskDigest(t, taskID)
+		if task_common.IsTerminalTaskStatus(digest.Status) {
+			return
+		}
+
+		for _, actionID := range e.actionIDs(t, taskID, internals.New) {
+			require.NoError(t, taskActionUsecase.ExecuteAction(actionID))
+		}
+
+		repo := e.newRepository(db_adapter.SnapshotReadOnly)
+		operations, err := repo.GetPendingLLMOperations()
+		repo.Rollback()
+		require.NoError(t, err)
+		for _, operation := range operations {
+			require.NoError(t, taskActionUsecase.PollLLMOperation(operation))
+		}
+
+		finished := e.actionIDs(t, taskID, internals.Finished)
+		slices.Sort(finished)
+		for _, actionID := range finished {
+			if e.handled[actionID] {
+				continue
+			}
+			e.handled[actionID] = true
+			e.onActionResult(t, actionID)
+		}
+	}
+	t.Fatal("task did not finish")
+}
+
+func TestGitHubAccountPROffline(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "определи продуктовые изменения", Response: `{"changes": ["Стоимость доставки выросла со 100 до 200 рублей"]}`},
+		{Contains: "предложи поисковые запросы", Response: "```json\n{\"queries\": [\" стоимость доставки \"]}\n```"},
+		{Contains: "Доставка стоит 100 рублей.", Response: `{"paragraph": "Доставка стоит 200 рублей.", "rationale": "Цена доставки изменилась в PR"}`},
+	})
+	env := newPRTestEnv(t, llmClient)
+	pageID := env.createIndexedPage(t, "Доставка", "Доставка стоит 100 рублей.")
+	taskID := env.createTask(t)
+
+	env.run(t, taskID)
+
+	digest, state := env.taskDigest(t, taskID)
+	require.Equal(t, api.Done, digest.Status)
+	require.Equal(t, []string{"Стоимость доставки выросла со 100 до 200 рублей"}, *state.LlmDetectedProductChanges)
+	require.Equal(t, []string{"стоимость доставки"}, *state.LlmSuggestedSearchQueries)
+	require.Equal(t, []string{"Цена доставки изменилась в PR"}, *state.LlmRephraseRationales)
+	require.Len(t, *state.CreatedDraftIds, 1)
+
+	requests := llmClient.Requests()
+	require.Len(t, requests, 3)
+	require.Contains(t, requests[0].Messages[0].Content, "+const deliveryPrice = 200")
+	require.Contains(t, requests[2].SystemPrompt, "Поднимаем цену доставки")
+	searchQueriesTemplate, _ := prompts.Builtin(prompts.GitHubPRSearchQueries)
+	require.Equal(t, searchQueriesTemplate.MaxTokens, requests[1].MaxTokens)
+
+	repo := env.newRepository(db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	draft, _, err := repo.GetDraftByID((*state.CreatedDraftIds)[0])
+	require.NoError(t, err)
+	require.Equal(t, pageID, draft.DraftDigest.PageDigest.PageId)
+	require.Equal(t, "Доставка стоит 200 рублей.", draft.Content)
+	require.Equal(t, &[]int{0}, draft.SourceParagraphIndexes)
+}
+
+func TestGitHubAccountPRRewritesChunkOfOversizedParagraph(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "определи продуктовые изменения", Response: `{"changes": ["Курьер звонит за два часа"]}`},
+		{Contains: "предложи поисковые запросы", Response: `{"queries": ["звонок курьера"]}`},
+		{Contains: "Курьер звонит за час.", Response: `{"paragraph": "Курьер звонит за два часа.", "rationale": "Время звонка"}`},
+	})
+	env := newPRTestEnv(t, llmClient)
+	// Paragraph of four sentences is indexed as four chunks, only the second
+	// one is relevant.
+	env.deps.Config.ChunkMaxTokens = 8
+	pageID := env.createIndexedPage(t, "Доставка", oversizedParagraphTestPage, 2)
+	taskID := env.createTask(t)
+
+	env.run(t, taskID)
+
+	digest, state := env.taskDigest(t, taskID)
+	require.Equal(t, api.Done, digest.Status)
+	require.Len(t, *state.CreatedDraftIds, 1)
+
+	repo := env.newRepository(db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	draft, _, err := repo.GetDraftByID((*state.CreatedDraftIds)[0])
+	require.NoError(t, err)
+	require.Equal(t, pageID, draft.DraftDigest.PageDigest.PageId)
+	require.Equal(t, &[]int{2}, draft.SourceParagraphIndexes)
+	require.Equal(t, `# Доставка
+
+Доставка стоит 100 рублей. Курьер звонит за два часа. Оплата картой курьеру. Самовывоз бесплатный.
+
+Заказ привозят в течение дня.`, draft.Content)
+}
+
+func TestGitHubAccountPRGroupsRewritesPerPage(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "определи продуктовые изменения", Response: `{"changes": ["Стоимость доставки выросла со 100 до 200 рублей"]}`},
+		{Contains: "предложи поисковые запросы", Response: `{"queries": ["стоимость доставки"]}`},
+		{
+			Contains: "Доставка по городу стоит 100 рублей.",
+			Response: `{"paragraph": "Доставка по городу стоит 200 рублей.", "rationale": "Цена"}`,
+		},
+		{
+			Contains: "Доставка за город стоит 100 рублей",
+			Response: `{"paragraph": "Доставка за город стоит 200 рублей\nплюс 20 рублей за километр.", "rationale": "Цена"}`,
+		},
+	})
+	env := newPRTestEnv(t, llmClient)
+	content := `# Доставка
+
+Доставка по городу стоит 100 рублей.
+
+Заказ привозят в течение дня.
+
+Курьер звонит за час.
+
+Самовывоз бесплатный.
+
+---
+
+Доставка за город стоит 100 рублей
+плюс 20 рублей за километр.
+
+Оплата картой курьеру.`
+	pageID := env.createIndexedPage(t, "Доставка", content, 1, 5)
+	taskID := env.createTask(t)
+
+	env.run(t, taskID)
+
+	digest, state := env.taskDigest(t, taskID)
+	require.Equal(t, api.Done, digest.Status)
+	require.Len(t, *state.HotParagraphs, 2)
+	require.Len(t, *state.CreatedDraftIds, 1)
+
+	repo := env.newRepository(db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	draft, _, err := repo.GetDraftByID((*state.CreatedDraftIds)[0])
+	require.NoError(t, err)
+	require.Equal(t, pageID, draft.DraftDigest.PageDigest.PageId)
+	require.Equal(t, &[]int{1, 5}, draft.SourceParagraphIndexes)
+	require.Equal(t, `# Доставка
+
+Доставка по городу стоит 200 рублей.
+
+Заказ привозят в течение дня.
+
+Курьер звонит за час.
+
+Самовывоз бесплатный.
+
+---
+
+Доставка за город стоит 200 рублей
+плюс 20 рублей за километр.
+
+Оплата картой курьеру.`, draft.Content)
+}
+
+func TestGitHubAccountPRUsesOverriddenPrompt(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "Изменения PR", Response: `{"changes": ["Стоимость доставки выросла"]}`},
+		{Contains: "предложи поисковые запросы", Response: `{"queries": ["стоимость доставки"]}`},
+		{Contains: "Доставка стоит 100 рублей.", Response: `{"paragraph": "Доставка стоит 200 рублей.", "rationale": ""}`},
+	})
+	env := newPRTestEnv(t, llmClient)
+	env.createIndexedPage(t, "Доставка", "Доставка стоит 100 рублей.")
+
+	productChangesTemplate, _ := prompts.Builtin(prompts.GitHubPRProductChanges)
+	repo := env.newRepository(db_adapter.SerializableReadWrite)
+	_, err := repo.CreatePromptTemplateOverride(internals.PromptTemplateOverride{
+		Name:        prompts.GitHubPRProductChanges,
+		Version:     productChangesTemplate.Version + 1,
+		UserPrompt:  "Изменения PR: {{.pr_description}}",
+		Temperature: 0.5,
+		MaxTokens:   300,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	taskID := env.createTask(t)
+	env.run(t, taskID)
+
+	digest, _ := env.taskDigest(t, taskID)
+	require.Equal(t, api.Done, digest.Status)
+	requests := llmClient.Requests()
+	require.Equal(t, "Изменения PR: Поднимаем цену доставки", requests[0].Messages[0].Content)
+	require.Equal(t, 0.5, requests[0].Temperature)
+	require.Equal(t, 300, requests[0].MaxTokens)
+
+	repo = env.newRepository(db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	actionIDs := env.actionIDs(t, taskID, internals.Finished)
+	slices.Sort(actionIDs)
+	templateRefs := make([]internals.PromptTemplateRef, 0)
+	for _, actionID := range actionIDs {
+		action, _, err := repo.GetTaskActionByID(actionID)
+		require.NoError(t, err)
+		askLLMAction, err := action.AsTaskActionAskLLM()
+		if err != nil || askLLMAction.PromptTemplate == nil {
+			continue
+		}
+		templateRefs = append(templateRefs, *askLLMAction.PromptTemplate)
+	}
+	searchQueriesTemplate, _ := prompts.Builtin(prompts.GitHubPRSearchQueries)
+	rephraseTemplate, _ := prompts.Builtin(prompts.GitHubPRRephraseParagraph)
+	require.Equal(t, []internals.PromptTemplateRef{
+		{Name: prompts.GitHubPRProductChanges, Version: productChangesTemplate.Version + 1, Overridden: true},
+		{Name: prompts.GitHubPRSearchQueries, Version: searchQueriesTemplate.Version},
+		{Name: prompts.GitHubPRRephraseParagraph, Version: rephraseTemplate.Version},
+	}, templateRefs)
+}
+
+func TestGitHubAccountPRRepairsMalformedOutput(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "Не удалось разобрать", Response: `{"changes": ["Стоимость доставки выросла"]}`},
+		{Contains: "определи продуктовые изменения", Response: "- Стоимость доставки выросла"},
+		{Contains: "предложи поисковые запросы", Response: `{"queries": ["стоимость доставки"]}`},
+		{Contains: "Доставка стоит 100 рублей.", Response: `{"paragraph": "Доставка стоит 200 рублей.", "rationale": ""}`},
+	})
+	env := newPRTestEnv(t, llmClient)
+	env.createIndexedPage(t, "Доставка", "Доставка стоит 100 рублей.")
+	taskID := env.createTask(t)
+
+	env.run(t, taskID)
+
+	digest, state := env.taskDigest(t, taskID)
+	require.Equal(t, api.Done, digest.Status)
+	require.Equal(t, []string{"Стоимость доставки выросла"}, *state.LlmDetectedProductChanges)
+	require.Nil(t, state.LlmOutputRepairAttempts)
+
+	requests := llmClient.Requests()
+	require.Len(t, requests, 4)
+	repairMessages := requests[1].Messages
+	require.Len(t, repairMessages, 3)
+	require.Equal(t, requests[0].Messages[0], repairMessages[0])
+	require.Equal(t, internals.LLMMessage{Role: llm_client.RoleAssistant, Content: "- Стоимость доставки выросла"}, repairMessages[1])
+	require.Contains(t, repairMessages[2].Content, "malformed LLM output")
+}
+
+func TestGitHubAccountPRFailsOnMalformedOutput(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "предложи поисковые запросы", Response: "1. стоимость доставки"},
+		{Contains: "определи продуктовые изменения", Response: `{"changes": ["Стоимость доставки выросла"]}`},
+	})
+	env := newPRTestEnv(t, llmClient)
+	taskID := env.createTask(t)
+
+	env.run(t, taskID)
+
+	digest, state := env.taskDigest(t, taskID)
+	require.Equal(t, api.FailedByError, digest.Status)
+	require.Nil(t, state.LlmSuggestedSearchQueries)
+	require.Contains(t, *state.FailureReason, "generate_search_queries")
+	require.Len(t, llmClient.Requests(), 2+maxLLMOutputRepairAttempts)
+
+	task := NewGitHubAccountPRTask(context.Background(), state, &task_common.TaskDeps{Digest: *digest})
+	subtasks, err := task.CalculateSubtasks()
+	require.NoError(t, err)
+	require.Equal(t, api.FailedByError, subtasks[2].Status)
+	require.Equal(t, []api.SubSubtask{{Description: *state.FailureReason, Status: api.FailedByError}}, subtasks[2].Subsubtasks)
+}
+
+func TestGitHubAccountPRWithoutProductChanges(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "определи продуктовые изменения", Response: `{"changes": []}`},
+	})
+	env := newPRTestEnv(t, llmClient)
+	taskID := env.createTask(t)
+
+	env.run(t, taskID)
+
+	digest, state := env.taskDigest(t, taskID)
+	require.Equal(t, api.Done, digest.Status)
+	require.Empty(t, *state.CreatedDraftIds)
+	require.Len(t, llmClient.Requests(), 1)
+}
@@ -2,6 +2,7 @@ package reindexate_pages
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
@@ -11,6 +12,9 @@ import (
 )
 
 type (
+	// reindexatePagesTask indexates pages keeping up to
+	// Config.ReindexationConcurrency indexate_page actions in flight. Pages that
+	// failed to indexate are recorded in state and skipped.
 	reindexatePagesTask struct {
 		taskID api.TaskID
 		status api.TaskStatus
@@ -38,16 +42,24 @@ func NewReindexatePagesTask(ctx context.Context, state internals.TaskStateReinde
 
 func (t *reindexatePagesTask) updateState() error {
 	taskState := internals.TaskState{}
-	taskState.FromTaskStateReindexatePages(t.state)
+	err := taskState.FromTaskStateReindexatePages(t.state)
+	if err != nil {
+		return err
+	}
 	return t.repo.SetTaskState(t.taskID, taskState)
 }
 
 func (t *reindexatePagesTask) createIndexatePageTaskAction(pageID api.PageID) error {
+	skipOnFailure := true
 	taskAction := internals.TaskAction{}
-	taskAction.FromTaskActionIndexatePage(internals.TaskActionIndexatePage{
+	err := taskAction.FromTaskActionIndexatePage(internals.TaskActionIndexatePage{
 		TaskActionType: internals.IndexatePage,
 		PageId:         pageID,
+		SkipOnFailure:  &skipOnFailure,
 	})
+	if err != nil {
+		return err
+	}
 
 	taskActionID, err := t.repo.CreateTaskAction(t.taskID, taskAction)
 	if err != nil {
@@ -57,29 +69,51 @@ func (t *reindexatePagesTask) createIndexatePageTaskAction(pageID api.PageID) er
 	return t.repo.EnqueueTaskAction(*taskActionID)
 }
 
+func (t *reindexatePagesTask) processedPagesCount() int {
+	return len(t.state.IndexatedPageIds) + len(t.state.FailedPages)
+}
+
+// dispatchPages creates indexate_page actions for the following pages until
+// concurrency limit of actions is in flight.
+func (t *reindexatePagesTask) dispatchPages() error {
+	concurrency := max(t.deps.Config.ReindexationConcurrency, 1)
+	for t.state.DispatchedPagesCount < len(t.state.PagesToIndexateIds) && t.state.DispatchedPagesCount-t.processedPagesCount() < concurrency {
+		err := t.createIndexatePageTaskAction(t.state.PagesToIndexateIds[t.state.DispatchedPagesCount])
+		if err != nil {
+			return err
+		}
+		t.state.DispatchedPagesCount++
+	}
+	return nil
+}
+
 func (t *reindexatePagesTask) CalculateSubtasks() ([]api.Subtask, error) {
 	var subtasks []api.Subtask
 
+	pageStatuses := make(map[api.PageID]api.TaskStatus, len(t.state.PagesToIndexateIds))
 	for _, pageID := range t.state.IndexatedPageIds {
+		pageStatuses[pageID] = api.Done
+	}
+	for _, failure := range t.state.FailedPages {
+		pageStatuses[failure.PageId] = api.FailedByError
+	}
+
+	for _, pageID := range t.state.PagesToIndexateIds {
+		status, ok := pageStatuses[pageID]
+		if !ok {
+			status = api.Executing
+			if task_common.IsTerminalTaskStatus(t.status) {
+				status = api.Cancelled
+			}
+		}
 		pageTitle := t.state.PageTitles[pageID.String()]
 		subtasks = append(subtasks, api.Subtask{
 			Description: "Indexating page " + pageTitle,
-			Status:      api.Done,
+			Status:      status,
 			Subsubtasks: []api.SubSubtask{},
 		})
 	}
 
-	if t.state.PagesToIndexateIds != nil {
-		for _, pageID := range t.state.PagesToIndexateIds[len(t.state.IndexatedPageIds):] {
-			pageTitle := t.state.PageTitles[pageID.String()]
-			subtasks = append(subtasks, api.Subtask{
-				Description: "Indexating page " + pageTitle,
-				Status:      api.Executing,
-				Subsubtasks: []api.SubSubtask{},
-			})
-		}
-	}
-
 	return subtasks, nil
 }
 
@@ -91,14 +125,7 @@ func (t *reindexatePagesTask) OnActionResult(result internals.TaskActionResult)
 
 	switch internals.TaskActionType(resultType) {
 	case internals.NewTask:
-		if len(t.state.PagesToIndexateIds) > 0 {
-			firstPageID := t.state.PagesToIndexateIds[0]
-			err := t.createIndexatePageTaskAction(firstPageID)
-			if err != nil {
-				return err
-			}
-		}
-		return t.repo.Commit()
+		// The first pages are dispatched below.
 
 	case internals.IndexatePage:
 		indexatePageResult, err := result.AsTaskActionResultIndexatePage()
@@ -106,29 +133,35 @@ func (t *reindexatePagesTask) OnActionResult(result internals.TaskActionResult)
 			return err
 		}
 
-		pageID := indexatePageResult.PageId
-		t.state.IndexatedPageIds = append(t.state.IndexatedPageIds, pageID)
+		if indexatePageResult.Error != nil {
+			t.state.FailedPages = append(t.state.FailedPages, internals.ReindexationFailure{
+				PageId: indexatePageResult.PageId,
+				Error:  *indexatePageResult.Error,
+			})
+		} else {
+			t.state.IndexatedPageIds = append(t.state.IndexatedPageIds, indexatePageResult.PageId)
+		}
+
+	default:
+		return fmt.Errorf("unexpected task action result type: %s", resultType)
+	}
+
+	err = t.dispatchPages()
+	if err != nil {
+		return err
+	}
 
-		err = t.updateState()
+	err = t.updateState()
+	if err != nil {
+		return err
+	}
+
+	if t.processedPagesCount() >= len(t.state.PagesToIndexateIds) {
+		err = t.repo.SetTaskStatus(t.taskID, api.Done)
 		if err != nil {
 			return err
 		}
-
-		if len(t.state.PagesToIndexateIds) >= len(t.state.IndexatedPageIds) {
-			err := t.repo.SetTaskStatus(t.taskID, api.Done)
-			if err != nil {
-				return err
-			}
-		} else {
-			nextPageID := t.state.PagesToIndexateIds[len(t.state.IndexatedPageIds)]
-			err = t.createIndexatePageTaskAction(nextPageID)
-			if err != nil {
-				return err
-			}
-		}
-
-		return t.repo.Commit()
 	}
 
-	return nil
+	return t.repo.Commit()
 }
@@ -0,0 +1,197 @@
+package reindexate_pages
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// reindexationTestEnv keeps the task in memory storage and accounts action
+// results in place of results topic reader.
+type reindexationTestEnv struct {
+	storage repository.Storage
+	deps    *deps.Deps
+	taskID  api.TaskID
+}
+
+func newReindexationTestEnv(t *testing.T, pageIDs []api.PageID) *reindexationTestEnv {
+	t.Helper()
+	log := logger.InitTestLogger()
+	storage := repository.NewMemoryStorage(log, nil, nil)
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	var state internals.TaskState
+	require.NoError(t, state.FromTaskStateReindexatePages(internals.TaskStateReindexatePages{
+		TaskType:           internals.ReindexatePages,
+		PagesToIndexateIds: pageIDs,
+		IndexatedPageIds:   []api.PageID{},
+		FailedPages:        []internals.ReindexationFailure{},
+		PageTitles:         map[string]string{},
+	}))
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	return &reindexationTestEnv{
+		storage: storage,
+		deps: &deps.Deps{
+			Logger: log,
+			Config: &config.Config{ReindexationConcurrency: 2},
+		},
+		taskID: *taskID,
+	}
+}
+
+// onResult restores task from the stored state, as results topic reader does.
+func (e *reindexationTestEnv) onResult(t *testing.T, result internals.TaskActionResult) {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	digest, state, err := repo.GetTaskByID(e.taskID)
+	require.NoError(t, err)
+	reindexationState, err := state.AsTaskStateReindexatePages()
+	require.NoError(t, err)
+	task := NewReindexatePagesTask(context.Background(), reindexationState, &task_common.TaskDeps{
+		Deps:   e.deps,
+		Digest: *digest,
+		State:  state,
+		Repo:   repo,
+	})
+	require.NoError(t, task.OnActionResult(result))
+}
+
+func (e *reindexationTestEnv) task(t *testing.T) (api.TaskStatus, internals.TaskStateReindexatePages) {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	digest, state, err := repo.GetTaskByID(e.taskID)
+	require.NoError(t, err)
+	reindexationState, err := state.AsTaskStateReindexatePages()
+	require.NoError(t, err)
+	return digest.Status, reindexationState
+}
+
+func (e *reindexationTestEnv) status(t *testing.T) api.TaskStatus {
+	t.Helper()
+	status, _ := e.task(t)
+	return status
+}
+
+// actions returns actions created by the task in creation order.
+func (e *reindexationTestEnv) actions(t *testing.T) []internals.TaskActionIndexatePage {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	actionIDs, err := repo.GetTaskActionIDsByStatus(e.taskID, []internals.TaskActionStatus{internals.New})
+	require.NoError(t, err)
+	actions := make([]internals.TaskActionIndexatePage, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		action, _, err := repo.GetTaskActionByID(actionID)
+		require.NoError(t, err)
+		indexatePageAction, err := action.AsTaskActionIndexatePage()
+		require.NoError(t, err)
+		actions = append(actions, indexatePageAction)
+	}
+	return actions
+}
+
+func newTaskResult(t *testing.T) internals.TaskActionResult {
+	t.Helper()
+	result := internals.TaskActionResult{}
+	require.NoError(t, result.FromTaskActionResultNewTask(internals.TaskActionResultNewTask{TaskActionType: internals.NewTask}))
+	return result
+}
+
+func indexateResult(t *testing.T, pageID api.PageID, errorMessage *string) internals.TaskActionResult {
+	t.Helper()
+	result := internals.TaskActionResult{}
+	require.NoError(t, result.FromTaskActionResultIndexatePage(internals.TaskActionResultIndexatePage{
+		TaskActionType: internals.IndexatePage,
+		PageId:         pageID,
+		Error:          errorMessage,
+	}))
+	return result
+}
+
+func TestReindexatePagesKeepsWindowOfActions(t *testing.T) {
+	t.Parallel()
+
+	pageIDs := []api.PageID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+	env := newReindexationTestEnv(t, pageIDs)
+
+	env.onResult(t, newTaskResult(t))
+	actions := env.actions(t)
+	require.Len(t, actions, 2)
+	require.Equal(t, pageIDs[0], actions[0].PageId)
+	require.Equal(t, pageIDs[1], actions[1].PageId)
+	require.True(t, *actions[0].SkipOnFailure)
+
+	// Results may come in any order, each of them frees a slot.
+	env.onResult(t, indexateResult(t, pageIDs[1], nil))
+	actions = env.actions(t)
+	require.Len(t, actions, 3)
+	require.Equal(t, pageIDs[2], actions[2].PageId)
+
+	env.onResult(t, indexateResult(t, pageIDs[0], nil))
+	actions = env.actions(t)
+	require.Len(t, actions, 4)
+	require.Equal(t, pageIDs[3], actions[3].PageId)
+
+	env.onResult(t, indexateResult(t, pageIDs[2], nil))
+	require.Equal(t, api.Executing, env.status(t))
+	env.onResult(t, indexateResult(t, pageIDs[3], nil))
+	require.Len(t, env.actions(t), 4)
+	status, state := env.task(t)
+	require.Equal(t, api.Done, status)
+	require.Equal(t, 4, state.DispatchedPagesCount)
+}
+
+func TestReindexatePagesSkipsFailedPage(t *testing.T) {
+	t.Parallel()
+
+	pageIDs := []api.PageID{uuid.New(), uuid.New()}
+	env := newReindexationTestEnv(t, pageIDs)
+
+	env.onResult(t, newTaskResult(t))
+	errorMessage := "inference service is unavailable"
+	env.onResult(t, indexateResult(t, pageIDs[0], &errorMessage))
+	require.Equal(t, api.Executing, env.status(t))
+	env.onResult(t, indexateResult(t, pageIDs[1], nil))
+
+	status, state := env.task(t)
+	require.Equal(t, api.Done, status)
+	require.Equal(t, []api.PageID{pageIDs[1]}, state.IndexatedPageIds)
+	require.Equal(t, []internals.ReindexationFailure{{PageId: pageIDs[0], Error: errorMessage}}, state.FailedPages)
+
+	task := NewReindexatePagesTask(context.Background(), state, &task_common.TaskDeps{
+		Deps:   env.deps,
+		Digest: api.TaskDigest{TaskId: env.taskID, Status: status},
+	})
+	subtasks, err := task.CalculateSubtasks()
+	require.NoError(t, err)
+	require.Equal(t, api.FailedByError, subtasks[0].Status)
+	require.Equal(t, api.Done, subtasks[1].Status)
+}
+
+func TestReindexatePagesWithoutPagesIsDone(t *testing.T) {
+	t.Parallel()
+
+	env := newReindexationTestEnv(t, []api.PageID{})
+	env.onResult(t, newTaskResult(t))
+	require.Empty(t, env.actions(t))
+	require.Equal(t, api.Done, env.status(t))
+}
@@ -1,88 +1,144 @@
 package task_actions_usecase
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
-	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ycloud_client_gen"
 )
 
+// llmOperationTimeout is how long submitted LLM request may stay not done
+// before the action is failed.
+const llmOperationTimeout = 3 * time.Minute
+
+// executeAskLLMAction only submits asynchronous LLM request. Action stays in
+// submitted status until LLMOperationPoller sees the operation done and calls
+// PollLLMOperation, so no transaction is held open while the model answers.
 func (u *taskActionUsecaseImpl) executeAskLLMAction(repo repository.AppRepository, actionID internals.TaskActionID, taskAction *internals.TaskAction) error {
 	askLLMAction, err := taskAction.AsTaskActionAskLLM()
 	if err != nil {
 		return fmt.Errorf("failed to parse task action as TaskActionAskLLM: %w", err)
 	}
 
-	var messages []ycloud_client_gen.Message
-	for _, msg := range askLLMAction.Messages {
-		messages = append(messages, ycloud_client_gen.Message{
-			Role: ycloud_client_gen.MessageRole(msg.Role),
-			Text: msg.Content,
-		})
+	operationID, err := u.deps.LLMClient.StartRequest(u.ctx, llmRequest(askLLMAction))
+	if err != nil {
+		return fmt.Errorf("failed to start async LLM request: %w", err)
 	}
 
-	operationID, err := u.deps.YCloudClient.StartAsyncLLMRequest(u.ctx, messages)
+	err = repo.SetTaskActionStatus(actionID, internals.Submitted)
 	if err != nil {
-		return fmt.Errorf("failed to start async LLM request: %w", err)
+		return fmt.Errorf("failed to set task action status to submitted: %w", err)
+	}
+
+	err = repo.SetPendingLLMOperation(actionID, operationID)
+	if err != nil {
+		return fmt.Errorf("failed to set pending LLM operation: %w", err)
 	}
 
-	timeout := time.After(3 * time.Minute)
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
-
-	var operation *ycloud_client_gen.Operation
-pollingLoop:
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout while waiting for LLM response")
-		case <-ticker.C:
-			operation, err = u.deps.YCloudClient.GetLLMResponse(u.ctx, *operationID)
-			u.log.Info(operation)
-			if err != nil {
-				return fmt.Errorf("failed to get LLM response: %w", err)
-			}
-			if operation == nil {
-				return fmt.Errorf("operation is nil")
-			}
-
-			if operation.Done {
-				break pollingLoop
-			}
+	u.log.Info("submitted LLM request", "action_id", actionID, "operation_id", operationID)
+
+	return nil
+}
+
+// PollLLMOperation checks submitted LLM request once. When the operation is
+// done, action result is stored and enqueued as if the action finished
+// synchronously. The action and its task are failed if the operation fails,
+// is lost by provider or is not done within llmOperationTimeout.
+func (u *taskActionUsecaseImpl) PollLLMOperation(pending internals.PendingLLMOperation) error {
+	operation, pollErr := u.deps.LLMClient.GetOperation(u.ctx, pending.OperationId)
+	lost := errors.Is(pollErr, llm_client.ErrOperationNotFound)
+	done := pollErr == nil && operation.Done
+	timedOut := time.Since(pending.SubmittedAt) > llmOperationTimeout
+	if !done && !lost && !timedOut {
+		if pollErr != nil {
+			return fmt.Errorf("failed to get LLM response: %w", pollErr)
 		}
+		return nil
 	}
 
-	err = repo.SetTaskActionStatus(actionID, internals.Finished)
+	repo := u.deps.Storage.NewRepository(u.ctx, db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	err := repo.RemovePendingLLMOperation(pending.TaskActionId, pending.OperationId)
+	if errors.Is(err, models.ErrNoRows) {
+		// Operation was handled concurrently or replaced by the operation of a
+		// retry, which is polled on its own.
+		u.log.Info("skipping LLM operation which is no longer pending",
+			"action_id", pending.TaskActionId,
+			"operation_id", pending.OperationId)
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to set task action status to finished: %w", err)
+		return fmt.Errorf("failed to remove pending LLM operation: %w", err)
 	}
 
-	responseBytes, err := json.Marshal(operation)
+	_, taskActionAdditionalInfo, err := repo.GetTaskActionByID(pending.TaskActionId)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get task action by ID: %w", err)
+	}
+
+	if taskActionAdditionalInfo.Status != internals.Submitted {
+		// Action was abandoned or retried while the operation was running.
+		u.log.Info("dropping LLM operation of not submitted action",
+			"action_id", pending.TaskActionId,
+			"status", taskActionAdditionalInfo.Status)
+		return repo.Commit()
+	}
+
+	switch {
+	case lost:
+		err = pollErr
+	case !done:
+		err = fmt.Errorf("timeout while waiting for LLM response")
+	case operation.Error != "":
+		err = fmt.Errorf("LLM request failed: %s", operation.Error)
+	default:
+		err = u.finishAskLLMAction(repo, pending.TaskActionId, operation)
 	}
-	rawResponse := make(map[string]any)
-	err = json.Unmarshal(responseBytes, &rawResponse)
 	if err != nil {
+		u.failTaskActionAndTask(repo, pending.TaskActionId, taskActionAdditionalInfo.TaskId)
 		return err
 	}
 
-	if operation.Response == nil {
-		return fmt.Errorf("response is nil")
+	return repo.Commit()
+}
+
+// llmRequest fills options not set in the action with defaults.
+func llmRequest(action internals.TaskActionAskLLM) llm_client.Request {
+	request := llm_client.Request{
+		Model:       action.Model,
+		Temperature: llm_client.DefaultTemperature,
+		MaxTokens:   llm_client.DefaultMaxTokens,
+		Messages:    action.Messages,
 	}
+	if action.SystemPrompt != nil {
+		request.SystemPrompt = *action.SystemPrompt
+	}
+	if action.Temperature != nil {
+		request.Temperature = *action.Temperature
+	}
+	if action.MaxTokens != nil {
+		request.MaxTokens = *action.MaxTokens
+	}
+	return request
+}
 
-	if len(operation.Response.Alternatives) == 0 {
-		return fmt.Errorf("no alternatives")
+func (u *taskActionUsecaseImpl) finishAskLLMAction(repo repository.AppRepository, actionID internals.TaskActionID, operation *llm_client.Operation) error {
+	err := repo.SetTaskActionStatus(actionID, internals.Finished)
+	if err != nil {
+		return fmt.Errorf("failed to set task action status to finished: %w", err)
 	}
 
 	result := internals.TaskActionResult{}
 	askLLMResult := internals.TaskActionResultAskLLM{
 		TaskActionType:  internals.AskLlm,
-		ResponseMessage: operation.Response.Alternatives[0].Message.Text,
-		ServerResponse:  rawResponse,
+		ResponseMessage: operation.Text,
+		ServerResponse:  operation.ServerResponse,
 	}
 	err = result.FromTaskActionResultAskLLM(askLLMResult)
 	if err != nil {
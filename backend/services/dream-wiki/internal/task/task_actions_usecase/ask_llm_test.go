@@ -0,0 +1,184 @@
+package task_actions_usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/llm_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// pendingLLMClient never finishes operations.
+type pendingLLMClient struct {
+	llm_client.LLMClient
+}
+
+func (pendingLLMClient) StartRequest(context.Context, llm_client.Request) (string, error) {
+	return "operation", nil
+}
+
+func (pendingLLMClient) GetOperation(context.Context, string) (*llm_client.Operation, error) {
+	return &llm_client.Operation{}, nil
+}
+
+func newAskLLMTestUsecase(t *testing.T, llmClient llm_client.LLMClient) (*taskActionUsecaseImpl, repository.Storage, api.TaskID, internals.TaskActionID) {
+	t.Helper()
+
+	log := logger.InitTestLogger()
+	storage := repository.NewMemoryStorage(log, nil, nil)
+	u := &taskActionUsecaseImpl{
+		ctx: context.Background(),
+		deps: &deps.Deps{
+			Storage:   storage,
+			Logger:    log,
+			LLMClient: llmClient,
+		},
+		log: log,
+	}
+
+	var state internals.TaskState
+	require.NoError(t, state.FromTaskStateAskQuestion(internals.TaskStateAskQuestion{
+		TaskType: internals.AskQuestion,
+	}))
+	var action internals.TaskAction
+	require.NoError(t, action.FromTaskActionAskLLM(internals.TaskActionAskLLM{
+		TaskActionType: internals.AskLlm,
+		Model:          internals.Yandexgpt5Lite,
+		Messages:       []internals.LLMMessage{{Role: "user", Content: "question"}},
+	}))
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+	actionID, err := repo.CreateTaskAction(*taskID, action)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	return u, storage, *taskID, *actionID
+}
+
+func pendingLLMOperations(t *testing.T, storage repository.Storage) []internals.PendingLLMOperation {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	operations, err := repo.GetPendingLLMOperations()
+	require.NoError(t, err)
+	return operations
+}
+
+func TestAskLLMActionIsSubmittedAndPolled(t *testing.T) {
+	t.Parallel()
+
+	llmClient := llm_client.NewScriptedLLMClient([]llm_client.ScriptedResponse{
+		{Contains: "question", Response: "answer"},
+	})
+	u, storage, _, actionID := newAskLLMTestUsecase(t, llmClient)
+
+	require.NoError(t, u.ExecuteAction(actionID))
+	// Redelivered message does not submit the request again.
+	require.NoError(t, u.ExecuteAction(actionID))
+	requests := llmClient.Requests()
+	require.Len(t, requests, 1)
+	require.Equal(t, llm_client.DefaultTemperature, requests[0].Temperature)
+	require.Equal(t, llm_client.DefaultMaxTokens, requests[0].MaxTokens)
+
+	operations := pendingLLMOperations(t, storage)
+	require.Len(t, operations, 1)
+	require.Equal(t, actionID, operations[0].TaskActionId)
+
+	require.NoError(t, u.PollLLMOperation(operations[0]))
+	require.Empty(t, pendingLLMOperations(t, storage))
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	_, info, err := repo.GetTaskActionByID(actionID)
+	require.NoError(t, err)
+	require.Equal(t, internals.Finished, info.Status)
+	result, _, err := repo.GetTaskActionResultByID(actionID)
+	require.NoError(t, err)
+	askLLMResult, err := result.AsTaskActionResultAskLLM()
+	require.NoError(t, err)
+	require.Equal(t, "answer", askLLMResult.ResponseMessage)
+}
+
+func TestAskLLMActionFailedByProvider(t *testing.T) {
+	t.Parallel()
+
+	u, storage, taskID, actionID := newAskLLMTestUsecase(t, llm_client.NewScriptedLLMClient(nil))
+	require.NoError(t, u.ExecuteAction(actionID))
+
+	operations := pendingLLMOperations(t, storage)
+	require.Len(t, operations, 1)
+	require.ErrorContains(t, u.PollLLMOperation(operations[0]), "no scripted response")
+	requireTaskFailed(t, storage, taskID, actionID)
+}
+
+func TestAskLLMActionTimeout(t *testing.T) {
+	t.Parallel()
+
+	u, storage, taskID, actionID := newAskLLMTestUsecase(t, pendingLLMClient{})
+	require.NoError(t, u.ExecuteAction(actionID))
+
+	operations := pendingLLMOperations(t, storage)
+	require.Len(t, operations, 1)
+	operation := operations[0]
+	operation.SubmittedAt = time.Now()
+
+	require.NoError(t, u.PollLLMOperation(operation))
+	require.Len(t, pendingLLMOperations(t, storage), 1)
+
+	operation.SubmittedAt = time.Now().Add(-llmOperationTimeout - time.Second)
+	require.Error(t, u.PollLLMOperation(operation))
+	require.Empty(t, pendingLLMOperations(t, storage))
+	requireTaskFailed(t, storage, taskID, actionID)
+}
+
+func TestAskLLMOperationReplacedByRetryIsSkipped(t *testing.T) {
+	t.Parallel()
+
+	u, storage, _, actionID := newAskLLMTestUsecase(t, pendingLLMClient{})
+	require.NoError(t, u.ExecuteAction(actionID))
+	staleOperations := pendingLLMOperations(t, storage)
+	require.Len(t, staleOperations, 1)
+
+	// Retried action submitted a new request while the poller still holds the
+	// operation of the previous attempt.
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	require.NoError(t, repo.SetPendingLLMOperation(actionID, "retried operation"))
+	require.NoError(t, repo.Commit())
+
+	staleOperation := staleOperations[0]
+	staleOperation.SubmittedAt = time.Now().Add(-llmOperationTimeout - time.Second)
+	require.NoError(t, u.PollLLMOperation(staleOperation))
+
+	operations := pendingLLMOperations(t, storage)
+	require.Len(t, operations, 1)
+	require.Equal(t, "retried operation", operations[0].OperationId)
+
+	reader := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer reader.Rollback()
+	_, info, err := reader.GetTaskActionByID(actionID)
+	require.NoError(t, err)
+	require.Equal(t, internals.Submitted, info.Status)
+}
+
+func requireTaskFailed(t *testing.T, storage repository.Storage, taskID api.TaskID, actionID internals.TaskActionID) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	_, info, err := repo.GetTaskActionByID(actionID)
+	require.NoError(t, err)
+	require.Equal(t, internals.Failed, info.Status)
+	taskDigest, _, err := repo.GetTaskByID(taskID)
+	require.NoError(t, err)
+	require.Equal(t, api.FailedByError, taskDigest.Status)
+}
@@ -9,69 +9,178 @@ import (
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
 
-func (u *taskActionUsecaseImpl) indexatePageInTransaction(repo repository.AppRepository, pageID api.PageID) error {
-	err := repo.RemovePageIndexation(pageID)
+type (
+	// previousParagraph is what is kept from the previous indexation of a
+	// paragraph with the same content.
+	previousParagraph struct {
+		embedding internals.Embedding
+		terms     []internals.Term
+	}
+
+	indexationStats struct {
+		reused     int
+		recomputed int
+	}
+
+	// pageIndexation is new indexation of a page, computed before anything is
+	// written, so that failure to compute it leaves the transaction clean.
+	pageIndexation struct {
+		paragraphs []internals.ParagraphWithEmbedding
+		terms      [][]internals.Term
+		stats      indexationStats
+	}
+)
+
+// loadPreviousParagraphs returns embeddings and terms of currently indexed
+// paragraphs of the page by their content hash.
+func loadPreviousParagraphs(repo repository.AppRepository, pageID api.PageID) (map[string]previousParagraph, error) {
+	digests, err := repo.GetPageIndexedParagraphs(pageID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get indexed paragraphs: %w", err)
 	}
 
-	page, _, err := repo.GetPageByID(pageID)
+	terms, err := repo.GetPageTerms(pageID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to get page terms: %w", err)
+	}
+
+	termsByParagraph := make(map[int64][]internals.Term)
+	for _, term := range terms {
+		termsByParagraph[term.ParagraphIndex] = append(termsByParagraph[term.ParagraphIndex], term)
+	}
+
+	previous := make(map[string]previousParagraph, len(digests))
+	for _, digest := range digests {
+		previous[digest.ContentHash] = previousParagraph{
+			embedding: digest.Embedding,
+			terms:     termsByParagraph[int64(digest.ParagraphIndex)],
+		}
 	}
+	return previous, nil
+}
 
-	paragraphs := indexing.SplitPageToParagraphs(pageID, page.Content)
+func makeTerms(pageID api.PageID, paragraphIndex int, stems []string) []internals.Term {
+	termCount := make(map[string]int64)
+	for _, stem := range stems {
+		termCount[stem]++
+	}
 
-	contentStrings := make([]string, len(paragraphs))
-	for i, paragraph := range paragraphs {
-		contentStrings[i] = paragraph.Content
+	terms := make([]internals.Term, 0, len(termCount))
+	for term, count := range termCount {
+		terms = append(terms, internals.Term{
+			Term:           term,
+			PageId:         pageID,
+			ParagraphIndex: int64(paragraphIndex),
+			TimesIn:        count,
+		})
 	}
+	return terms
+}
 
-	embeddings, err := u.deps.InferenceClient.GenerateEmbeddings(u.ctx, contentStrings)
+// computePageIndexation splits the page into paragraphs and finds their
+// embeddings and terms. Only paragraphs whose content changed since the
+// previous indexation are sent to inference service, the rest reuse stored
+// embeddings and terms.
+func (u *taskActionUsecaseImpl) computePageIndexation(repo repository.AppRepository, pageID api.PageID) (*pageIndexation, error) {
+	previous, err := loadPreviousParagraphs(repo, pageID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	stems, err := u.deps.InferenceClient.GenerateStems(u.ctx, contentStrings)
+	page, _, err := repo.GetPageByID(pageID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for i, paragraph := range paragraphs {
-		paragraph.Embedding = embeddings[i]
+	paragraphs := indexing.SplitPageToChunks(pageID, page.Content, indexing.ChunkingPolicy{
+		MaxTokens:     u.deps.Config.ChunkMaxTokens,
+		MinTokens:     u.deps.Config.ChunkMinTokens,
+		OverlapTokens: u.deps.Config.ChunkOverlapTokens,
+	})
+
+	terms := make([][]internals.Term, len(paragraphs))
+	changed := make([]int, 0)
+	contentStrings := make([]string, 0)
+	for i := range paragraphs {
+		paragraphs[i].ContentHash = indexing.ContentHash(paragraphs[i].Content)
+
+		reused, ok := previous[paragraphs[i].ContentHash]
+		if !ok {
+			changed = append(changed, i)
+			contentStrings = append(contentStrings, paragraphs[i].Content)
+			continue
+		}
 
-		err = repo.AddIndexedParagraph(paragraph)
+		paragraphs[i].Embedding = reused.embedding
+		terms[i] = make([]internals.Term, 0, len(reused.terms))
+		for _, term := range reused.terms {
+			term.ParagraphIndex = int64(paragraphs[i].ParagraphIndex)
+			terms[i] = append(terms[i], term)
+		}
+	}
+
+	if len(contentStrings) > 0 {
+		embeddings, err := u.deps.InferenceClient.GenerateEmbeddings(u.ctx, contentStrings)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		termCount := make(map[string]int64)
-		for _, stem := range stems[i] {
-			termCount[stem]++
+		stems, err := u.deps.InferenceClient.GenerateStems(u.ctx, contentStrings)
+		if err != nil {
+			return nil, err
 		}
 
-		terms := make([]internals.Term, 0, len(termCount))
-		for term, count := range termCount {
-			if count == 0 {
-				continue
-			}
-			terms = append(terms, internals.Term{
-				Term:           term,
-				PageId:         pageID,
-				ParagraphIndex: int64(paragraph.ParagraphIndex),
-				TimesIn:        count,
-			})
+		for j, i := range changed {
+			paragraphs[i].Embedding = embeddings[j]
+			terms[i] = makeTerms(pageID, paragraphs[i].ParagraphIndex, stems[j])
 		}
+	}
+
+	return &pageIndexation{
+		paragraphs: paragraphs,
+		terms:      terms,
+		stats: indexationStats{
+			reused:     len(paragraphs) - len(changed),
+			recomputed: len(changed),
+		},
+	}, nil
+}
+
+// writePageIndexation replaces paragraphs, terms and term statistics of the
+// page.
+func writePageIndexation(repo repository.AppRepository, pageID api.PageID, indexation *pageIndexation) error {
+	err := repo.RemovePageIndexation(pageID)
+	if err != nil {
+		return err
+	}
 
-		if len(terms) > 0 {
-			err = repo.AddTerms(terms)
+	stats := internals.PageTermStats{
+		PageId:               pageID,
+		ParagraphsCount:      int64(len(indexation.paragraphs)),
+		TermParagraphsCounts: make(map[string]int64),
+	}
+	for i, paragraph := range indexation.paragraphs {
+		paragraph.TermsCount = 0
+		for _, term := range indexation.terms[i] {
+			paragraph.TermsCount += term.TimesIn
+			stats.TermParagraphsCounts[term.Term]++
+		}
+		stats.TermsCount += paragraph.TermsCount
+
+		err = repo.AddIndexedParagraph(paragraph)
+		if err != nil {
+			return err
+		}
+
+		if len(indexation.terms[i]) > 0 {
+			err = repo.AddTerms(indexation.terms[i])
 			if err != nil {
 				return fmt.Errorf("failed to add terms for page %s: %w", pageID, err)
 			}
 		}
 	}
 
-	return nil
+	return repo.SetPageTermStats(stats)
 }
 
 func (u *taskActionUsecaseImpl) executeIndexatePageAction(repo repository.AppRepository, actionID internals.TaskActionID, taskAction *internals.TaskAction) error {
@@ -80,9 +189,27 @@ func (u *taskActionUsecaseImpl) executeIndexatePageAction(repo repository.AppRep
 		return fmt.Errorf("failed to parse task action as TaskActionIndexatePage: %w", err)
 	}
 
-	err = u.indexatePageInTransaction(repo, indexatePageAction.PageId)
+	indexatePageResult := internals.TaskActionResultIndexatePage{
+		TaskActionType: internals.IndexatePage,
+		PageId:         indexatePageAction.PageId,
+	}
+
+	indexation, err := u.computePageIndexation(repo, indexatePageAction.PageId)
 	if err != nil {
-		return fmt.Errorf("failed to indexate page: %w", err)
+		if indexatePageAction.SkipOnFailure == nil || !*indexatePageAction.SkipOnFailure {
+			return fmt.Errorf("failed to indexate page: %w", err)
+		}
+		u.log.Warn("skipping page that failed to indexate", "page_id", indexatePageAction.PageId, "error", err)
+		errorMessage := err.Error()
+		indexatePageResult.Error = &errorMessage
+	} else {
+		err = writePageIndexation(repo, indexatePageAction.PageId, indexation)
+		if err != nil {
+			return fmt.Errorf("failed to indexate page: %w", err)
+		}
+		u.log.Info("page indexated", "page_id", indexatePageAction.PageId, "reused_paragraphs", indexation.stats.reused, "recomputed_paragraphs", indexation.stats.recomputed)
+		indexatePageResult.ReusedParagraphs = indexation.stats.reused
+		indexatePageResult.RecomputedParagraphs = indexation.stats.recomputed
 	}
 
 	err = repo.SetTaskActionStatus(actionID, internals.Finished)
@@ -91,10 +218,6 @@ func (u *taskActionUsecaseImpl) executeIndexatePageAction(repo repository.AppRep
 	}
 
 	result := internals.TaskActionResult{}
-	indexatePageResult := internals.TaskActionResultIndexatePage{
-		TaskActionType: internals.IndexatePage,
-		PageId:         indexatePageAction.PageId,
-	}
 	err = result.FromTaskActionResultIndexatePage(indexatePageResult)
 	if err != nil {
 		return fmt.Errorf("failed to create task action result: %w", err)
@@ -0,0 +1,218 @@
+package task_actions_usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/inference_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/config"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// fakeInferenceClient records texts sent for embedding and stemming.
+type fakeInferenceClient struct {
+	inference_client.InferenceClient
+
+	embedded []string
+	stemmed  []string
+}
+
+func (c *fakeInferenceClient) GenerateEmbeddings(_ context.Context, texts []string) ([]internals.Embedding, error) {
+	c.embedded = append(c.embedded, texts...)
+	embeddings := make([]internals.Embedding, len(texts))
+	for i := range texts {
+		embeddings[i] = internals.Embedding{float32(len(c.embedded) - len(texts) + i + 1)}
+	}
+	return embeddings, nil
+}
+
+func (c *fakeInferenceClient) GenerateStems(_ context.Context, paragraphs []string) ([][]string, error) {
+	c.stemmed = append(c.stemmed, paragraphs...)
+	stems := make([][]string, len(paragraphs))
+	for i, paragraph := range paragraphs {
+		stems[i] = strings.Fields(strings.ToLower(paragraph))
+	}
+	return stems, nil
+}
+
+// termStatsRecorder records indexation written to memory repository, which
+// does not read paragraph lengths and term statistics back.
+type termStatsRecorder struct {
+	repository.AppRepository
+
+	paragraphs []internals.ParagraphWithEmbedding
+	stats      *internals.PageTermStats
+}
+
+func (r *termStatsRecorder) AddIndexedParagraph(paragraph internals.ParagraphWithEmbedding) error {
+	r.paragraphs = append(r.paragraphs, paragraph)
+	return r.AppRepository.AddIndexedParagraph(paragraph)
+}
+
+func (r *termStatsRecorder) SetPageTermStats(stats internals.PageTermStats) error {
+	r.stats = &stats
+	return r.AppRepository.SetPageTermStats(stats)
+}
+
+func newIndexationTestUsecase(inference *fakeInferenceClient) (*taskActionUsecaseImpl, repository.Storage) {
+	log := logger.InitTestLogger()
+	storage := repository.NewMemoryStorage(log, nil, nil)
+	return &taskActionUsecaseImpl{
+		ctx: context.Background(),
+		deps: &deps.Deps{
+			Storage:         storage,
+			Logger:          log,
+			InferenceClient: inference,
+			Config:          &config.Config{ChunkMaxTokens: 256, ChunkMinTokens: 0, ChunkOverlapTokens: 0},
+		},
+		log: log,
+	}, storage
+}
+
+// setPageContent creates page or appends its revision with given content.
+func setPageContent(t *testing.T, storage repository.Storage, pageID *api.PageID, content string) api.PageID {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	if pageID == nil {
+		var err error
+		pageID, err = repo.CreatePage("page", "Page", content)
+		require.NoError(t, err)
+	} else {
+		_, err := repo.AppendPageRevision(*pageID, content, api.YwikiSync, nil)
+		require.NoError(t, err)
+	}
+	require.NoError(t, repo.Commit())
+	return *pageID
+}
+
+func indexatePage(t *testing.T, u *taskActionUsecaseImpl, storage repository.Storage, pageID api.PageID) indexationStats {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	indexation, err := u.computePageIndexation(repo, pageID)
+	require.NoError(t, err)
+	require.NoError(t, writePageIndexation(repo, pageID, indexation))
+	require.NoError(t, repo.Commit())
+	return indexation.stats
+}
+
+// indexedParagraph returns content, embedding and terms of indexed paragraph.
+func indexedParagraph(t *testing.T, storage repository.Storage, pageID api.PageID, paragraphIndex int) (string, internals.Embedding, []string) {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	paragraph, err := repo.GetParagraphWithContext(pageID, paragraphIndex, 0)
+	require.NoError(t, err)
+
+	digests, err := repo.GetPageIndexedParagraphs(pageID)
+	require.NoError(t, err)
+	var embedding internals.Embedding
+	for _, digest := range digests {
+		if digest.ParagraphIndex == paragraphIndex {
+			embedding = digest.Embedding
+		}
+	}
+
+	pageTerms, err := repo.GetPageTerms(pageID)
+	require.NoError(t, err)
+	terms := make([]string, 0)
+	for _, term := range pageTerms {
+		if term.ParagraphIndex == int64(paragraphIndex) {
+			terms = append(terms, term.Term)
+		}
+	}
+
+	return paragraph.Content, embedding, terms
+}
+
+func indexedParagraphsCount(t *testing.T, storage repository.Storage, pageID api.PageID) int {
+	t.Helper()
+	repo := storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	digests, err := repo.GetPageIndexedParagraphs(pageID)
+	require.NoError(t, err)
+	return len(digests)
+}
+
+func TestIndexatePageReusesUnchangedParagraphs(t *testing.T) {
+	t.Parallel()
+
+	inference := &fakeInferenceClient{}
+	u, storage := newIndexationTestUsecase(inference)
+	pageID := setPageContent(t, storage, nil, "# Title\n\nFirst paragraph.\n\nSecond paragraph.\n")
+
+	require.Equal(t, indexationStats{reused: 0, recomputed: 3}, indexatePage(t, u, storage, pageID))
+	_, firstEmbedding, _ := indexedParagraph(t, storage, pageID, 1)
+
+	setPageContent(t, storage, &pageID, "# Title\n\nNew paragraph.\n\nFirst paragraph.\n\nSecond paragraph.\n")
+	inference.embedded = nil
+	inference.stemmed = nil
+
+	require.Equal(t, indexationStats{reused: 3, recomputed: 1}, indexatePage(t, u, storage, pageID))
+	require.Equal(t, []string{"New paragraph."}, inference.embedded)
+	require.Equal(t, []string{"New paragraph."}, inference.stemmed)
+
+	require.Equal(t, 4, indexedParagraphsCount(t, storage, pageID))
+	content, embedding, terms := indexedParagraph(t, storage, pageID, 2)
+	require.Equal(t, "First paragraph.", content)
+	require.Equal(t, firstEmbedding, embedding)
+	require.ElementsMatch(t, []string{"first", "paragraph."}, terms)
+	_, _, terms = indexedParagraph(t, storage, pageID, 1)
+	require.ElementsMatch(t, []string{"new", "paragraph."}, terms)
+}
+
+func TestIndexatePageSkipsInferenceWhenNothingChanged(t *testing.T) {
+	t.Parallel()
+
+	inference := &fakeInferenceClient{}
+	u, storage := newIndexationTestUsecase(inference)
+	pageID := setPageContent(t, storage, nil, "Only paragraph.\n")
+
+	indexatePage(t, u, storage, pageID)
+	inference.embedded = nil
+
+	require.Equal(t, indexationStats{reused: 1, recomputed: 0}, indexatePage(t, u, storage, pageID))
+	require.Empty(t, inference.embedded)
+}
+
+func TestIndexatePageWritesTermStats(t *testing.T) {
+	t.Parallel()
+
+	u, storage := newIndexationTestUsecase(&fakeInferenceClient{})
+	pageID := setPageContent(t, storage, nil, "Deploy with helm helm.\n\nDeploy with make.\n")
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+	indexation, err := u.computePageIndexation(repo, pageID)
+	require.NoError(t, err)
+	recorder := &termStatsRecorder{AppRepository: repo}
+	require.NoError(t, writePageIndexation(recorder, pageID, indexation))
+
+	require.Len(t, recorder.paragraphs, 2)
+	require.Equal(t, int64(4), recorder.paragraphs[0].TermsCount)
+	require.Equal(t, int64(3), recorder.paragraphs[1].TermsCount)
+	require.Equal(t, &internals.PageTermStats{
+		PageId:          pageID,
+		ParagraphsCount: 2,
+		TermsCount:      7,
+		TermParagraphsCounts: map[string]int64{
+			"deploy": 2,
+			"with":   2,
+			"helm":   1,
+			"helm.":  1,
+			"make.":  1,
+		},
+	}, recorder.stats)
+}
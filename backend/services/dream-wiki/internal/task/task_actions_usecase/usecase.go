@@ -17,6 +17,7 @@ import (
 type (
 	TaskActionUsecase interface {
 		ExecuteAction(actionID internals.TaskActionID) error
+		PollLLMOperation(operation internals.PendingLLMOperation) error
 	}
 
 	taskActionUsecaseImpl struct {
@@ -52,11 +53,7 @@ func (u *taskActionUsecaseImpl) failTaskActionAndTask(repo repository.AppReposit
 }
 
 func (u *taskActionUsecaseImpl) ExecuteAction(actionID internals.TaskActionID) (err error) {
-	tx := u.deps.YDBDriver.NewTransaction(u.ctx, db_adapter.SerializableReadWrite)
-	repo := repository.NewAppRepository(u.ctx, &deps.RepositoryDeps{
-		TX:   tx,
-		Deps: u.deps,
-	})
+	repo := u.deps.Storage.NewRepository(u.ctx, db_adapter.SerializableReadWrite)
 	defer repo.Rollback()
 
 	defer func() {
@@ -85,6 +82,29 @@ func (u *taskActionUsecaseImpl) ExecuteAction(actionID internals.TaskActionID) (
 		return fmt.Errorf("failed to get task action by ID: %w", err)
 	}
 
+	if taskActionAdditionalInfo.Status == internals.Abandoned {
+		u.log.Info("skipping abandoned task action",
+			"action_id", actionID,
+			"task_id", taskActionAdditionalInfo.TaskId)
+		return nil
+	}
+
+	if taskActionAdditionalInfo.Status == internals.Finished {
+		// Message was redelivered, e.g. enqueued again by task retry.
+		u.log.Info("skipping finished task action",
+			"action_id", actionID,
+			"task_id", taskActionAdditionalInfo.TaskId)
+		return nil
+	}
+
+	if taskActionAdditionalInfo.Status == internals.Submitted {
+		// Message was redelivered after the LLM request had been submitted.
+		u.log.Info("skipping submitted task action",
+			"action_id", actionID,
+			"task_id", taskActionAdditionalInfo.TaskId)
+		return nil
+	}
+
 	taskDigest, _, err := repo.GetTaskByID(taskActionAdditionalInfo.TaskId)
 	if err != nil {
 		return fmt.Errorf("failed to get task by ID: %w", err)
@@ -111,6 +131,14 @@ func (u *taskActionUsecaseImpl) ExecuteAction(actionID internals.TaskActionID) (
 		err = u.executeAskLLMAction(repo, actionID, taskAction)
 	case internals.IndexatePage:
 		err = u.executeIndexatePageAction(repo, actionID, taskAction)
+	case internals.ListYwikiPages:
+		err = u.executeListYWikiPagesAction(repo, actionID, taskAction)
+	case internals.FetchYwikiPage:
+		err = u.executeFetchYWikiPageAction(repo, actionID, taskAction)
+	case internals.PublishDraftToYwiki:
+		// YWiki must not be written while the transaction is held.
+		repo.Rollback()
+		return u.executePublishDraftToYWikiAction(actionID, taskActionAdditionalInfo.TaskId, taskAction)
 	default:
 		err = fmt.Errorf("unsupported task action type: %s", actionType)
 	}
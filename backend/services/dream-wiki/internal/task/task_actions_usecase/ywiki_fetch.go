@@ -0,0 +1,133 @@
+package task_actions_usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ywiki_client_gen"
+)
+
+func (u *taskActionUsecaseImpl) executeListYWikiPagesAction(repo repository.AppRepository, actionID internals.TaskActionID, taskAction *internals.TaskAction) error {
+	listAction, err := taskAction.AsTaskActionListYWikiPages()
+	if err != nil {
+		return fmt.Errorf("failed to parse task action as TaskActionListYWikiPages: %w", err)
+	}
+
+	descendants, err := u.deps.YWikiClient.GetPageDescendants(u.ctx, listAction.RootSlug, listAction.Cursor)
+	if err != nil {
+		return fmt.Errorf("failed to list YWiki page descendants: %w", err)
+	}
+
+	pageSlugs := make([]string, 0, len(descendants.Results))
+	for _, descendant := range descendants.Results {
+		pageSlugs = append(pageSlugs, descendant.Slug)
+	}
+
+	err = repo.SetTaskActionStatus(actionID, internals.Finished)
+	if err != nil {
+		return fmt.Errorf("failed to set task action status to finished: %w", err)
+	}
+
+	result := internals.TaskActionResult{}
+	err = result.FromTaskActionResultListYWikiPages(internals.TaskActionResultListYWikiPages{
+		TaskActionType: internals.ListYwikiPages,
+		PageSlugs:      pageSlugs,
+		NextCursor:     descendants.NextCursor,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create task action result: %w", err)
+	}
+
+	err = repo.CreateTaskActionResult(actionID, result)
+	if err != nil {
+		return fmt.Errorf("failed to create task action result: %w", err)
+	}
+
+	err = repo.EnqueueTaskActionResult(actionID)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task action result: %w", err)
+	}
+
+	return nil
+}
+
+func (u *taskActionUsecaseImpl) executeFetchYWikiPageAction(repo repository.AppRepository, actionID internals.TaskActionID, taskAction *internals.TaskAction) error {
+	fetchAction, err := taskAction.AsTaskActionFetchYWikiPage()
+	if err != nil {
+		return fmt.Errorf("failed to parse task action as TaskActionFetchYWikiPage: %w", err)
+	}
+
+	fetchResult := internals.TaskActionResultFetchYWikiPage{
+		TaskActionType: internals.FetchYwikiPage,
+		PageSlug:       fetchAction.PageSlug,
+	}
+
+	pageResponse, err := u.deps.YWikiClient.GetPage(u.ctx, fetchAction.PageSlug)
+	if errors.Is(err, models.ErrNotFound) {
+		// Page was removed after listing or is not accessible, the rest of the
+		// pages are still fetched.
+		u.log.Warn("skipping page missing in YWiki", "page_slug", fetchAction.PageSlug, "error", err)
+		errorMessage := err.Error()
+		fetchResult.Error = &errorMessage
+	} else if err != nil {
+		return fmt.Errorf("failed to fetch page %s from YWiki: %w", fetchAction.PageSlug, err)
+	} else {
+		pageID, err := u.upsertYWikiPage(repo, fetchAction.PageSlug, pageResponse)
+		if err != nil {
+			return err
+		}
+		fetchResult.PageId = pageID
+	}
+
+	err = repo.SetTaskActionStatus(actionID, internals.Finished)
+	if err != nil {
+		return fmt.Errorf("failed to set task action status to finished: %w", err)
+	}
+
+	result := internals.TaskActionResult{}
+	err = result.FromTaskActionResultFetchYWikiPage(fetchResult)
+	if err != nil {
+		return fmt.Errorf("failed to create task action result: %w", err)
+	}
+
+	err = repo.CreateTaskActionResult(actionID, result)
+	if err != nil {
+		return fmt.Errorf("failed to create task action result: %w", err)
+	}
+
+	err = repo.EnqueueTaskActionResult(actionID)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task action result: %w", err)
+	}
+
+	return nil
+}
+
+// upsertYWikiPage saves fetched page and attaches it to its closest known ancestor.
+func (u *taskActionUsecaseImpl) upsertYWikiPage(repo repository.AppRepository, pageSlug string, pageResponse *ywiki_client_gen.V1PageResponse) (*api.PageID, error) {
+	content := ""
+	if pageResponse.Content != nil {
+		content = *pageResponse.Content
+	}
+
+	pageID, err := repo.UpsertPage(pageSlug, pageResponse.Title, content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert page %s: %w", pageSlug, err)
+	}
+
+	parentPageID, err := repo.GetClosestAncestorPageID(pageSlug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent of page %s: %w", pageSlug, err)
+	}
+
+	err = repo.SetPageParentID(*pageID, parentPageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set parent of page %s: %w", pageSlug, err)
+	}
+
+	return pageID, nil
+}
@@ -0,0 +1,72 @@
+package task_actions_usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/ywiki_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ywiki_client_gen"
+)
+
+// missingPageYWikiClient answers every page request as YWiki does for removed pages.
+type missingPageYWikiClient struct {
+	ywiki_client.YWikiClient
+}
+
+func (missingPageYWikiClient) GetPage(context.Context, string) (*ywiki_client_gen.V1PageResponse, error) {
+	return nil, fmt.Errorf("YWiki GetPage: %w", models.ErrNotFound)
+}
+
+func TestFetchYWikiPageSkipsMissingPage(t *testing.T) {
+	t.Parallel()
+
+	log := logger.InitTestLogger()
+	storage := repository.NewMemoryStorage(log, nil, nil)
+	u := &taskActionUsecaseImpl{
+		ctx:  context.Background(),
+		deps: &deps.Deps{Storage: storage, Logger: log, YWikiClient: missingPageYWikiClient{}},
+		log:  log,
+	}
+
+	var state internals.TaskState
+	require.NoError(t, state.FromTaskStateYWikiFetchAll(internals.TaskStateYWikiFetchAll{
+		TaskType:  internals.YwikiFetchAll,
+		PageSlugs: []string{"removed"},
+	}))
+	var action internals.TaskAction
+	require.NoError(t, action.FromTaskActionFetchYWikiPage(internals.TaskActionFetchYWikiPage{
+		TaskActionType: internals.FetchYwikiPage,
+		PageSlug:       "removed",
+	}))
+
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+	actionID, err := repo.CreateTaskAction(*taskID, action)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	require.NoError(t, u.ExecuteAction(*actionID))
+
+	repo = storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+	_, actionInfo, err := repo.GetTaskActionByID(*actionID)
+	require.NoError(t, err)
+	require.Equal(t, internals.Finished, actionInfo.Status)
+
+	result, _, err := repo.GetTaskActionResultByID(*actionID)
+	require.NoError(t, err)
+	fetchResult, err := result.AsTaskActionResultFetchYWikiPage()
+	require.NoError(t, err)
+	require.Equal(t, "removed", fetchResult.PageSlug)
+	require.Nil(t, fetchResult.PageId)
+	require.NotNil(t, fetchResult.Error)
+}
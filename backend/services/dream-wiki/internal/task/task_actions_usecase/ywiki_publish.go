@@ -0,0 +1,256 @@
+package task_actions_usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/models"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ywiki_client_gen"
+)
+
+type (
+	// draftPublication is the draft with its page as they were read before
+	// publishing, and the YWiki page found changed by someone else.
+	draftPublication struct {
+		draft      *api.Draft
+		draftInfo  *internals.DraftAdditionalInfo
+		page       *api.Page
+		remotePage *ywiki_client_gen.V1PageResponse
+	}
+)
+
+func (p *draftPublication) outdated() bool {
+	return p.draft.DraftDigest.Status != api.Publishing || p.draftInfo.BaseRevisionId != p.draftInfo.PageCurrentRevisionId
+}
+
+// publishDraftToYWiki writes draft to YWiki and, on success, applies it locally.
+// No transaction is held while YWiki is requested: the draft is read before,
+// and the outcome is recorded in a new transaction after. Draft is marked
+// publishing before the task starts, so YWiki write may be repeated after
+// failed recording: YWiki page equal to the draft counts as published. When
+// YWiki page differs from both the draft and its base, remote content is
+// stored as a new local revision instead, so the draft has to be rebased
+// before next publishing.
+func (u *taskActionUsecaseImpl) publishDraftToYWiki(actionID internals.TaskActionID, publishAction internals.TaskActionPublishDraftToYWiki) (internals.YWikiPublishOutcome, error) {
+	publication, err := u.readDraftPublication(publishAction.DraftId)
+	if err != nil {
+		return "", err
+	}
+
+	outcome := internals.DraftOutdated
+	if !publication.outdated() {
+		outcome, err = u.writeDraftToYWiki(publication)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = u.recordDraftPublication(actionID, publication, outcome, publishAction.AppliedBy)
+	if err != nil {
+		return "", err
+	}
+
+	return outcome, nil
+}
+
+func (u *taskActionUsecaseImpl) readDraftPublication(draftID api.DraftID) (*draftPublication, error) {
+	repo := u.deps.Storage.NewRepository(u.ctx, db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	draft, draftInfo, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft %s: %w", draftID, err)
+	}
+
+	pageID := draft.DraftDigest.PageDigest.PageId
+	page, _, err := repo.GetPageByID(pageID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get page %s: %w", pageID, err)
+	}
+
+	return &draftPublication{draft: draft, draftInfo: draftInfo, page: page}, nil
+}
+
+// writeDraftToYWiki creates or updates YWiki page unless it was changed by
+// someone else since the draft base.
+func (u *taskActionUsecaseImpl) writeDraftToYWiki(publication *draftPublication) (internals.YWikiPublishOutcome, error) {
+	draft, page := publication.draft, publication.page
+
+	baseContent := ""
+	if draft.OriginalPageContent != nil {
+		baseContent = *draft.OriginalPageContent
+	}
+
+	remotePage, err := u.deps.YWikiClient.GetPage(u.ctx, page.YwikiSlug)
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		_, err = u.deps.YWikiClient.CreatePage(u.ctx, page.YwikiSlug, draft.DraftDigest.DraftTitle, draft.Content)
+		if err != nil {
+			return "", fmt.Errorf("failed to create page %s in YWiki: %w", page.YwikiSlug, err)
+		}
+
+	case err != nil:
+		return "", fmt.Errorf("failed to fetch page %s from YWiki: %w", page.YwikiSlug, err)
+
+	default:
+		remoteContent := ""
+		if remotePage.Content != nil {
+			remoteContent = *remotePage.Content
+		}
+
+		if remoteContent != baseContent && remoteContent != draft.Content {
+			publication.remotePage = remotePage
+			return internals.RemoteChanged, nil
+		}
+
+		if remoteContent != draft.Content || remotePage.Title != draft.DraftDigest.DraftTitle {
+			_, err = u.deps.YWikiClient.UpdatePage(u.ctx, remotePage.Id, draft.DraftDigest.DraftTitle, draft.Content)
+			if err != nil {
+				return "", fmt.Errorf("failed to update page %s in YWiki: %w", page.YwikiSlug, err)
+			}
+		}
+	}
+
+	return internals.Published, nil
+}
+
+// recordDraftPublication applies outcome of publishing locally and finishes the
+// action. Action finished meanwhile by redelivered message is left as is.
+func (u *taskActionUsecaseImpl) recordDraftPublication(actionID internals.TaskActionID, publication *draftPublication, outcome internals.YWikiPublishOutcome, appliedBy *string) error {
+	repo := u.deps.Storage.NewRepository(u.ctx, db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	_, taskActionAdditionalInfo, err := repo.GetTaskActionByID(actionID)
+	if err != nil {
+		return fmt.Errorf("failed to get task action by ID: %w", err)
+	}
+	if taskActionAdditionalInfo.Status == internals.Finished {
+		u.log.Info("skipping publishing outcome of finished task action", "action_id", actionID)
+		return nil
+	}
+
+	draftID := publication.draft.DraftDigest.DraftId
+	switch outcome {
+	case internals.Published:
+		err = applyPublishedDraft(repo, publication, appliedBy)
+
+	case internals.RemoteChanged:
+		remotePage := publication.remotePage
+		remoteContent := ""
+		if remotePage.Content != nil {
+			remoteContent = *remotePage.Content
+		}
+		_, err = repo.UpsertPage(publication.page.YwikiSlug, remotePage.Title, remoteContent)
+		if err != nil {
+			return fmt.Errorf("failed to store remote content of page %s: %w", publication.page.YwikiSlug, err)
+		}
+		err = markDraftOutdated(repo, draftID)
+
+	default:
+		err = markDraftOutdated(repo, draftID)
+	}
+	if err != nil {
+		return err
+	}
+
+	logErr := repo.WriteIntegrationLogField("ywiki", fmt.Sprintf("Publishing draft %s: %s", draftID, outcome))
+	if logErr != nil {
+		u.log.Error("failed to write integration log", "error", logErr)
+	}
+
+	err = repo.SetTaskActionStatus(actionID, internals.Finished)
+	if err != nil {
+		return fmt.Errorf("failed to set task action status to finished: %w", err)
+	}
+
+	result := internals.TaskActionResult{}
+	err = result.FromTaskActionResultPublishDraftToYWiki(internals.TaskActionResultPublishDraftToYWiki{
+		TaskActionType: internals.PublishDraftToYwiki,
+		DraftId:        draftID,
+		Outcome:        outcome,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create task action result: %w", err)
+	}
+
+	err = repo.CreateTaskActionResult(actionID, result)
+	if err != nil {
+		return fmt.Errorf("failed to create task action result: %w", err)
+	}
+
+	err = repo.EnqueueTaskActionResult(actionID)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue task action result: %w", err)
+	}
+
+	return repo.Commit()
+}
+
+// applyPublishedDraft stores published draft as a new page revision.
+func applyPublishedDraft(repo repository.AppRepository, publication *draftPublication, appliedBy *string) error {
+	draft, page := publication.draft, publication.page
+	pageID := page.PageId
+
+	revisionSource := api.DraftApply
+	if publication.draftInfo.SourceTaskId != nil {
+		revisionSource = api.GithubPr
+	}
+
+	_, err := repo.AppendPageRevision(pageID, draft.Content, revisionSource, appliedBy)
+	if err != nil {
+		return fmt.Errorf("failed to append revision of page %s: %w", pageID, err)
+	}
+
+	if draft.DraftDigest.DraftTitle != page.Title {
+		err = repo.SetPageTitle(pageID, draft.DraftDigest.DraftTitle)
+		if err != nil {
+			return fmt.Errorf("failed to set title of page %s: %w", pageID, err)
+		}
+	}
+
+	err = repo.SetDraftStatus(draft.DraftDigest.DraftId, api.Merged)
+	if err != nil {
+		return fmt.Errorf("failed to set draft %s status to merged: %w", draft.DraftDigest.DraftId, err)
+	}
+
+	return nil
+}
+
+// markDraftOutdated marks draft which was not published as needing rebase, so
+// it can be applied again after that.
+func markDraftOutdated(repo repository.AppRepository, draftID api.DraftID) error {
+	draft, _, err := repo.GetDraftByID(draftID)
+	if err != nil {
+		return fmt.Errorf("failed to get draft %s: %w", draftID, err)
+	}
+	if draft.DraftDigest.Status != api.Publishing {
+		return nil
+	}
+	err = repo.SetDraftStatus(draftID, api.NeedsRebase)
+	if err != nil {
+		return fmt.Errorf("failed to set draft %s status to needs rebase: %w", draftID, err)
+	}
+	return nil
+}
+
+// executePublishDraftToYWikiAction runs with no transaction of ExecuteAction
+// held, so it fails the action and its task in a transaction of its own.
+func (u *taskActionUsecaseImpl) executePublishDraftToYWikiAction(actionID internals.TaskActionID, taskID api.TaskID, taskAction *internals.TaskAction) error {
+	publishAction, err := taskAction.AsTaskActionPublishDraftToYWiki()
+	if err != nil {
+		err = fmt.Errorf("failed to parse task action as TaskActionPublishDraftToYWiki: %w", err)
+	} else {
+		_, err = u.publishDraftToYWiki(actionID, publishAction)
+	}
+	if err != nil {
+		repo := u.deps.Storage.NewRepository(u.ctx, db_adapter.SerializableReadWrite)
+		defer repo.Rollback()
+		u.failTaskActionAndTask(repo, actionID, taskID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package task_actions_usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/client/ywiki_client"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/ywiki_client_gen"
+)
+
+// fakeYWikiClient keeps a single YWiki page and counts its updates.
+type fakeYWikiClient struct {
+	ywiki_client.YWikiClient
+
+	title   string
+	content string
+	updates int
+}
+
+func (c *fakeYWikiClient) GetPage(_ context.Context, pageSlug string) (*ywiki_client_gen.V1PageResponse, error) {
+	content := c.content
+	return &ywiki_client_gen.V1PageResponse{Id: 1, Slug: pageSlug, Title: c.title, Content: &content}, nil
+}
+
+func (c *fakeYWikiClient) UpdatePage(_ context.Context, _ int64, title string, content string) (*ywiki_client_gen.V1PageResponse, error) {
+	c.title, c.content = title, content
+	c.updates++
+	return &ywiki_client_gen.V1PageResponse{Id: 1, Title: title, Content: &content}, nil
+}
+
+func TestPublishDraftToYWiki(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		draftStatus     api.DraftStatus
+		remoteContent   string
+		expectedOutcome internals.YWikiPublishOutcome
+		expectedUpdates int
+		expectedStatus  api.DraftStatus
+		expectedContent string
+	}{
+		{
+			name:            "remote equals base",
+			draftStatus:     api.Publishing,
+			remoteContent:   "v1",
+			expectedOutcome: internals.Published,
+			expectedUpdates: 1,
+			expectedStatus:  api.Merged,
+			expectedContent: "v2",
+		},
+		{
+			// Previous attempt wrote YWiki, but its outcome was not recorded.
+			name:            "remote equals draft",
+			draftStatus:     api.Publishing,
+			remoteContent:   "v2",
+			expectedOutcome: internals.Published,
+			expectedUpdates: 0,
+			expectedStatus:  api.Merged,
+			expectedContent: "v2",
+		},
+		{
+			name:            "remote changed",
+			draftStatus:     api.Publishing,
+			remoteContent:   "v1 edited in YWiki",
+			expectedOutcome: internals.RemoteChanged,
+			expectedUpdates: 0,
+			expectedStatus:  api.NeedsRebase,
+			expectedContent: "v1 edited in YWiki",
+		},
+		{
+			name:            "draft is not publishing",
+			draftStatus:     api.Active,
+			remoteContent:   "v1",
+			expectedOutcome: internals.DraftOutdated,
+			expectedUpdates: 0,
+			expectedStatus:  api.Active,
+			expectedContent: "v1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			log := logger.InitTestLogger()
+			storage := repository.NewMemoryStorage(log, nil, nil)
+			yWikiClient := &fakeYWikiClient{title: "Page", content: tt.remoteContent}
+			u := &taskActionUsecaseImpl{
+				ctx:  context.Background(),
+				deps: &deps.Deps{Storage: storage, Logger: log, YWikiClient: yWikiClient},
+				log:  log,
+			}
+
+			repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+			pageID, err := repo.CreatePage("page", "Page", "v1")
+			require.NoError(t, err)
+			draftID, err := repo.CreateDraft(*pageID, "Page", "v2", nil, nil)
+			require.NoError(t, err)
+			require.NoError(t, repo.SetDraftStatus(*draftID, tt.draftStatus))
+
+			var state internals.TaskState
+			require.NoError(t, state.FromTaskStateYWikiPublishDraft(internals.TaskStateYWikiPublishDraft{
+				TaskType: internals.YwikiPublishDraft,
+				DraftId:  *draftID,
+			}))
+			publishAction := internals.TaskActionPublishDraftToYWiki{
+				TaskActionType: internals.PublishDraftToYwiki,
+				DraftId:        *draftID,
+			}
+			var action internals.TaskAction
+			require.NoError(t, action.FromTaskActionPublishDraftToYWiki(publishAction))
+			taskID, err := repo.CreateTask(state)
+			require.NoError(t, err)
+			actionID, err := repo.CreateTaskAction(*taskID, action)
+			require.NoError(t, err)
+			require.NoError(t, repo.Commit())
+
+			outcome, err := u.publishDraftToYWiki(*actionID, publishAction)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedOutcome, outcome)
+			require.Equal(t, tt.expectedUpdates, yWikiClient.updates)
+
+			repo = storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+			defer repo.Rollback()
+			draft, _, err := repo.GetDraftByID(*draftID)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStatus, draft.DraftDigest.Status)
+			page, _, err := repo.GetPageByID(*pageID)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedContent, page.Content)
+			_, actionInfo, err := repo.GetTaskActionByID(*actionID)
+			require.NoError(t, err)
+			require.Equal(t, internals.Finished, actionInfo.Status)
+			result, _, err := repo.GetTaskActionResultByID(*actionID)
+			require.NoError(t, err)
+			publishResult, err := result.AsTaskActionResultPublishDraftToYWiki()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedOutcome, publishResult.Outcome)
+		})
+	}
+}
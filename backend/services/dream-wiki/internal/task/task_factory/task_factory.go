@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/ask_question"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/github_account_pr"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/reindexate_pages"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/ywiki_fetch_all"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/ywiki_publish_draft"
 	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
 )
 
@@ -39,6 +42,39 @@ func CreateTaskLogicCreator() task_common.TaskLogicCreator {
 				return nil, fmt.Errorf("task is nil")
 			}
 			return task, nil
+
+		case internals.YwikiFetchAll:
+			taskState, err := deps.State.AsTaskStateYWikiFetchAll()
+			if err != nil {
+				return nil, err
+			}
+			task := ywiki_fetch_all.NewYWikiFetchAllTask(ctx, taskState, deps)
+			if task == nil {
+				return nil, fmt.Errorf("task is nil")
+			}
+			return task, nil
+
+		case internals.YwikiPublishDraft:
+			taskState, err := deps.State.AsTaskStateYWikiPublishDraft()
+			if err != nil {
+				return nil, err
+			}
+			task := ywiki_publish_draft.NewYWikiPublishDraftTask(ctx, taskState, deps)
+			if task == nil {
+				return nil, fmt.Errorf("task is nil")
+			}
+			return task, nil
+
+		case internals.AskQuestion:
+			taskState, err := deps.State.AsTaskStateAskQuestion()
+			if err != nil {
+				return nil, err
+			}
+			task := ask_question.NewAskQuestionTask(ctx, taskState, deps)
+			if task == nil {
+				return nil, fmt.Errorf("task is nil")
+			}
+			return task, nil
 		}
 		return nil, fmt.Errorf("unknown task type")
 	}
@@ -0,0 +1,224 @@
+package ywiki_fetch_all
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+type (
+	// yWikiFetchAllTask mirrors YWiki subtree in two stages: list descendants of
+	// the root page and fetch pages one by one. Every stage keeps its progress in
+	// task state, so the task resumes after retry. Pages missing in YWiki are
+	// recorded in state and skipped. Fetched pages are indexated by
+	// PageRevisionsReindexer, as any other appended page revision.
+	yWikiFetchAllTask struct {
+		taskID api.TaskID
+		status api.TaskStatus
+		state  internals.TaskStateYWikiFetchAll
+		ctx    context.Context
+		deps   *deps.Deps
+		repo   repository.AppRepository
+	}
+)
+
+var (
+	_ task_common.TaskLogic = (*yWikiFetchAllTask)(nil)
+)
+
+func NewYWikiFetchAllTask(ctx context.Context, state internals.TaskStateYWikiFetchAll, deps *task_common.TaskDeps) *yWikiFetchAllTask {
+	return &yWikiFetchAllTask{
+		state:  state,
+		status: deps.Digest.Status,
+		ctx:    ctx,
+		deps:   deps.Deps,
+		taskID: deps.Digest.TaskId,
+		repo:   deps.Repo,
+	}
+}
+
+func (t *yWikiFetchAllTask) getSubtaskStatus(completed bool) api.TaskStatus {
+	if completed {
+		return api.Done
+	}
+	if t.status != api.Done {
+		return t.status
+	}
+	return api.Executing
+}
+
+func (t *yWikiFetchAllTask) processedPagesCount() int {
+	return len(t.state.FetchedPageIds) + len(t.state.SkippedPages)
+}
+
+func (t *yWikiFetchAllTask) CalculateSubtasks() ([]api.Subtask, error) {
+	listingDescription := "Listing pages under " + t.state.RootSlug
+	if t.state.RootSlug == "" {
+		listingDescription = "Listing known pages"
+	}
+	subtasks := []api.Subtask{
+		{
+			Description: listingDescription,
+			Status:      t.getSubtaskStatus(t.state.ListingFinished),
+			Subsubtasks: []api.SubSubtask{},
+		},
+	}
+
+	skippedSlugs := make(map[string]struct{}, len(t.state.SkippedPages))
+	for _, skipped := range t.state.SkippedPages {
+		skippedSlugs[skipped.PageSlug] = struct{}{}
+	}
+
+	for i, slug := range t.state.PageSlugs {
+		status := t.getSubtaskStatus(i < t.processedPagesCount())
+		if _, ok := skippedSlugs[slug]; ok {
+			status = api.FailedByError
+		}
+		subtasks = append(subtasks, api.Subtask{
+			Description: "Fetching page " + slug,
+			Status:      status,
+			Subsubtasks: []api.SubSubtask{},
+		})
+	}
+
+	return subtasks, nil
+}
+
+func (t *yWikiFetchAllTask) updateState() error {
+	taskState := internals.TaskState{}
+	err := taskState.FromTaskStateYWikiFetchAll(t.state)
+	if err != nil {
+		return err
+	}
+	return t.repo.SetTaskState(t.taskID, taskState)
+}
+
+func (t *yWikiFetchAllTask) createTaskAction(taskAction internals.TaskAction) error {
+	taskActionID, err := t.repo.CreateTaskAction(t.taskID, taskAction)
+	if err != nil {
+		return err
+	}
+
+	return t.repo.EnqueueTaskAction(*taskActionID)
+}
+
+// scheduleNextAction creates action for the first unfinished stage or finishes the task.
+func (t *yWikiFetchAllTask) scheduleNextAction() error {
+	taskAction := internals.TaskAction{}
+
+	switch {
+	case !t.state.ListingFinished:
+		err := taskAction.FromTaskActionListYWikiPages(internals.TaskActionListYWikiPages{
+			TaskActionType: internals.ListYwikiPages,
+			RootSlug:       t.state.RootSlug,
+			Cursor:         t.state.ListingCursor,
+		})
+		if err != nil {
+			return err
+		}
+
+	case t.processedPagesCount() < len(t.state.PageSlugs):
+		err := taskAction.FromTaskActionFetchYWikiPage(internals.TaskActionFetchYWikiPage{
+			TaskActionType: internals.FetchYwikiPage,
+			PageSlug:       t.state.PageSlugs[t.processedPagesCount()],
+		})
+		if err != nil {
+			return err
+		}
+
+	default:
+		return t.repo.SetTaskStatus(t.taskID, api.Done)
+	}
+
+	return t.createTaskAction(taskAction)
+}
+
+// accountListing appends newly discovered slugs. When listing is over, descendants
+// are ordered by depth, so every parent page is fetched before its children.
+func (t *yWikiFetchAllTask) accountListing(result internals.TaskActionResultListYWikiPages) {
+	knownSlugs := make(map[string]struct{}, len(t.state.PageSlugs)+len(result.PageSlugs))
+	for _, slug := range t.state.PageSlugs {
+		knownSlugs[slug] = struct{}{}
+	}
+	for _, slug := range result.PageSlugs {
+		if _, ok := knownSlugs[slug]; ok {
+			continue
+		}
+		knownSlugs[slug] = struct{}{}
+		t.state.PageSlugs = append(t.state.PageSlugs, slug)
+	}
+
+	t.state.ListingCursor = result.NextCursor
+	if result.NextCursor != nil {
+		return
+	}
+
+	t.state.ListingFinished = true
+	slices.SortStableFunc(t.state.PageSlugs[1:], func(a, b string) int {
+		return strings.Count(a, "/") - strings.Count(b, "/")
+	})
+}
+
+func (t *yWikiFetchAllTask) OnActionResult(result internals.TaskActionResult) error {
+	resultType, err := result.Discriminator()
+	if err != nil {
+		return err
+	}
+
+	switch internals.TaskActionType(resultType) {
+	case internals.NewTask:
+		// Nothing to account, task starts from its first unfinished stage.
+
+	case internals.ListYwikiPages:
+		listResult, err := result.AsTaskActionResultListYWikiPages()
+		if err != nil {
+			return err
+		}
+		if t.state.ListingFinished {
+			t.deps.Logger.Warn("skipping listing result of finished listing, task_id=", t.taskID)
+			return nil
+		}
+		t.accountListing(listResult)
+
+	case internals.FetchYwikiPage:
+		fetchResult, err := result.AsTaskActionResultFetchYWikiPage()
+		if err != nil {
+			return err
+		}
+		processedCount := t.processedPagesCount()
+		if processedCount >= len(t.state.PageSlugs) || t.state.PageSlugs[processedCount] != fetchResult.PageSlug {
+			t.deps.Logger.Warn("skipping unexpected fetch result for page ", fetchResult.PageSlug, ", task_id=", t.taskID)
+			return nil
+		}
+		if fetchResult.Error != nil {
+			t.state.SkippedPages = append(t.state.SkippedPages, internals.YWikiFetchFailure{
+				PageSlug: fetchResult.PageSlug,
+				Error:    *fetchResult.Error,
+			})
+		} else {
+			t.state.FetchedPageIds = append(t.state.FetchedPageIds, *fetchResult.PageId)
+		}
+
+	default:
+		return fmt.Errorf("unexpected task action result type: %s", resultType)
+	}
+
+	err = t.updateState()
+	if err != nil {
+		return err
+	}
+
+	err = t.scheduleNextAction()
+	if err != nil {
+		return err
+	}
+
+	return t.repo.Commit()
+}
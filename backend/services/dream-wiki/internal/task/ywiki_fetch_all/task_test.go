@@ -0,0 +1,234 @@
+package ywiki_fetch_all
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/db_adapter"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/utils/logger"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// fetchAllTestEnv keeps the task in memory storage and accounts action results
+// in place of results topic reader.
+type fetchAllTestEnv struct {
+	storage repository.Storage
+	taskID  api.TaskID
+}
+
+func newFetchAllTestEnv(t *testing.T) *fetchAllTestEnv {
+	t.Helper()
+	storage := repository.NewMemoryStorage(logger.InitTestLogger(), nil, nil)
+	repo := storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	var state internals.TaskState
+	err := state.FromTaskStateYWikiFetchAll(internals.TaskStateYWikiFetchAll{
+		TaskType:       internals.YwikiFetchAll,
+		RootSlug:       "root",
+		PageSlugs:      []string{"root"},
+		FetchedPageIds: []api.PageID{},
+		SkippedPages:   []internals.YWikiFetchFailure{},
+	})
+	require.NoError(t, err)
+	taskID, err := repo.CreateTask(state)
+	require.NoError(t, err)
+	require.NoError(t, repo.Commit())
+
+	return &fetchAllTestEnv{storage: storage, taskID: *taskID}
+}
+
+// onResult restores task from the stored state, as results topic reader does.
+func (e *fetchAllTestEnv) onResult(t *testing.T, result internals.TaskActionResult) error {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SerializableReadWrite)
+	defer repo.Rollback()
+
+	digest, state, err := repo.GetTaskByID(e.taskID)
+	require.NoError(t, err)
+	fetchAllState, err := state.AsTaskStateYWikiFetchAll()
+	require.NoError(t, err)
+	task := NewYWikiFetchAllTask(context.Background(), fetchAllState, &task_common.TaskDeps{
+		Deps:   &deps.Deps{Logger: logger.InitTestLogger()},
+		Digest: *digest,
+		State:  state,
+		Repo:   repo,
+	})
+	return task.OnActionResult(result)
+}
+
+func (e *fetchAllTestEnv) task(t *testing.T) (api.TaskStatus, internals.TaskStateYWikiFetchAll) {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	digest, state, err := repo.GetTaskByID(e.taskID)
+	require.NoError(t, err)
+	fetchAllState, err := state.AsTaskStateYWikiFetchAll()
+	require.NoError(t, err)
+	return digest.Status, fetchAllState
+}
+
+// actions returns actions created by the task in creation order.
+func (e *fetchAllTestEnv) actions(t *testing.T) []internals.TaskAction {
+	t.Helper()
+	repo := e.storage.NewRepository(context.Background(), db_adapter.SnapshotReadOnly)
+	defer repo.Rollback()
+
+	actionIDs, err := repo.GetTaskActionIDsByStatus(e.taskID, []internals.TaskActionStatus{internals.New})
+	require.NoError(t, err)
+	actions := make([]internals.TaskAction, 0, len(actionIDs))
+	for _, actionID := range actionIDs {
+		action, _, err := repo.GetTaskActionByID(actionID)
+		require.NoError(t, err)
+		actions = append(actions, *action)
+	}
+	return actions
+}
+
+func (e *fetchAllTestEnv) lastAction(t *testing.T) internals.TaskAction {
+	t.Helper()
+	actions := e.actions(t)
+	require.NotEmpty(t, actions)
+	return actions[len(actions)-1]
+}
+
+func makeListResult(t *testing.T, slugs []string, nextCursor *string) internals.TaskActionResult {
+	t.Helper()
+	var result internals.TaskActionResult
+	err := result.FromTaskActionResultListYWikiPages(internals.TaskActionResultListYWikiPages{
+		PageSlugs:  slugs,
+		NextCursor: nextCursor,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func makeFetchResult(t *testing.T, slug string, pageID api.PageID) internals.TaskActionResult {
+	t.Helper()
+	var result internals.TaskActionResult
+	err := result.FromTaskActionResultFetchYWikiPage(internals.TaskActionResultFetchYWikiPage{
+		PageSlug: slug,
+		PageId:   &pageID,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func makeSkippedFetchResult(t *testing.T, slug string) internals.TaskActionResult {
+	t.Helper()
+	errorMessage := "YWiki GetPage: not found"
+	var result internals.TaskActionResult
+	err := result.FromTaskActionResultFetchYWikiPage(internals.TaskActionResultFetchYWikiPage{
+		PageSlug: slug,
+		Error:    &errorMessage,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestYWikiFetchAllTask(t *testing.T) {
+	t.Parallel()
+
+	env := newFetchAllTestEnv(t)
+
+	var newTaskResult internals.TaskActionResult
+	require.NoError(t, newTaskResult.FromTaskActionResultNewTask(internals.TaskActionResultNewTask{}))
+	require.NoError(t, env.onResult(t, newTaskResult))
+	actionType, err := env.lastAction(t).Discriminator()
+	require.NoError(t, err)
+	require.Equal(t, internals.ListYwikiPages, internals.TaskActionType(actionType))
+
+	cursor := "next"
+	require.NoError(t, env.onResult(t, makeListResult(t, []string{"root/a/b", "root/c"}, &cursor)))
+	listAction, err := env.lastAction(t).AsTaskActionListYWikiPages()
+	require.NoError(t, err)
+	require.Equal(t, &cursor, listAction.Cursor)
+
+	require.NoError(t, env.onResult(t, makeListResult(t, []string{"root/a", "root/c"}, nil)))
+	_, state := env.task(t)
+	require.True(t, state.ListingFinished)
+	require.Equal(t, []string{"root", "root/c", "root/a", "root/a/b"}, state.PageSlugs)
+
+	pageIDs := []api.PageID{{1}, {2}, {3}, {4}}
+	for i, slug := range state.PageSlugs {
+		fetchAction, err := env.lastAction(t).AsTaskActionFetchYWikiPage()
+		require.NoError(t, err)
+		require.Equal(t, slug, fetchAction.PageSlug)
+		require.NoError(t, env.onResult(t, makeFetchResult(t, slug, pageIDs[i])))
+	}
+
+	// Duplicated result is ignored and does not fork the chain of actions.
+	actionsCount := len(env.actions(t))
+	require.NoError(t, env.onResult(t, makeFetchResult(t, "root/a/b", pageIDs[3])))
+	require.Len(t, env.actions(t), actionsCount)
+
+	// Pages are indexated on revision append, the task does not indexate them.
+	status, state := env.task(t)
+	require.Equal(t, api.Done, status)
+	require.Equal(t, pageIDs, state.FetchedPageIds)
+}
+
+func TestYWikiFetchAllTaskSkipsMissingPage(t *testing.T) {
+	t.Parallel()
+
+	env := newFetchAllTestEnv(t)
+	require.NoError(t, env.onResult(t, makeListResult(t, []string{"root/a", "root/b"}, nil)))
+
+	require.NoError(t, env.onResult(t, makeFetchResult(t, "root", api.PageID{1})))
+	require.NoError(t, env.onResult(t, makeSkippedFetchResult(t, "root/a")))
+	fetchAction, err := env.lastAction(t).AsTaskActionFetchYWikiPage()
+	require.NoError(t, err)
+	require.Equal(t, "root/b", fetchAction.PageSlug)
+
+	require.NoError(t, env.onResult(t, makeFetchResult(t, "root/b", api.PageID{2})))
+	status, state := env.task(t)
+	require.Equal(t, api.Done, status)
+	require.Equal(t, []api.PageID{{1}, {2}}, state.FetchedPageIds)
+	require.Equal(t, []internals.YWikiFetchFailure{{PageSlug: "root/a", Error: "YWiki GetPage: not found"}}, state.SkippedPages)
+
+	task := &yWikiFetchAllTask{status: status, state: state}
+	subtasks, err := task.CalculateSubtasks()
+	require.NoError(t, err)
+	require.Len(t, subtasks, 4)
+	require.Equal(t, api.Done, subtasks[1].Status)
+	require.Equal(t, api.FailedByError, subtasks[2].Status)
+	require.Equal(t, api.Done, subtasks[3].Status)
+}
+
+func TestYWikiFetchAllTaskFailsOnIndexationResult(t *testing.T) {
+	t.Parallel()
+
+	env := newFetchAllTestEnv(t)
+
+	var result internals.TaskActionResult
+	require.NoError(t, result.FromTaskActionResultIndexatePage(internals.TaskActionResultIndexatePage{PageId: api.PageID{1}}))
+	require.ErrorContains(t, env.onResult(t, result), "unexpected task action result type")
+	require.Empty(t, env.actions(t))
+}
+
+func TestYWikiFetchAllTaskSubtasks(t *testing.T) {
+	t.Parallel()
+
+	task := &yWikiFetchAllTask{
+		status: api.FailedByError,
+		state: internals.TaskStateYWikiFetchAll{
+			RootSlug:        "root",
+			ListingFinished: true,
+			PageSlugs:       []string{"root", "root/a"},
+			FetchedPageIds:  []api.PageID{{1}},
+		},
+	}
+
+	subtasks, err := task.CalculateSubtasks()
+	require.NoError(t, err)
+	require.Len(t, subtasks, 3)
+	require.Equal(t, api.Done, subtasks[0].Status)
+	require.Equal(t, api.Done, subtasks[1].Status)
+	require.Equal(t, api.FailedByError, subtasks[2].Status)
+}
@@ -0,0 +1,133 @@
+package ywiki_publish_draft
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/app/repository"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/deps"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/internal/task/task_common"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+type (
+	// yWikiPublishDraftTask publishes draft to YWiki. Draft is marked merged by the
+	// publishing action only after YWiki accepted the write.
+	yWikiPublishDraftTask struct {
+		taskID api.TaskID
+		status api.TaskStatus
+		state  internals.TaskStateYWikiPublishDraft
+		ctx    context.Context
+		deps   *deps.Deps
+		repo   repository.AppRepository
+	}
+)
+
+var (
+	_ task_common.TaskLogic = (*yWikiPublishDraftTask)(nil)
+)
+
+func NewYWikiPublishDraftTask(ctx context.Context, state internals.TaskStateYWikiPublishDraft, deps *task_common.TaskDeps) *yWikiPublishDraftTask {
+	return &yWikiPublishDraftTask{
+		state:  state,
+		status: deps.Digest.Status,
+		ctx:    ctx,
+		deps:   deps.Deps,
+		taskID: deps.Digest.TaskId,
+		repo:   deps.Repo,
+	}
+}
+
+func (t *yWikiPublishDraftTask) CalculateSubtasks() ([]api.Subtask, error) {
+	status := t.status
+	if t.state.Outcome != nil && *t.state.Outcome != internals.Published {
+		status = api.FailedByError
+	}
+
+	description := "Publish draft of page " + t.state.PageTitle
+	if t.state.Outcome != nil {
+		description += fmt.Sprintf(" (%s)", *t.state.Outcome)
+	}
+
+	return []api.Subtask{
+		{
+			Description: description,
+			Status:      status,
+			Subsubtasks: []api.SubSubtask{},
+		},
+	}, nil
+}
+
+func (t *yWikiPublishDraftTask) updateState() error {
+	taskState := internals.TaskState{}
+	err := taskState.FromTaskStateYWikiPublishDraft(t.state)
+	if err != nil {
+		return err
+	}
+	return t.repo.SetTaskState(t.taskID, taskState)
+}
+
+func (t *yWikiPublishDraftTask) createPublishAction() error {
+	taskAction := internals.TaskAction{}
+	err := taskAction.FromTaskActionPublishDraftToYWiki(internals.TaskActionPublishDraftToYWiki{
+		TaskActionType: internals.PublishDraftToYwiki,
+		DraftId:        t.state.DraftId,
+		AppliedBy:      t.state.AppliedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	taskActionID, err := t.repo.CreateTaskAction(t.taskID, taskAction)
+	if err != nil {
+		return err
+	}
+
+	return t.repo.EnqueueTaskAction(*taskActionID)
+}
+
+func (t *yWikiPublishDraftTask) OnActionResult(result internals.TaskActionResult) error {
+	resultType, err := result.Discriminator()
+	if err != nil {
+		return err
+	}
+
+	switch internals.TaskActionType(resultType) {
+	case internals.NewTask:
+		err = t.createPublishAction()
+		if err != nil {
+			return err
+		}
+
+	case internals.PublishDraftToYwiki:
+		publishResult, err := result.AsTaskActionResultPublishDraftToYWiki()
+		if err != nil {
+			return err
+		}
+		if publishResult.DraftId != t.state.DraftId {
+			t.deps.Logger.Warn("skipping publish result of another draft ", publishResult.DraftId, ", task_id=", t.taskID)
+			return nil
+		}
+
+		t.state.Outcome = &publishResult.Outcome
+		err = t.updateState()
+		if err != nil {
+			return err
+		}
+
+		newStatus := api.Done
+		if publishResult.Outcome != internals.Published {
+			newStatus = api.FailedByError
+		}
+		err = t.repo.SetTaskStatus(t.taskID, newStatus)
+		if err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unexpected task action result type: %s", resultType)
+	}
+
+	return t.repo.Commit()
+}
@@ -0,0 +1,18 @@
+package ywiki_slug
+
+import "strings"
+
+// AncestorSlugs returns slugs of all pages above the given one, closest first.
+// YWiki slugs are paths, so "a/b/c" has ancestors "a/b" and "a".
+func AncestorSlugs(slug string) []string {
+	ancestors := make([]string, 0)
+	slug = strings.Trim(slug, "/")
+	for {
+		lastSlash := strings.LastIndex(slug, "/")
+		if lastSlash < 0 {
+			return ancestors
+		}
+		slug = slug[:lastSlash]
+		ancestors = append(ancestors, slug)
+	}
+}
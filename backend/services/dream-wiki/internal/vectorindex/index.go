@@ -0,0 +1,252 @@
+package vectorindex
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const (
+	buildSeed = 1
+	// Recall is measured on recallQueriesCount indexed vectors used as queries,
+	// comparing recallNeighboursCount nearest neighbours.
+	recallQueriesCount    = 32
+	recallNeighboursCount = 10
+)
+
+type (
+	// Index is an approximate nearest neighbours index of paragraph embeddings.
+	// It is rebuilt from scratch periodically, see VectorIndexBuilder, and
+	// pages reindexated in between are replaced in it by ReplacePage, so that
+	// search does not miss new paragraphs nor return removed ones. Searches go
+	// to exact scan while the index is not ready.
+	Index struct {
+		probes     int
+		minEntries int
+
+		mu            sync.RWMutex
+		ivf           *ivf
+		builtAt       time.Time
+		buildDuration time.Duration
+		recall        float64
+		// updates keep pages replaced after updatesSequence of Rebuild, they are
+		// replaced in the rebuilt index too.
+		updatesSequence uint64
+		updates         map[api.PageID]pageUpdate
+		resetSequence   uint64
+
+		statsMu sync.Mutex
+		stats   searchStats
+	}
+
+	pageUpdate struct {
+		sequence uint64
+		entries  []Entry
+	}
+
+	searchStats struct {
+		approximateCount    int64
+		approximateDuration time.Duration
+		exactCount          int64
+		exactDuration       time.Duration
+		fallbacksCount      int64
+	}
+
+	// Diagnostics describes index state and search quality.
+	Diagnostics struct {
+		Ready          bool
+		EntriesCount   int
+		ClustersCount  int
+		BuiltAt        *time.Time
+		BuildDuration  time.Duration
+		Recall         *float64
+		Probes         int
+		MinEntries     int
+		FallbacksCount int64
+
+		ApproximateSearchesCount        int64
+		ApproximateSearchAverageLatency time.Duration
+		ExactSearchesCount              int64
+		ExactSearchAverageLatency       time.Duration
+	}
+)
+
+// NewIndex creates index which is not ready until Rebuild is called with at
+// least minEntries entries, because exact scan is fast enough for small
+// collections. probes is the number of clusters scanned on search, more probes
+// give better recall and slower search.
+func NewIndex(probes int, minEntries int) *Index {
+	return &Index{
+		probes:     max(1, probes),
+		minEntries: minEntries,
+		updates:    make(map[api.PageID]pageUpdate),
+	}
+}
+
+// UpdatesSequence must be taken before entries for Rebuild are read from the
+// storage.
+func (x *Index) UpdatesSequence() uint64 {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.updatesSequence
+}
+
+// Rebuild replaces index contents with entries and measures recall of the new
+// index against exact search. Pages replaced after updatesSequence, which
+// entries may miss, are replaced in the new index too.
+func (x *Index) Rebuild(entries []Entry, updatesSequence uint64) {
+	var index *ivf
+	var buildDuration time.Duration
+	var recall float64
+	if len(entries) > 0 && len(entries) >= x.minEntries {
+		start := time.Now()
+		index = buildIVF(entries, buildSeed)
+		buildDuration = time.Since(start)
+		recall = measureRecall(index, entries, x.probes)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for pageID, update := range x.updates {
+		if update.sequence <= updatesSequence {
+			delete(x.updates, pageID)
+		} else if index != nil {
+			index = index.replacePage(pageID, update.entries)
+		}
+	}
+	if updatesSequence < x.resetSequence {
+		// Entries were read before Reset.
+		index = nil
+	}
+	x.ivf = index
+	if index != nil {
+		x.builtAt = time.Now()
+		x.buildDuration = buildDuration
+		x.recall = recall
+	}
+}
+
+// ReplacePage replaces indexed paragraphs of the page with entries, empty
+// entries remove the page. It is called after the reindexation of the page is
+// committed.
+func (x *Index) ReplacePage(pageID api.PageID, entries []Entry) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.updatesSequence++
+	x.updates[pageID] = pageUpdate{sequence: x.updatesSequence, entries: entries}
+	if x.ivf != nil {
+		x.ivf = x.ivf.replacePage(pageID, entries)
+	}
+}
+
+// Reset drops the index after all paragraphs are removed, searches go to exact
+// scan until the next Rebuild.
+func (x *Index) Reset() {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.updatesSequence++
+	x.resetSequence = x.updatesSequence
+	x.updates = make(map[api.PageID]pageUpdate)
+	x.ivf = nil
+}
+
+// measureRecall returns the average share of exact nearest neighbours found by
+// the index.
+func measureRecall(index *ivf, entries []Entry, probes int) float64 {
+	random := rand.New(rand.NewSource(buildSeed))
+	queries := random.Perm(len(entries))[:min(recallQueriesCount, len(entries))]
+
+	total := 0.0
+	for _, query := range queries {
+		exact := exactSearch(entries, entries[query].Embedding, recallNeighboursCount)
+		approximate := index.search(entries[query].Embedding, probes, recallNeighboursCount, nil)
+
+		found := make(map[Key]struct{}, len(approximate))
+		for _, neighbour := range approximate {
+			found[neighbour.Key] = struct{}{}
+		}
+		matched := 0
+		for _, neighbour := range exact {
+			if _, ok := found[neighbour.Key]; ok {
+				matched++
+			}
+		}
+		total += float64(matched) / float64(len(exact))
+	}
+	return total / float64(len(queries))
+}
+
+// Search returns up to limit approximate nearest neighbours accepted by filter.
+// False is returned if the index is not ready.
+func (x *Index) Search(query internals.Embedding, limit int, filter func(Key) bool) ([]Neighbour, bool) {
+	x.mu.RLock()
+	index := x.ivf
+	x.mu.RUnlock()
+
+	if index == nil {
+		return nil, false
+	}
+	return index.search(query, x.probes, limit, filter), true
+}
+
+// RecordSearch accounts latency of the whole search including reading of the
+// found paragraphs.
+func (x *Index) RecordSearch(approximate bool, duration time.Duration) {
+	x.statsMu.Lock()
+	defer x.statsMu.Unlock()
+	if approximate {
+		x.stats.approximateCount++
+		x.stats.approximateDuration += duration
+	} else {
+		x.stats.exactCount++
+		x.stats.exactDuration += duration
+	}
+}
+
+// RecordFallback accounts approximate search which found too few paragraphs
+// and was repeated as exact one.
+func (x *Index) RecordFallback() {
+	x.statsMu.Lock()
+	defer x.statsMu.Unlock()
+	x.stats.fallbacksCount++
+}
+
+func averageDuration(total time.Duration, count int64) time.Duration {
+	if count == 0 {
+		return 0
+	}
+	return total / time.Duration(count)
+}
+
+func (x *Index) Diagnostics() Diagnostics {
+	diagnostics := Diagnostics{
+		Probes:     x.probes,
+		MinEntries: x.minEntries,
+	}
+
+	x.mu.RLock()
+	if x.ivf != nil {
+		builtAt := x.builtAt
+		recall := x.recall
+		diagnostics.Ready = true
+		diagnostics.EntriesCount = x.ivf.size
+		diagnostics.ClustersCount = len(x.ivf.centroids)
+		diagnostics.BuiltAt = &builtAt
+		diagnostics.BuildDuration = x.buildDuration
+		diagnostics.Recall = &recall
+	}
+	x.mu.RUnlock()
+
+	x.statsMu.Lock()
+	diagnostics.FallbacksCount = x.stats.fallbacksCount
+	diagnostics.ApproximateSearchesCount = x.stats.approximateCount
+	diagnostics.ApproximateSearchAverageLatency = averageDuration(x.stats.approximateDuration, x.stats.approximateCount)
+	diagnostics.ExactSearchesCount = x.stats.exactCount
+	diagnostics.ExactSearchAverageLatency = averageDuration(x.stats.exactDuration, x.stats.exactCount)
+	x.statsMu.Unlock()
+
+	return diagnostics
+}
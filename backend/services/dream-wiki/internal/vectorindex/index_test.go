@@ -0,0 +1,159 @@
+package vectorindex
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+// clusteredEntries generates entries around clustersCount random centers, like
+// embeddings of paragraphs on a few topics.
+func clusteredEntries(count int, clustersCount int, dimensions int) []Entry {
+	random := rand.New(rand.NewSource(42))
+	centers := make([]internals.Embedding, clustersCount)
+	for i := range centers {
+		centers[i] = make(internals.Embedding, dimensions)
+		for j := range centers[i] {
+			centers[i][j] = float32(random.NormFloat64())
+		}
+	}
+
+	pageIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	entries := make([]Entry, count)
+	for i := range entries {
+		center := centers[random.Intn(clustersCount)]
+		embedding := make(internals.Embedding, dimensions)
+		for j := range embedding {
+			embedding[j] = center[j] + float32(random.NormFloat64()*0.2)
+		}
+		entries[i] = Entry{
+			Key:       Key{PageID: pageIDs[i%len(pageIDs)], ParagraphIndex: i},
+			Embedding: embedding,
+		}
+	}
+	return entries
+}
+
+func TestCosineDistance(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 0, CosineDistance(internals.Embedding{1, 1}, internals.Embedding{2, 2}), 1e-6)
+	require.InDelta(t, 1, CosineDistance(internals.Embedding{1, 0}, internals.Embedding{0, 3}), 1e-6)
+	require.InDelta(t, 2, CosineDistance(internals.Embedding{1, 0}, internals.Embedding{-1, 0}), 1e-6)
+	require.Equal(t, float32(1), CosineDistance(internals.Embedding{0, 0}, internals.Embedding{1, 0}))
+}
+
+func TestIndexNotReady(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(4, 100)
+
+	_, ready := index.Search(internals.Embedding{1, 0}, 10, nil)
+	require.False(t, ready)
+
+	index.Rebuild(clusteredEntries(99, 4, 8), 0)
+	_, ready = index.Search(internals.Embedding{1, 0}, 10, nil)
+	require.False(t, ready)
+	require.False(t, index.Diagnostics().Ready)
+	require.Nil(t, index.Diagnostics().Recall)
+}
+
+func TestIndexSearch(t *testing.T) {
+	t.Parallel()
+	entries := clusteredEntries(2000, 16, 32)
+	index := NewIndex(8, 100)
+	index.Rebuild(entries, 0)
+
+	diagnostics := index.Diagnostics()
+	require.True(t, diagnostics.Ready)
+	require.Equal(t, len(entries), diagnostics.EntriesCount)
+	require.Equal(t, 45, diagnostics.ClustersCount)
+	require.NotNil(t, diagnostics.BuiltAt)
+	require.NotNil(t, diagnostics.Recall)
+	require.Greater(t, *diagnostics.Recall, 0.9)
+
+	query := entries[7].Embedding
+	neighbours, ready := index.Search(query, 5, nil)
+	require.True(t, ready)
+	require.Len(t, neighbours, 5)
+	require.Equal(t, entries[7].Key, neighbours[0].Key)
+	require.InDelta(t, 0, neighbours[0].Distance, 1e-5)
+	for i := 1; i < len(neighbours); i++ {
+		require.LessOrEqual(t, neighbours[i-1].Distance, neighbours[i].Distance)
+	}
+
+	pageID := entries[0].Key.PageID
+	filtered, ready := index.Search(query, 5, func(key Key) bool { return key.PageID == pageID })
+	require.True(t, ready)
+	require.NotEmpty(t, filtered)
+	for _, neighbour := range filtered {
+		require.Equal(t, pageID, neighbour.Key.PageID)
+	}
+
+	index.Rebuild(nil, 0)
+	_, ready = index.Search(query, 5, nil)
+	require.False(t, ready)
+}
+
+func TestIndexSearchStats(t *testing.T) {
+	t.Parallel()
+	index := NewIndex(1, 1)
+
+	index.RecordSearch(true, 2_000_000)
+	index.RecordSearch(true, 4_000_000)
+	index.RecordSearch(false, 10_000_000)
+	index.RecordFallback()
+
+	diagnostics := index.Diagnostics()
+	require.Equal(t, int64(2), diagnostics.ApproximateSearchesCount)
+	require.Equal(t, int64(3_000_000), int64(diagnostics.ApproximateSearchAverageLatency))
+	require.Equal(t, int64(1), diagnostics.ExactSearchesCount)
+	require.Equal(t, int64(10_000_000), int64(diagnostics.ExactSearchAverageLatency))
+	require.Equal(t, int64(1), diagnostics.FallbacksCount)
+}
+
+func TestIndexReplacePage(t *testing.T) {
+	t.Parallel()
+	entries := clusteredEntries(500, 8, 16)
+	index := NewIndex(8, 100)
+	index.Rebuild(entries, index.UpdatesSequence())
+
+	// Entries with even indexes belong to the first page.
+	pageID := entries[0].Key.PageID
+	pageFilter := func(key Key) bool { return key.PageID == pageID }
+	newEntry := Entry{Key: Key{PageID: pageID, ParagraphIndex: 1000}, Embedding: entries[1].Embedding}
+
+	rebuildSequence := index.UpdatesSequence()
+	index.ReplacePage(pageID, []Entry{newEntry})
+	require.Equal(t, len(entries)/2+1, index.Diagnostics().EntriesCount)
+
+	neighbours, ready := index.Search(entries[1].Embedding, 2, nil)
+	require.True(t, ready)
+	require.ElementsMatch(t, []Key{entries[1].Key, newEntry.Key}, []Key{neighbours[0].Key, neighbours[1].Key})
+
+	neighbours, _ = index.Search(entries[0].Embedding, 10, pageFilter)
+	require.Equal(t, []Key{newEntry.Key}, []Key{neighbours[0].Key})
+	require.Len(t, neighbours, 1)
+
+	// Entries read before the replacement do not bring removed ones back.
+	index.Rebuild(entries, rebuildSequence)
+	neighbours, _ = index.Search(entries[0].Embedding, 10, pageFilter)
+	require.Len(t, neighbours, 1)
+	require.Equal(t, newEntry.Key, neighbours[0].Key)
+
+	// Entries read after it are taken as they are.
+	index.Rebuild(entries, index.UpdatesSequence())
+	neighbours, _ = index.Search(entries[0].Embedding, 10, pageFilter)
+	require.Len(t, neighbours, 10)
+	require.Equal(t, entries[0].Key, neighbours[0].Key)
+
+	rebuildSequence = index.UpdatesSequence()
+	index.Reset()
+	_, ready = index.Search(entries[0].Embedding, 10, nil)
+	require.False(t, ready)
+	index.Rebuild(entries, rebuildSequence)
+	_, ready = index.Search(entries[0].Embedding, 10, nil)
+	require.False(t, ready)
+}
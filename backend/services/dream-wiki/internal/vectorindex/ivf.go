@@ -0,0 +1,245 @@
+package vectorindex
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/api"
+	"github.com/texnopark-DreamTeam-2025/DreamWiki/pkg/internals"
+)
+
+const (
+	kMeansIterations = 8
+	// kMeansSamplesPerCluster bounds the number of vectors centroids are
+	// trained on, all vectors are assigned to clusters afterwards.
+	kMeansSamplesPerCluster = 64
+)
+
+type (
+	// Key identifies indexed paragraph.
+	Key struct {
+		PageID         api.PageID
+		ParagraphIndex int
+	}
+
+	Entry struct {
+		Key       Key
+		Embedding internals.Embedding
+	}
+
+	Neighbour struct {
+		Key Key
+		// Distance is cosine distance as computed by Knn::CosineDistance.
+		Distance float32
+	}
+
+	// ivf is an inverted file index: vectors are split into clusters by k-means
+	// and search scans only clusters with the closest centroids.
+	ivf struct {
+		centroids []internals.Embedding
+		// lists keep normalized vectors of clusters.
+		lists [][]ivfEntry
+		size  int
+	}
+
+	ivfEntry struct {
+		key    Key
+		vector internals.Embedding
+	}
+)
+
+// CosineDistance mirrors Knn::CosineDistance. Zero vectors are at distance 1
+// from everything.
+func CosineDistance(a, b internals.Embedding) float32 {
+	var dot, normA, normB float64
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return float32(1 - dot/(math.Sqrt(normA)*math.Sqrt(normB)))
+}
+
+func normalize(vector internals.Embedding) internals.Embedding {
+	var norm float64
+	for _, value := range vector {
+		norm += float64(value) * float64(value)
+	}
+	normalized := make(internals.Embedding, len(vector))
+	if norm == 0 {
+		return normalized
+	}
+	norm = math.Sqrt(norm)
+	for i, value := range vector {
+		normalized[i] = float32(float64(value) / norm)
+	}
+	return normalized
+}
+
+// distance between normalized vectors.
+func distance(a, b internals.Embedding) float32 {
+	var dot float32
+	for i := range min(len(a), len(b)) {
+		dot += a[i] * b[i]
+	}
+	return 1 - dot
+}
+
+func nearestCentroid(centroids []internals.Embedding, vector internals.Embedding) int {
+	nearest := 0
+	nearestDistance := float32(math.Inf(1))
+	for i, centroid := range centroids {
+		if d := distance(centroid, vector); d < nearestDistance {
+			nearest = i
+			nearestDistance = d
+		}
+	}
+	return nearest
+}
+
+// buildIVF splits entries into about sqrt(len(entries)) clusters. Build is
+// deterministic for the same entries and seed.
+func buildIVF(entries []Entry, seed int64) *ivf {
+	vectors := make([]internals.Embedding, len(entries))
+	for i, entry := range entries {
+		vectors[i] = normalize(entry.Embedding)
+	}
+
+	clustersCount := max(1, min(len(entries), int(math.Round(math.Sqrt(float64(len(entries)))))))
+	random := rand.New(rand.NewSource(seed))
+	samples := vectors
+	if len(samples) > clustersCount*kMeansSamplesPerCluster {
+		samples = make([]internals.Embedding, 0, clustersCount*kMeansSamplesPerCluster)
+		for _, i := range random.Perm(len(vectors))[:cap(samples)] {
+			samples = append(samples, vectors[i])
+		}
+	}
+
+	centroids := make([]internals.Embedding, 0, clustersCount)
+	for _, i := range random.Perm(len(samples))[:clustersCount] {
+		centroids = append(centroids, samples[i])
+	}
+	for range kMeansIterations {
+		sums := make([][]float64, clustersCount)
+		for _, vector := range samples {
+			cluster := nearestCentroid(centroids, vector)
+			if sums[cluster] == nil {
+				sums[cluster] = make([]float64, len(vector))
+			}
+			for i := range min(len(vector), len(sums[cluster])) {
+				sums[cluster][i] += float64(vector[i])
+			}
+		}
+		for cluster, sum := range sums {
+			// Empty cluster keeps its centroid.
+			if sum == nil {
+				continue
+			}
+			centroid := make(internals.Embedding, len(sum))
+			for i, value := range sum {
+				centroid[i] = float32(value)
+			}
+			centroids[cluster] = normalize(centroid)
+		}
+	}
+
+	index := &ivf{
+		centroids: centroids,
+		lists:     make([][]ivfEntry, clustersCount),
+		size:      len(entries),
+	}
+	for i, entry := range entries {
+		cluster := nearestCentroid(centroids, vectors[i])
+		index.lists[cluster] = append(index.lists[cluster], ivfEntry{key: entry.Key, vector: vectors[i]})
+	}
+	return index
+}
+
+// replacePage returns copy of the index where entries of the page are replaced
+// with entries, which are assigned to the closest of existing clusters. Lists
+// of the original index are not modified, so searches running on it are safe.
+func (x *ivf) replacePage(pageID api.PageID, entries []Entry) *ivf {
+	index := &ivf{
+		centroids: x.centroids,
+		lists:     slices.Clone(x.lists),
+		size:      x.size,
+	}
+	for cluster, list := range index.lists {
+		if !slices.ContainsFunc(list, func(entry ivfEntry) bool { return entry.key.PageID == pageID }) {
+			continue
+		}
+		kept := make([]ivfEntry, 0, len(list))
+		for _, entry := range list {
+			if entry.key.PageID != pageID {
+				kept = append(kept, entry)
+			}
+		}
+		index.size -= len(list) - len(kept)
+		index.lists[cluster] = kept
+	}
+
+	for _, entry := range entries {
+		vector := normalize(entry.Embedding)
+		cluster := nearestCentroid(index.centroids, vector)
+		index.lists[cluster] = append(slices.Clip(index.lists[cluster]), ivfEntry{key: entry.Key, vector: vector})
+	}
+	index.size += len(entries)
+	return index
+}
+
+// sortNeighbours orders neighbours by distance breaking ties by key, so that
+// results are stable.
+func sortNeighbours(neighbours []Neighbour) {
+	sort.Slice(neighbours, func(i, j int) bool {
+		if neighbours[i].Distance != neighbours[j].Distance {
+			return neighbours[i].Distance < neighbours[j].Distance
+		}
+		if neighbours[i].Key.PageID != neighbours[j].Key.PageID {
+			return neighbours[i].Key.PageID.String() < neighbours[j].Key.PageID.String()
+		}
+		return neighbours[i].Key.ParagraphIndex < neighbours[j].Key.ParagraphIndex
+	})
+}
+
+// search scans probes closest clusters and returns up to limit nearest keys
+// accepted by filter. Nil filter accepts everything.
+func (x *ivf) search(query internals.Embedding, probes int, limit int, filter func(Key) bool) []Neighbour {
+	query = normalize(query)
+
+	centroidDistances := make([]float32, len(x.centroids))
+	clusters := make([]int, len(x.centroids))
+	for i, centroid := range x.centroids {
+		centroidDistances[i] = distance(centroid, query)
+		clusters[i] = i
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return centroidDistances[clusters[i]] < centroidDistances[clusters[j]]
+	})
+
+	neighbours := make([]Neighbour, 0)
+	for _, cluster := range clusters[:min(probes, len(clusters))] {
+		for _, entry := range x.lists[cluster] {
+			if filter != nil && !filter(entry.key) {
+				continue
+			}
+			neighbours = append(neighbours, Neighbour{Key: entry.key, Distance: distance(entry.vector, query)})
+		}
+	}
+	sortNeighbours(neighbours)
+	return neighbours[:min(limit, len(neighbours))]
+}
+
+// exactSearch is the full scan which ivf approximates.
+func exactSearch(entries []Entry, query internals.Embedding, limit int) []Neighbour {
+	neighbours := make([]Neighbour, 0, len(entries))
+	for _, entry := range entries {
+		neighbours = append(neighbours, Neighbour{Key: entry.Key, Distance: CosineDistance(entry.Embedding, query)})
+	}
+	sortNeighbours(neighbours)
+	return neighbours[:min(limit, len(neighbours))]
+}